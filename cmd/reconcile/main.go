@@ -0,0 +1,64 @@
+// Команда reconcile сверяет users.coins с журналом двойной записи и печатает отчет в формате JSON.
+// С флагом -repair расходящиеся балансы перезаписываются значением из журнала.
+// Код выхода 2 означает, что после сверки остались неустраненные расхождения.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+
+	"github.com/senyabanana/shop-service/internal/infrastructure/config"
+	"github.com/senyabanana/shop-service/internal/infrastructure/database"
+	"github.com/senyabanana/shop-service/internal/infrastructure/logger"
+	"github.com/senyabanana/shop-service/internal/repository"
+	"github.com/senyabanana/shop-service/internal/service"
+)
+
+func main() {
+	repair := flag.Bool("repair", false, "overwrite drifted balances with the ledger value")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	log := logger.NewLogger()
+	log.SetOutput(os.Stderr)
+
+	cfg, err := config.LoadConfig(".")
+	if err != nil {
+		log.Fatalf("error initializing configs: %s", err.Error())
+	}
+
+	db, err := database.NewPostgresDB(ctx, cfg)
+	if err != nil {
+		log.Fatalf("failed to initialize db: %s", err.Error())
+	}
+	defer db.Close()
+
+	trManager := manager.Must(trmsqlx.NewDefaultFactory(db))
+	repos := repository.NewRepository(db)
+	reconciliation := service.NewReconciliationService(repos.UserRepository, repos.LedgerRepository, trManager, log)
+
+	report, err := reconciliation.Reconcile(ctx, *repair)
+	if err != nil {
+		log.Fatalf("reconciliation failed: %s", err.Error())
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("failed to write report: %s", err.Error())
+	}
+
+	if report.DriftedAccounts > report.RepairedAccounts {
+		db.Close()
+		os.Exit(2)
+	}
+}
@@ -4,18 +4,21 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
 	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/sirupsen/logrus"
 
 	"github.com/senyabanana/shop-service/internal/handler"
 	"github.com/senyabanana/shop-service/internal/infrastructure/config"
 	"github.com/senyabanana/shop-service/internal/infrastructure/database"
 	"github.com/senyabanana/shop-service/internal/infrastructure/logger"
 	"github.com/senyabanana/shop-service/internal/repository"
+	"github.com/senyabanana/shop-service/internal/scheduler"
 	"github.com/senyabanana/shop-service/internal/service"
 	httpServer "github.com/senyabanana/shop-service/internal/transport/http"
 )
@@ -39,8 +42,107 @@ func main() {
 
 	trManager := manager.Must(trmsqlx.NewDefaultFactory(db))
 	repos := repository.NewRepository(db)
-	services := service.NewService(repos, trManager, cfg.JwtSecretKey, log)
-	handlers := handler.NewHandler(services, log)
+	switch cfg.LoginAttemptStore {
+	case "postgres":
+	case "memory":
+		repos.LoginAttemptRepository = repository.NewLoginAttemptMemory()
+	default:
+		log.Fatalf("unsupported login attempt store: %s", cfg.LoginAttemptStore)
+	}
+	hasher, err := service.NewPasswordHasher(cfg.PasswordHashAlgorithm)
+	if err != nil {
+		log.Fatalf("failed to initialize password hasher: %s", err.Error())
+	}
+
+	keyring, err := service.NewKeyring(service.KeyringConfig{
+		LegacySecret: cfg.JwtSecretKey,
+		Keys:         cfg.JwtKeys,
+		Dir:          cfg.JwtKeysDir,
+		ActiveKeyID:  cfg.JwtActiveKeyID,
+	})
+	if err != nil {
+		log.Fatalf("failed to initialize jwt keyring: %s", err.Error())
+	}
+	log.Infof("JWT tokens are signed with key %s", keyring.ActiveKeyID())
+
+	go reloadKeyringOnSignal(ctx, keyring, log)
+
+	if cfg.SignupGrant < 0 {
+		log.Fatalf("invalid SIGNUP_GRANT: must not be negative")
+	}
+	signupGrantRules, err := service.ParseSignupGrantRules(cfg.SignupGrantRules)
+	if err != nil {
+		log.Fatalf("invalid SIGNUP_GRANT_RULES: %s", err.Error())
+	}
+
+	authCfg := service.AuthConfig{
+		Keyring: keyring,
+		Policy:  service.CredentialsPolicy{MinPasswordLength: cfg.PasswordMinLength},
+		Throttle: service.LoginThrottleConfig{
+			MaxUserFailures: cfg.LoginMaxFailures,
+			MaxIPFailures:   cfg.LoginIPMaxFailures,
+			BaseLockout:     cfg.LoginLockoutBase,
+			MaxLockout:      cfg.LoginLockoutMax,
+			FailureWindow:   cfg.LoginFailureWindow,
+		},
+		TwoFactor: service.TwoFactorConfig{
+			Issuer:       cfg.TOTPIssuer,
+			ChallengeTTL: cfg.TOTPChallengeTTL,
+		},
+		SignupGrant: service.SignupGrantPolicy{
+			Default: cfg.SignupGrant,
+			Rules:   signupGrantRules,
+		},
+		AccessTokenTTL:     cfg.AccessTokenTTL,
+		RefreshTokenTTL:    cfg.RefreshTokenTTL,
+		RevocationCacheTTL: cfg.RevocationCacheTTL,
+		APIKeyTTL:          cfg.APIKeyTTL,
+	}
+
+	historyCfg := service.HistoryConfig{InfoLimit: cfg.InfoHistoryLimit}
+
+	sendCoinIsolation, err := service.ParseIsolationLevel(cfg.TxSendCoinIsolation)
+	if err != nil {
+		log.Fatalf("invalid TX_ISOLATION_SEND_COIN: %s", err.Error())
+	}
+	buyItemIsolation, err := service.ParseIsolationLevel(cfg.TxBuyItemIsolation)
+	if err != nil {
+		log.Fatalf("invalid TX_ISOLATION_BUY_ITEM: %s", err.Error())
+	}
+
+	txCfg := service.TxConfig{
+		MaxAttempts:       cfg.TxMaxAttempts,
+		BaseDelay:         cfg.TxRetryBaseDelay,
+		MaxDelay:          cfg.TxRetryMaxDelay,
+		SendCoinIsolation: sendCoinIsolation,
+		BuyItemIsolation:  buyItemIsolation,
+	}
+
+	if cfg.AllowanceCoinTTL < 0 {
+		log.Fatalf("invalid ALLOWANCE_COIN_TTL: must not be negative")
+	}
+	expirationCfg := service.ExpirationConfig{AllowanceCoinTTL: cfg.AllowanceCoinTTL}
+
+	services := service.NewService(repos, trManager, hasher, authCfg, historyCfg, txCfg, expirationCfg, cfg.IdempotencyKeyTTL, log)
+	handlers := handler.NewHandler(services, cfg, log)
+
+	jobs := scheduler.New(log)
+	jobs.Add(scheduler.Job{
+		Name:     "reconcileBalances",
+		Interval: cfg.ReconcileInterval,
+		Run:      reconcileBalances(services.Reconciliation, log),
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "applyAllowances",
+		Interval: cfg.AllowanceCheckInterval,
+		Run:      applyAllowances(services.Allowance, log),
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "expireCoins",
+		Interval: cfg.CoinExpiryCheckInterval,
+		Run:      expireCoins(services.Expiration, log),
+	})
+	jobs.Start(ctx)
 
 	srv := new(httpServer.Server)
 
@@ -63,5 +165,71 @@ func main() {
 		log.Errorf("error occurred on server shutdown: %s", err.Error())
 	}
 
+	jobs.Wait()
+
 	log.Info("Server stopped gracefully")
 }
+
+// reloadKeyringOnSignal перечитывает каталог JWT-ключей по SIGHUP, чтобы новые ключи
+// подхватывались без перезапуска сервиса.
+func reloadKeyringOnSignal(ctx context.Context, keyring *service.Keyring, log *logrus.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if err := keyring.Reload(); err != nil {
+				log.Errorf("failed to reload jwt keys: %s", err.Error())
+				continue
+			}
+			log.Infof("JWT keys reloaded, active key %s", keyring.ActiveKeyID())
+		}
+	}
+}
+
+// reconcileBalances сверяет балансы с журналом и обновляет метрику balance_drifted_accounts.
+// Расхождения только логируются: исправлять их нужно командой reconcile.
+func reconcileBalances(reconciliation service.Reconciliation, log *logrus.Logger) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		report, err := reconciliation.Reconcile(ctx, false)
+		if err != nil {
+			return err
+		}
+		for _, drift := range report.Drifts {
+			log.WithFields(logrus.Fields{
+				"userId":   drift.UserID,
+				"cached":   drift.Cached,
+				"expected": drift.Expected,
+			}).Warn("balance drift detected")
+		}
+		return nil
+	}
+}
+
+// applyAllowances выплачивает регулярные начисления за текущий период. Задача запускается на всех
+// репликах: кто выплатит период, решает запись allowance_runs в базе.
+func applyAllowances(allowances service.Allowance, log *logrus.Logger) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		applied, err := allowances.ApplyDueAllowances(ctx, time.Now())
+		if applied > 0 {
+			log.Infof("Paid %d allowances", applied)
+		}
+		return err
+	}
+}
+
+// expireCoins списывает сгоревшие монеты. Задача запускается на всех репликах: пользователя
+// обрабатывает та, что первой заблокирует его строку, остальные не найдут у него сгоревших партий.
+func expireCoins(expiration service.Expiration, log *logrus.Logger) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		expired, err := expiration.ExpireCoins(ctx, time.Now())
+		if expired > 0 {
+			log.Infof("Expired %d coins", expired)
+		}
+		return err
+	}
+}
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+const (
+	CadenceDaily   = "daily"
+	CadenceWeekly  = "weekly"
+	CadenceMonthly = "monthly"
+)
+
+// Allowance — регулярное начисление: Amount монет каждому получателю раз в период Cadence.
+// Если AllUsers, получают все пользователи, включая зарегистрированных после создания начисления.
+type Allowance struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Amount     int64      `json:"amount"`
+	Cadence    string     `json:"cadence"`
+	AllUsers   bool       `json:"allUsers"`
+	Usernames  []string   `json:"usernames,omitempty"`
+	CreatedBy  int64      `json:"-"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastPeriod *time.Time `json:"lastPeriod,omitempty"`
+}
+
+// CreateAllowanceRequest — создание регулярного начисления. Пустой список Usernames означает всех пользователей.
+type CreateAllowanceRequest struct {
+	Name      string   `json:"name" binding:"required,max=200"`
+	Amount    int64    `json:"amount" binding:"required,gt=0"`
+	Cadence   string   `json:"cadence" binding:"required,oneof=daily weekly monthly"`
+	Usernames []string `json:"usernames" binding:"max=1000,dive,required"`
+}
@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+const (
+	ScopeInfo     = "info"
+	ScopeSendCoin = "sendCoin"
+	ScopeBuy      = "buy"
+)
+
+var APIKeyScopes = []string{ScopeInfo, ScopeSendCoin, ScopeBuy}
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RevokedAt  *time.Time `json:"-"`
+}
+
+func (k APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreatedAPIKey содержит открытое значение ключа. Оно возвращается только при создании и нигде не хранится.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
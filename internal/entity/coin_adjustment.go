@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// CoinAdjustment — запись аудита о начислении или списании монет администратором.
+// Сама операция хранится в TransactionID.
+type CoinAdjustment struct {
+	ID            int64     `db:"id"`
+	TransactionID int64     `db:"transaction_id"`
+	AdminID       int64     `db:"admin_id"`
+	Reason        string    `db:"reason"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// MintRequest — начисление amount монет каждому из пользователей, например квартальной премии.
+type MintRequest struct {
+	Usernames []string `json:"usernames" binding:"required,min=1,max=1000,dive,required"`
+	Amount    int64    `json:"amount" binding:"required,gt=0"`
+	Reason    string   `json:"reason" binding:"required,max=500"`
+}
+
+type MintResponse struct {
+	Receipts []Receipt `json:"receipts"`
+}
+
+type BurnRequest struct {
+	Username string `json:"username" binding:"required"`
+	Amount   int64  `json:"amount" binding:"required,gt=0"`
+	Reason   string `json:"reason" binding:"required,max=500"`
+}
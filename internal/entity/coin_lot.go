@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// CoinLot — партия сгорающих монет, выданная операцией TransactionID. Remaining — еще не потраченная
+// часть партии; то, что останется к ExpiresAt, списывается.
+type CoinLot struct {
+	ID            int64     `db:"id"`
+	UserID        int64     `db:"user_id"`
+	TransactionID int64     `db:"transaction_id"`
+	Amount        int64     `db:"amount"`
+	Remaining     int64     `db:"remaining"`
+	GrantedAt     time.Time `db:"granted_at"`
+	ExpiresAt     time.Time `db:"expires_at"`
+}
+
+// CoinExpiration — монеты, которые сгорят в ExpiresAt, если их не потратить.
+type CoinExpiration struct {
+	Amount    int64     `json:"amount" db:"amount"`
+	ExpiresAt time.Time `json:"expiresAt" db:"expires_at"`
+}
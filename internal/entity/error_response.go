@@ -17,6 +17,16 @@ func NewErrorResponse(c *gin.Context, log *logrus.Logger, statusCode int, messag
 		log.Warnf("Bad Request (400): %s", message)
 	case http.StatusUnauthorized:
 		log.Warnf("Unauthorized access (401): %s", message)
+	case http.StatusForbidden:
+		log.Warnf("Forbidden (403): %s", message)
+	case http.StatusNotFound:
+		log.Warnf("Not found (404): %s", message)
+	case http.StatusConflict:
+		log.Warnf("Conflict (409): %s", message)
+	case http.StatusUnprocessableEntity:
+		log.Warnf("Unprocessable entity (422): %s", message)
+	case http.StatusTooManyRequests:
+		log.Warnf("Too many requests (429): %s", message)
 	case http.StatusInternalServerError:
 		log.Errorf("Internal server error (500): %s", message)
 	default:
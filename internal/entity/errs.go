@@ -7,9 +7,44 @@ var (
 	ErrInvalidUserIDType      = errors.New("user id is of invalid type")
 	ErrIncorrectPassword      = errors.New("incorrect password")
 	ErrInvalidSigningMethod   = errors.New("invalid signing method")
+	ErrUnknownSigningKey      = errors.New("unknown signing key")
 	ErrInvalidTokenClaimsType = errors.New("token claims are not of type *tokenClaims")
 	ErrRecipientNotFound      = errors.New("recipient not found")
 	ErrSendThemselves         = errors.New("cannot send coins to yourself")
 	ErrInsufficientBalance    = errors.New("insufficient balance")
 	ErrItemNotFound           = errors.New("item not found")
+	ErrUnknownHashFormat      = errors.New("unknown password hash format")
+	ErrInvalidRefreshToken    = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused     = errors.New("refresh token reuse detected")
+	ErrInvalidToken           = errors.New("invalid or expired token")
+	ErrTokenRevoked           = errors.New("token has been revoked")
+	ErrUserExists             = errors.New("user already exists")
+	ErrInvalidUsername        = errors.New("invalid username")
+	ErrWeakPassword           = errors.New("weak password")
+	ErrInvalidRole            = errors.New("invalid role")
+	ErrTooManyLoginAttempts   = errors.New("too many failed login attempts")
+	ErrInvalidAPIKey          = errors.New("invalid or expired api key")
+	ErrAPIKeyNotFound         = errors.New("api key not found")
+	ErrAPIKeyPrefixTaken      = errors.New("api key prefix is already taken")
+	ErrInvalidScope           = errors.New("invalid scope")
+	ErrInvalidExpiration      = errors.New("expiration must be in the future")
+	ErrTwoFactorRequired      = errors.New("two-factor authentication required")
+	ErrInvalidTwoFactorCode   = errors.New("invalid two-factor code")
+	ErrInvalidChallengeToken  = errors.New("invalid or expired challenge token")
+	ErrTwoFactorEnabled       = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled    = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled   = errors.New("two-factor enrollment has not been started")
+	ErrIdempotencyKeyConflict = errors.New("idempotency key was already used with a different request")
+	ErrTransactionNotFound    = errors.New("transaction not found")
+	ErrInvalidCursor          = errors.New("invalid cursor")
+	ErrInvalidHistoryFilter   = errors.New("invalid history filter")
+	ErrUnbalancedEntry        = errors.New("journal entry is not balanced")
+	ErrTransactionConflict    = errors.New("transaction aborted by a concurrent update")
+	ErrAlreadyReversed        = errors.New("transaction has already been reversed")
+	ErrNotReversible          = errors.New("only transfers can be reversed")
+	ErrNotRefundable          = errors.New("only purchases can be refunded")
+	ErrItemNotOwned           = errors.New("item is no longer in the user's inventory")
+	ErrReasonRequired         = errors.New("reason is required")
+	ErrAllowanceNotFound      = errors.New("allowance not found")
+	ErrAllowanceNameRequired  = errors.New("allowance name is required")
 )
@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+const (
+	DirectionReceived = "received"
+	DirectionSent     = "sent"
+	DirectionPurchase = "purchase"
+	DirectionRefund   = "refund"
+	DirectionMint     = "mint"
+	DirectionBurn     = "burn"
+	// DirectionSignupGrant — стартовые монеты, выпущенные при регистрации.
+	DirectionSignupGrant = "signup_grant"
+	// DirectionAllowance — выплаты регулярных начислений.
+	DirectionAllowance = "allowance"
+	// DirectionExpiration — сгоревшие монеты.
+	DirectionExpiration = "expiration"
+)
+
+// HistoryFilter — параметры запроса GET /api/history.
+type HistoryFilter struct {
+	Direction    string     `form:"direction" binding:"omitempty,oneof=received sent purchase refund mint burn signup_grant allowance expiration"`
+	Counterparty string     `form:"counterparty"`
+	MinAmount    *int64     `form:"minAmount" binding:"omitempty,gt=0"`
+	MaxAmount    *int64     `form:"maxAmount" binding:"omitempty,gt=0"`
+	From         *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor       string     `form:"cursor"`
+	Limit        int        `form:"limit" binding:"omitempty,min=1,max=100"`
+
+	// After — разобранный курсор: выдача продолжается с операций строго старше этой.
+	After *HistoryCursor `form:"-"`
+}
+
+// HistoryCursor указывает на последнюю отданную операцию. Операции упорядочены по (created_at, id) по убыванию.
+type HistoryCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+type HistoryPage struct {
+	Items      []TransactionDetail `json:"items"`
+	NextCursor string              `json:"nextCursor,omitempty"`
+}
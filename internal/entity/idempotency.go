@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+type IdempotencyRecord struct {
+	UserID       int64     `db:"user_id"`
+	Key          string    `db:"key"`
+	RequestHash  string    `db:"request_hash"`
+	StatusCode   int       `db:"status_code"`
+	ResponseBody []byte    `db:"response_body"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// IdempotentResponse — ответ на запрос с Idempotency-Key. Replayed означает, что он взят из сохраненного
+// результата первого запроса, а операция повторно не выполнялась.
+type IdempotentResponse struct {
+	StatusCode int
+	Body       []byte
+	Replayed   bool
+}
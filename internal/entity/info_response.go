@@ -1,9 +1,12 @@
 package entity
 
+import "time"
+
 type InfoResponse struct {
-	Coins       int64           `json:"coins"`
-	Inventory   []InventoryItem `json:"inventory"`
-	CoinHistory CoinHistory     `json:"coinHistory"`
+	Coins         int64            `json:"coins"`
+	ExpiringCoins []CoinExpiration `json:"expiringCoins"`
+	Inventory     []InventoryItem  `json:"inventory"`
+	CoinHistory   CoinHistory      `json:"coinHistory"`
 }
 
 type InventoryItem struct {
@@ -12,12 +15,40 @@ type InventoryItem struct {
 }
 
 type CoinHistory struct {
-	Received []TransactionDetail `json:"received"`
-	Sent     []TransactionDetail `json:"sent"`
+	Received  []TransactionDetail `json:"received"`
+	Sent      []TransactionDetail `json:"sent"`
+	Purchases []PurchaseDetail    `json:"purchases"`
 }
 
 type TransactionDetail struct {
+	ID        int64     `json:"id" db:"id"`
+	Direction string    `json:"direction,omitempty" db:"direction"`
+	FromUser  string    `json:"fromUser,omitempty" db:"from_user"`
+	ToUser    string    `json:"toUser,omitempty" db:"to_user"`
+	Item      string    `json:"item,omitempty" db:"item"`
+	Quantity  int       `json:"quantity,omitempty" db:"quantity"`
+	Amount    int64     `json:"amount" db:"amount"`
+	Reverses  *int64    `json:"reverses,omitempty" db:"reverses_id"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// InfoSummaryResponse — вариант /api/info, в котором история сгруппирована по контрагентам.
+type InfoSummaryResponse struct {
+	Coins         int64              `json:"coins"`
+	ExpiringCoins []CoinExpiration   `json:"expiringCoins"`
+	Inventory     []InventoryItem    `json:"inventory"`
+	CoinHistory   CoinHistorySummary `json:"coinHistory"`
+}
+
+type CoinHistorySummary struct {
+	Received []CounterpartyTotal `json:"received"`
+	Sent     []CounterpartyTotal `json:"sent"`
+}
+
+// CounterpartyTotal — сумма и количество переводов с одним контрагентом.
+type CounterpartyTotal struct {
 	FromUser string `json:"fromUser,omitempty" db:"from_user"`
 	ToUser   string `json:"toUser,omitempty" db:"to_user"`
 	Amount   int64  `json:"amount" db:"amount"`
+	Count    int64  `json:"count" db:"count"`
 }
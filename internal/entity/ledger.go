@@ -0,0 +1,42 @@
+package entity
+
+const (
+	LedgerAccountUser     = "user"
+	LedgerAccountShop     = "shop"
+	LedgerAccountIssuance = "issuance"
+)
+
+const (
+	JournalSignupGrant = "signup_grant"
+	JournalTransfer    = "transfer"
+	JournalPurchase    = "purchase"
+	JournalReversal    = "reversal"
+	JournalRefund      = "refund"
+	JournalMint        = "mint"
+	JournalBurn        = "burn"
+	JournalAllowance   = "allowance"
+	JournalExpiration  = "expiration"
+)
+
+// LedgerPosting — проводка по счету: положительная сумма увеличивает остаток, отрицательная уменьшает.
+type LedgerPosting struct {
+	AccountID int64 `db:"account_id"`
+	Amount    int64 `db:"amount"`
+}
+
+// JournalEntry — запись журнала двойной записи. Сумма ее проводок всегда равна нулю.
+type JournalEntry struct {
+	ID            int64
+	Kind          string
+	TransactionID *int64
+	Postings      []LedgerPosting
+}
+
+func (e JournalEntry) Balanced() bool {
+	var sum int64
+	for _, posting := range e.Postings {
+		sum += posting.Amount
+	}
+
+	return len(e.Postings) >= 2 && sum == 0
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// LoginLockedError сообщает, что вход временно заблокирован после серии неудачных попыток.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return ErrTooManyLoginAttempts.Error()
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// Purchase — покупка мерча. Цена фиксируется на момент покупки, списание монет хранится в TransactionID.
+type Purchase struct {
+	ID            int64     `db:"id"`
+	UserID        int64     `db:"user_id"`
+	MerchID       int64     `db:"merch_id"`
+	UnitPrice     int64     `db:"unit_price"`
+	Quantity      int       `db:"quantity"`
+	TransactionID int64     `db:"transaction_id"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// PurchaseDetail — покупка в истории пользователя. ID совпадает с ID операции списания.
+type PurchaseDetail struct {
+	ID        int64     `json:"id" db:"id"`
+	Item      string    `json:"item" db:"item"`
+	UnitPrice int64     `json:"unitPrice" db:"unit_price"`
+	Quantity  int       `json:"quantity" db:"quantity"`
+	Amount    int64     `json:"amount" db:"amount"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
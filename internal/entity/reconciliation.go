@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// BalanceDrift — расхождение кэша users.coins с остатком счета пользователя в журнале.
+type BalanceDrift struct {
+	UserID         int64  `json:"userId" db:"user_id"`
+	Username       string `json:"username" db:"username"`
+	Cached         int64  `json:"cached" db:"cached"`
+	Expected       int64  `json:"expected" db:"expected"`
+	Drift          int64  `json:"drift"`
+	MissingAccount bool   `json:"missingAccount,omitempty" db:"missing_account"`
+	Repaired       bool   `json:"repaired"`
+}
+
+type ReconciliationReport struct {
+	CheckedAt        time.Time      `json:"checkedAt"`
+	CheckedAccounts  int            `json:"checkedAccounts"`
+	DriftedAccounts  int            `json:"driftedAccounts"`
+	RepairedAccounts int            `json:"repairedAccounts"`
+	Drifts           []BalanceDrift `json:"drifts"`
+}
@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+const (
+	TransactionTypeTransfer = "transfer"
+	TransactionTypePurchase = "purchase"
+	TransactionTypeReversal = "reversal"
+	TransactionTypeRefund   = "refund"
+	TransactionTypeMint     = "mint"
+	TransactionTypeBurn     = "burn"
+	// TransactionTypeSignupGrant — выпуск стартовых монет при регистрации.
+	TransactionTypeSignupGrant = "signup_grant"
+	// TransactionTypeAllowance — выплата регулярного начисления.
+	TransactionTypeAllowance = "allowance"
+	// TransactionTypeExpiration — списание сгоревших монет.
+	TransactionTypeExpiration = "expiration"
+)
+
+// Transaction — запись о списании монет: перевод другому пользователю или покупка мерча.
+// Балансы после операции сохраняются, чтобы квитанцию можно было получить позже.
+// Сторно и возврат ссылаются на отменяемую операцию через ReversesID.
+type Transaction struct {
+	ID               int64     `db:"id"`
+	Type             string    `db:"type"`
+	FromUserID       int64     `db:"from_user"`
+	ToUserID         *int64    `db:"to_user"`
+	MerchID          *int64    `db:"merch_id"`
+	Amount           int64     `db:"amount"`
+	SenderBalance    *int64    `db:"sender_balance"`
+	RecipientBalance *int64    `db:"recipient_balance"`
+	ReversesID       *int64    `db:"reverses_id"`
+	ReversedByID     *int64    `db:"reversed_by"`
+	CreatedAt        time.Time `db:"created_at"`
+	FromUsername     string    `db:"from_username"`
+	ToUsername       string    `db:"to_username"`
+	Item             string    `db:"item"`
+}
+
+// Receipt — квитанция об операции с точки зрения одного из ее участников:
+// для отправителя указывается получатель, для получателя — отправитель, для покупки — товар.
+type Receipt struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	Amount    int64     `json:"amount"`
+	FromUser  string    `json:"fromUser,omitempty"`
+	ToUser    string    `json:"toUser,omitempty"`
+	Item      string    `json:"item,omitempty"`
+	Balance   *int64    `json:"balance,omitempty"`
+	Reverses  *int64    `json:"reverses,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ReversalRequest — тело запросов на сторно перевода и возврат покупки.
+// ForceNegative разрешает списать монеты, даже если баланс пользователя уйдет в минус.
+type ReversalRequest struct {
+	ForceNegative bool `json:"forceNegative"`
+}
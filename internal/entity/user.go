@@ -1,8 +1,24 @@
 package entity
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID       int64  `json:"-" db:"id"`
 	Username string `json:"username" db:"username"`
 	Password string `json:"password" db:"password_hash"`
 	Coins    int64  `json:"coins" db:"coins"`
+	Role     string `json:"role" db:"role"`
+
+	TwoFactorEnabled bool `json:"-" db:"two_factor_enabled"`
+}
+
+type RoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
 }
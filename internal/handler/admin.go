@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func (h *Handler) setUserRole(c *gin.Context) {
+	username := c.Param("username")
+
+	var input entity.RoleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	err := h.services.Authorization.SetUserRole(c.Request.Context(), username, input.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidRole):
+			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, err.Error())
+		case errors.Is(err, entity.ErrUserNotFound):
+			entity.NewErrorResponse(c, h.log, http.StatusNotFound, err.Error())
+		default:
+			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.StatusResponse{
+		Status: "role updated",
+	})
+}
+
+func (h *Handler) unlockUser(c *gin.Context) {
+	err := h.services.Authorization.UnlockUser(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			entity.NewErrorResponse(c, h.log, http.StatusNotFound, err.Error())
+			return
+		}
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.StatusResponse{
+		Status: "user unlocked",
+	})
+}
+
+// reverseTransfer отменяет перевод. Тело запроса необязательно: без него forceNegative = false.
+func (h *Handler) reverseTransfer(c *gin.Context) {
+	adminID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	transactionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid transaction id")
+		return
+	}
+
+	var input entity.ReversalRequest
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	receipt, err := h.services.Reversal.ReverseTransfer(c.Request.Context(), adminID, transactionID, input.ForceNegative)
+	if err != nil {
+		h.reversalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+func (h *Handler) refundPurchase(c *gin.Context) {
+	adminID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	transactionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid transaction id")
+		return
+	}
+
+	receipt, err := h.services.Reversal.RefundPurchase(c.Request.Context(), adminID, transactionID)
+	if err != nil {
+		h.reversalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+func (h *Handler) mintCoins(c *gin.Context) {
+	adminID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	var input entity.MintRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	response, err := h.services.Issuance.MintCoins(c.Request.Context(), adminID, input)
+	if err != nil {
+		h.issuanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) burnCoins(c *gin.Context) {
+	adminID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	var input entity.BurnRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	receipt, err := h.services.Issuance.BurnCoins(c.Request.Context(), adminID, input)
+	if err != nil {
+		h.issuanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+func (h *Handler) issuanceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, entity.ErrReasonRequired), errors.Is(err, entity.ErrInsufficientBalance):
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrUserNotFound):
+		entity.NewErrorResponse(c, h.log, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrTransactionConflict):
+		entity.NewErrorResponse(c, h.log, http.StatusServiceUnavailable, "concurrent update, please retry")
+	default:
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+func (h *Handler) reversalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, entity.ErrTransactionNotFound):
+		entity.NewErrorResponse(c, h.log, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrNotReversible), errors.Is(err, entity.ErrNotRefundable),
+		errors.Is(err, entity.ErrInsufficientBalance):
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrAlreadyReversed), errors.Is(err, entity.ErrItemNotOwned):
+		entity.NewErrorResponse(c, h.log, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrTransactionConflict):
+		entity.NewErrorResponse(c, h.log, http.StatusServiceUnavailable, "concurrent update, please retry")
+	default:
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+func (h *Handler) createAllowance(c *gin.Context) {
+	adminID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	var input entity.CreateAllowanceRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	allowance, err := h.services.Allowance.CreateAllowance(c.Request.Context(), adminID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrAllowanceNameRequired):
+			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, err.Error())
+		case errors.Is(err, entity.ErrUserNotFound):
+			entity.NewErrorResponse(c, h.log, http.StatusNotFound, err.Error())
+		default:
+			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, allowance)
+}
+
+func (h *Handler) listAllowances(c *gin.Context) {
+	allowances, err := h.services.Allowance.ListAllowances(c.Request.Context())
+	if err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, allowances)
+}
+
+func (h *Handler) disableAllowance(c *gin.Context) {
+	adminID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	allowanceID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid allowance id")
+		return
+	}
+
+	if err := h.services.Allowance.DisableAllowance(c.Request.Context(), adminID, allowanceID); err != nil {
+		if errors.Is(err, entity.ErrAllowanceNotFound) {
+			entity.NewErrorResponse(c, h.log, http.StatusNotFound, err.Error())
+			return
+		}
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.StatusResponse{
+		Status: "allowance disabled",
+	})
+}
@@ -0,0 +1,690 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/service"
+	mocks "github.com/senyabanana/shop-service/internal/service/mocks"
+)
+
+func TestHandler_SetUserRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Success",
+			requestBody: `{"role":"admin"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().SetUserRole(gomock.Any(), "testuser", entity.RoleAdmin).Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"role updated"}`,
+		},
+		{
+			name:         "Invalid Request Format",
+			requestBody:  `{}`,
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid request format"}`,
+		},
+		{
+			name:        "Invalid Role",
+			requestBody: `{"role":"root"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().SetUserRole(gomock.Any(), "testuser", "root").Return(entity.ErrInvalidRole)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"invalid role"}`,
+		},
+		{
+			name:        "User Not Found",
+			requestBody: `{"role":"admin"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().SetUserRole(gomock.Any(), "testuser", entity.RoleAdmin).Return(entity.ErrUserNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `{"errors":"user not found"}`,
+		},
+		{
+			name:        "Internal Error",
+			requestBody: `{"role":"admin"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().SetUserRole(gomock.Any(), "testuser", entity.RoleAdmin).Return(errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/admin/users/testuser/role", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = gin.Params{{Key: "username", Value: "testuser"}}
+
+			handler.setUserRole(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_UnlockUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mockAuthService.EXPECT().UnlockUser(gomock.Any(), "testuser").Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"user unlocked"}`,
+		},
+		{
+			name: "User Not Found",
+			mockBehavior: func() {
+				mockAuthService.EXPECT().UnlockUser(gomock.Any(), "testuser").Return(entity.ErrUserNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `{"errors":"user not found"}`,
+		},
+		{
+			name: "Internal Error",
+			mockBehavior: func() {
+				mockAuthService.EXPECT().UnlockUser(gomock.Any(), "testuser").Return(errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/admin/users/testuser/unlock", nil)
+			c.Params = gin.Params{{Key: "username", Value: "testuser"}}
+
+			handler.unlockUser(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_ReverseTransfer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReversalService := mocks.NewMockReversal(ctrl)
+	mockService := &service.Service{Reversal: mockReversalService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	reverses := int64(7)
+	receipt := entity.Receipt{
+		ID:        9,
+		Type:      entity.TransactionTypeReversal,
+		Amount:    100,
+		FromUser:  "user2",
+		ToUser:    "user1",
+		Reverses:  &reverses,
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name          string
+		transactionID string
+		requestBody   string
+		mockBehavior  func()
+		wantCode      int
+		wantBody      string
+	}{
+		{
+			name:          "Success Without Body",
+			transactionID: "7",
+			mockBehavior: func() {
+				mockReversalService.EXPECT().ReverseTransfer(gomock.Any(), int64(1), int64(7), false).Return(receipt, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":9,"type":"reversal","amount":100,"fromUser":"user2","toUser":"user1","reverses":7,"createdAt":"2025-01-01T12:00:00Z"}`,
+		},
+		{
+			name:          "Force Negative",
+			transactionID: "7",
+			requestBody:   `{"forceNegative":true}`,
+			mockBehavior: func() {
+				mockReversalService.EXPECT().ReverseTransfer(gomock.Any(), int64(1), int64(7), true).Return(receipt, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":9,"type":"reversal","amount":100,"fromUser":"user2","toUser":"user1","reverses":7,"createdAt":"2025-01-01T12:00:00Z"}`,
+		},
+		{
+			name:          "Invalid ID",
+			transactionID: "abc",
+			mockBehavior:  func() {},
+			wantCode:      http.StatusBadRequest,
+			wantBody:      `{"errors":"invalid transaction id"}`,
+		},
+		{
+			name:          "Invalid Request Format",
+			transactionID: "7",
+			requestBody:   `{"forceNegative":"yes"}`,
+			mockBehavior:  func() {},
+			wantCode:      http.StatusBadRequest,
+			wantBody:      `{"errors":"invalid request format"}`,
+		},
+		{
+			name:          "Not Found",
+			transactionID: "7",
+			mockBehavior: func() {
+				mockReversalService.EXPECT().ReverseTransfer(gomock.Any(), int64(1), int64(7), false).Return(entity.Receipt{}, entity.ErrTransactionNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `{"errors":"transaction not found"}`,
+		},
+		{
+			name:          "Not Reversible",
+			transactionID: "7",
+			mockBehavior: func() {
+				mockReversalService.EXPECT().ReverseTransfer(gomock.Any(), int64(1), int64(7), false).Return(entity.Receipt{}, entity.ErrNotReversible)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"only transfers can be reversed"}`,
+		},
+		{
+			name:          "Insufficient Balance",
+			transactionID: "7",
+			mockBehavior: func() {
+				mockReversalService.EXPECT().ReverseTransfer(gomock.Any(), int64(1), int64(7), false).Return(entity.Receipt{}, entity.ErrInsufficientBalance)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"insufficient balance"}`,
+		},
+		{
+			name:          "Already Reversed",
+			transactionID: "7",
+			mockBehavior: func() {
+				mockReversalService.EXPECT().ReverseTransfer(gomock.Any(), int64(1), int64(7), false).Return(entity.Receipt{}, entity.ErrAlreadyReversed)
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `{"errors":"transaction has already been reversed"}`,
+		},
+		{
+			name:          "Internal Error",
+			transactionID: "7",
+			mockBehavior: func() {
+				mockReversalService.EXPECT().ReverseTransfer(gomock.Any(), int64(1), int64(7), false).Return(entity.Receipt{}, errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/transactions/"+tt.transactionID+"/reverse", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: tt.transactionID}}
+			c.Set(userCtx, int64(1))
+
+			handler.reverseTransfer(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_RefundPurchase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReversalService := mocks.NewMockReversal(ctrl)
+	mockService := &service.Service{Reversal: mockReversalService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	reverses := int64(8)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mockReversalService.EXPECT().RefundPurchase(gomock.Any(), int64(1), int64(8)).Return(entity.Receipt{
+					ID:        11,
+					Type:      entity.TransactionTypeRefund,
+					Amount:    80,
+					FromUser:  "user1",
+					Item:      "t-shirt",
+					Reverses:  &reverses,
+					CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":11,"type":"refund","amount":80,"fromUser":"user1","item":"t-shirt","reverses":8,"createdAt":"2025-01-01T12:00:00Z"}`,
+		},
+		{
+			name: "Not Refundable",
+			mockBehavior: func() {
+				mockReversalService.EXPECT().RefundPurchase(gomock.Any(), int64(1), int64(8)).Return(entity.Receipt{}, entity.ErrNotRefundable)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"only purchases can be refunded"}`,
+		},
+		{
+			name: "Item Not Owned",
+			mockBehavior: func() {
+				mockReversalService.EXPECT().RefundPurchase(gomock.Any(), int64(1), int64(8)).Return(entity.Receipt{}, entity.ErrItemNotOwned)
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `{"errors":"item is no longer in the user's inventory"}`,
+		},
+		{
+			name: "Transaction Conflict",
+			mockBehavior: func() {
+				mockReversalService.EXPECT().RefundPurchase(gomock.Any(), int64(1), int64(8)).Return(entity.Receipt{}, entity.ErrTransactionConflict)
+			},
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: `{"errors":"concurrent update, please retry"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/admin/transactions/8/refund", nil)
+			c.Params = gin.Params{{Key: "id", Value: "8"}}
+			c.Set(userCtx, int64(1))
+
+			handler.refundPurchase(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_MintCoins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIssuanceService := mocks.NewMockIssuance(ctrl)
+	mockService := &service.Service{Issuance: mockIssuanceService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	input := entity.MintRequest{Usernames: []string{"alice"}, Amount: 500, Reason: "Q1 bonus"}
+	balance := int64(1500)
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Success",
+			requestBody: `{"usernames":["alice"],"amount":500,"reason":"Q1 bonus"}`,
+			mockBehavior: func() {
+				mockIssuanceService.EXPECT().MintCoins(gomock.Any(), int64(1), input).Return(entity.MintResponse{Receipts: []entity.Receipt{{
+					ID:        8,
+					Type:      entity.TransactionTypeMint,
+					Amount:    500,
+					ToUser:    "alice",
+					Balance:   &balance,
+					Reason:    "Q1 bonus",
+					CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+				}}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"receipts":[{"id":8,"type":"mint","amount":500,"toUser":"alice","balance":1500,"reason":"Q1 bonus","createdAt":"2025-01-01T12:00:00Z"}]}`,
+		},
+		{
+			name:         "Missing Reason",
+			requestBody:  `{"usernames":["alice"],"amount":500}`,
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid request format"}`,
+		},
+		{
+			name:         "Empty User List",
+			requestBody:  `{"usernames":[],"amount":500,"reason":"Q1 bonus"}`,
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid request format"}`,
+		},
+		{
+			name:        "User Not Found",
+			requestBody: `{"usernames":["alice"],"amount":500,"reason":"Q1 bonus"}`,
+			mockBehavior: func() {
+				mockIssuanceService.EXPECT().MintCoins(gomock.Any(), int64(1), input).
+					Return(entity.MintResponse{}, fmt.Errorf("%w: %s", entity.ErrUserNotFound, "alice"))
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `{"errors":"user not found: alice"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/coins/mint", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Set(userCtx, int64(1))
+
+			handler.mintCoins(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_BurnCoins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIssuanceService := mocks.NewMockIssuance(ctrl)
+	mockService := &service.Service{Issuance: mockIssuanceService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	input := entity.BurnRequest{Username: "alice", Amount: 300, Reason: "duplicate bonus"}
+	requestBody := `{"username":"alice","amount":300,"reason":"duplicate bonus"}`
+	balance := int64(700)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mockIssuanceService.EXPECT().BurnCoins(gomock.Any(), int64(1), input).Return(entity.Receipt{
+					ID:        12,
+					Type:      entity.TransactionTypeBurn,
+					Amount:    300,
+					FromUser:  "alice",
+					Balance:   &balance,
+					Reason:    "duplicate bonus",
+					CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":12,"type":"burn","amount":300,"fromUser":"alice","balance":700,"reason":"duplicate bonus","createdAt":"2025-01-01T12:00:00Z"}`,
+		},
+		{
+			name: "Insufficient Balance",
+			mockBehavior: func() {
+				mockIssuanceService.EXPECT().BurnCoins(gomock.Any(), int64(1), input).Return(entity.Receipt{}, entity.ErrInsufficientBalance)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"insufficient balance"}`,
+		},
+		{
+			name: "Blank Reason",
+			mockBehavior: func() {
+				mockIssuanceService.EXPECT().BurnCoins(gomock.Any(), int64(1), input).Return(entity.Receipt{}, entity.ErrReasonRequired)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"reason is required"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/coins/burn", bytes.NewBufferString(requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Set(userCtx, int64(1))
+
+			handler.burnCoins(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_CreateAllowance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAllowanceService := mocks.NewMockAllowance(ctrl)
+	mockService := &service.Service{Allowance: mockAllowanceService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	input := entity.CreateAllowanceRequest{Name: "team", Amount: 50, Cadence: entity.CadenceWeekly, Usernames: []string{"alice"}}
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Success",
+			requestBody: `{"name":"team","amount":50,"cadence":"weekly","usernames":["alice"]}`,
+			mockBehavior: func() {
+				mockAllowanceService.EXPECT().CreateAllowance(gomock.Any(), int64(1), input).Return(entity.Allowance{
+					ID:        3,
+					Name:      "team",
+					Amount:    50,
+					Cadence:   entity.CadenceWeekly,
+					Usernames: []string{"alice"},
+					CreatedBy: 1,
+					CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+				}, nil)
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `{"id":3,"name":"team","amount":50,"cadence":"weekly","allUsers":false,"usernames":["alice"],"createdAt":"2025-01-01T12:00:00Z"}`,
+		},
+		{
+			name:         "Unknown Cadence",
+			requestBody:  `{"name":"team","amount":50,"cadence":"hourly"}`,
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid request format"}`,
+		},
+		{
+			name:         "Non Positive Amount",
+			requestBody:  `{"name":"team","amount":-5,"cadence":"weekly"}`,
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid request format"}`,
+		},
+		{
+			name:        "User Not Found",
+			requestBody: `{"name":"team","amount":50,"cadence":"weekly","usernames":["alice"]}`,
+			mockBehavior: func() {
+				mockAllowanceService.EXPECT().CreateAllowance(gomock.Any(), int64(1), input).
+					Return(entity.Allowance{}, fmt.Errorf("%w: %s", entity.ErrUserNotFound, "alice"))
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `{"errors":"user not found: alice"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/allowances", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Set(userCtx, int64(1))
+
+			handler.createAllowance(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_DisableAllowance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAllowanceService := mocks.NewMockAllowance(ctrl)
+	mockService := &service.Service{Allowance: mockAllowanceService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	tests := []struct {
+		name         string
+		allowanceID  string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Success",
+			allowanceID: "3",
+			mockBehavior: func() {
+				mockAllowanceService.EXPECT().DisableAllowance(gomock.Any(), int64(1), int64(3)).Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"allowance disabled"}`,
+		},
+		{
+			name:        "Not Found",
+			allowanceID: "3",
+			mockBehavior: func() {
+				mockAllowanceService.EXPECT().DisableAllowance(gomock.Any(), int64(1), int64(3)).Return(entity.ErrAllowanceNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `{"errors":"allowance not found"}`,
+		},
+		{
+			name:         "Invalid ID",
+			allowanceID:  "abc",
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid allowance id"}`,
+		},
+		{
+			name:        "Internal Error",
+			allowanceID: "3",
+			mockBehavior: func() {
+				mockAllowanceService.EXPECT().DisableAllowance(gomock.Any(), int64(1), int64(3)).Return(errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/admin/allowances/"+tt.allowanceID, nil)
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: tt.allowanceID}}
+			c.Set(userCtx, int64(1))
+
+			handler.disableAllowance(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func (h *Handler) createAPIKey(c *gin.Context) {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	var input entity.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	key, err := h.services.APIKey.CreateAPIKey(c.Request.Context(), userID, input)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidScope) || errors.Is(err, entity.ErrInvalidExpiration) {
+			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, err.Error())
+			return
+		}
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *Handler) listAPIKeys(c *gin.Context) {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	keys, err := h.services.APIKey.ListAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *Handler) revokeAPIKey(c *gin.Context) {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid api key id")
+		return
+	}
+
+	if err := h.services.APIKey.RevokeAPIKey(c.Request.Context(), userID, keyID); err != nil {
+		if errors.Is(err, entity.ErrAPIKeyNotFound) {
+			entity.NewErrorResponse(c, h.log, http.StatusNotFound, err.Error())
+			return
+		}
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.StatusResponse{
+		Status: "api key revoked",
+	})
+}
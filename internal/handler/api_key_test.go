@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/service"
+	mocks "github.com/senyabanana/shop-service/internal/service/mocks"
+)
+
+func TestHandler_CreateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyService := mocks.NewMockAPIKey(ctrl)
+	mockService := &service.Service{APIKey: mockAPIKeyService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Success",
+			requestBody: `{"name":"bot","scopes":["info"]}`,
+			mockBehavior: func() {
+				mockAPIKeyService.EXPECT().CreateAPIKey(gomock.Any(), int64(1), entity.CreateAPIKeyRequest{Name: "bot", Scopes: []string{"info"}}).
+					Return(entity.CreatedAPIKey{
+						APIKey: entity.APIKey{ID: 7, Name: "bot", Prefix: "0a1b2c3d", Scopes: []string{"info"}, ExpiresAt: expiresAt, CreatedAt: createdAt},
+						Key:    "shop_0a1b2c3d_secret",
+					}, nil)
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `{"id":7,"name":"bot","prefix":"0a1b2c3d","scopes":["info"],"expiresAt":"2030-01-01T00:00:00Z","lastUsedAt":null,"createdAt":"2025-01-01T00:00:00Z","key":"shop_0a1b2c3d_secret"}`,
+		},
+		{
+			name:         "Invalid Request Format",
+			requestBody:  `{"scopes":["info"]}`,
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid request format"}`,
+		},
+		{
+			name:        "Invalid Scope",
+			requestBody: `{"name":"bot","scopes":["admin"]}`,
+			mockBehavior: func() {
+				mockAPIKeyService.EXPECT().CreateAPIKey(gomock.Any(), int64(1), gomock.Any()).
+					Return(entity.CreatedAPIKey{}, fmt.Errorf("%w: admin", entity.ErrInvalidScope))
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"invalid scope: admin"}`,
+		},
+		{
+			name:        "Internal Error",
+			requestBody: `{"name":"bot","scopes":["info"]}`,
+			mockBehavior: func() {
+				mockAPIKeyService.EXPECT().CreateAPIKey(gomock.Any(), int64(1), gomock.Any()).
+					Return(entity.CreatedAPIKey{}, errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/keys", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Set(userCtx, int64(1))
+
+			handler.createAPIKey(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_RevokeAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyService := mocks.NewMockAPIKey(ctrl)
+	mockService := &service.Service{APIKey: mockAPIKeyService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	tests := []struct {
+		name         string
+		keyID        string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:  "Success",
+			keyID: "7",
+			mockBehavior: func() {
+				mockAPIKeyService.EXPECT().RevokeAPIKey(gomock.Any(), int64(1), int64(7)).Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"api key revoked"}`,
+		},
+		{
+			name:         "Invalid ID",
+			keyID:        "abc",
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid api key id"}`,
+		},
+		{
+			name:  "Not Found",
+			keyID: "8",
+			mockBehavior: func() {
+				mockAPIKeyService.EXPECT().RevokeAPIKey(gomock.Any(), int64(1), int64(8)).Return(entity.ErrAPIKeyNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `{"errors":"api key not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/keys/"+tt.keyID, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.keyID}}
+			c.Set(userCtx, int64(1))
+
+			handler.revokeAPIKey(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -16,19 +19,141 @@ func (h *Handler) authenticate(c *gin.Context) {
 		return
 	}
 
-	_, err := h.services.Authorization.GetUser(c.Request.Context(), input.Username)
+	if h.autoRegister {
+		_, err := h.services.Authorization.GetUser(c.Request.Context(), input.Username)
+		if err != nil {
+			if err := h.services.Authorization.CreateUser(c.Request.Context(), input.Username, input.Password); err != nil {
+				h.registrationError(c, err)
+				return
+			}
+		}
+	}
+
+	tokens, err := h.services.Authorization.GenerateToken(c.Request.Context(), input.Username, input.Password, c.ClientIP())
+	if err != nil {
+		var (
+			lockErr      *entity.LoginLockedError
+			challengeErr *entity.TwoFactorRequiredError
+		)
+		switch {
+		case errors.As(err, &challengeErr):
+			c.JSON(http.StatusOK, entity.TwoFactorChallenge{
+				TwoFactorRequired: true,
+				ChallengeToken:    challengeErr.ChallengeToken,
+			})
+		case errors.As(err, &lockErr):
+			h.loginLocked(c, lockErr)
+		case errors.Is(err, entity.ErrUserNotFound), errors.Is(err, entity.ErrIncorrectPassword):
+			entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, "invalid username or password")
+		default:
+			entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// loginLocked отвечает 429 с заголовком Retry-After, пока вход заблокирован.
+func (h *Handler) loginLocked(c *gin.Context, lockErr *entity.LoginLockedError) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockErr.RetryAfter.Seconds()))))
+	entity.NewErrorResponse(c, h.log, http.StatusTooManyRequests, "too many failed login attempts, try again later")
+}
+
+func (h *Handler) register(c *gin.Context) {
+	var input entity.AuthRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	tokens, err := h.services.Authorization.Register(c.Request.Context(), input.Username, input.Password)
+	if err != nil {
+		h.registrationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tokens)
+}
+
+func (h *Handler) registrationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, entity.ErrUserExists):
+		entity.NewErrorResponse(c, h.log, http.StatusConflict, "user already exists")
+	case errors.Is(err, entity.ErrWeakPassword), errors.Is(err, entity.ErrInvalidUsername):
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorf("registration failed: %v", err)
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+func (h *Handler) refresh(c *gin.Context) {
+	var input entity.RefreshRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	tokens, err := h.services.Authorization.RefreshToken(c.Request.Context(), input.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidRefreshToken):
+			entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, "invalid or expired refresh token")
+		case errors.Is(err, entity.ErrRefreshTokenReused):
+			entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, "refresh token reuse detected")
+		default:
+			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) logout(c *gin.Context) {
+	claims, err := h.getTokenClaims(c)
 	if err != nil {
-		if err := h.services.Authorization.CreateUser(c.Request.Context(), input.Username, input.Password); err != nil {
-			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var input entity.LogoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
 			return
 		}
 	}
 
-	token, err := h.services.Authorization.GenerateToken(c.Request.Context(), input.Username, input.Password)
+	if err := h.services.Authorization.Logout(c.Request.Context(), claims, input.RefreshToken); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.StatusResponse{
+		Status: "successfully logged out",
+	})
+}
+
+func (h *Handler) logoutAll(c *gin.Context) {
+	userID, err := h.getUserID(c)
 	if err != nil {
-		entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, entity.AuthResponse{Token: token})
+	if err := h.services.Authorization.RevokeAllSessions(c.Request.Context(), userID); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.StatusResponse{
+		Status: "all sessions were revoked",
+	})
+}
+
+func (h *Handler) jwks(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.services.Authorization.GetJWKS())
 }
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -27,7 +29,7 @@ func TestHandler_Authenticate(t *testing.T) {
 	mockAuthService := mocks.NewMockAuthorization(ctrl)
 	mockService := &service.Service{Authorization: mockAuthService}
 	mockLog := logrus.New()
-	handler := &Handler{services: mockService, log: mockLog}
+	handler := &Handler{services: mockService, autoRegister: true, log: mockLog}
 
 	tests := []struct {
 		name         string
@@ -44,10 +46,10 @@ func TestHandler_Authenticate(t *testing.T) {
 			},
 			mockBehavior: func() {
 				mockAuthService.EXPECT().GetUser(gomock.Any(), "testuser").Return(entity.User{ID: 1, Username: "testuser"}, nil)
-				mockAuthService.EXPECT().GenerateToken(gomock.Any(), "testuser", "testpass").Return("jwt-token", nil)
+				mockAuthService.EXPECT().GenerateToken(gomock.Any(), "testuser", "testpass", gomock.Any()).Return(entity.AuthResponse{Token: "jwt-token", RefreshToken: "refresh-token"}, nil)
 			},
 			wantCode: http.StatusOK,
-			wantBody: `{"token":"jwt-token"}`,
+			wantBody: `{"token":"jwt-token","refreshToken":"refresh-token"}`,
 		},
 		{
 			name: "User Created",
@@ -58,10 +60,10 @@ func TestHandler_Authenticate(t *testing.T) {
 			mockBehavior: func() {
 				mockAuthService.EXPECT().GetUser(gomock.Any(), "newuser").Return(entity.User{}, errors.New("user not found"))
 				mockAuthService.EXPECT().CreateUser(gomock.Any(), "newuser", "newpass").Return(nil)
-				mockAuthService.EXPECT().GenerateToken(gomock.Any(), "newuser", "newpass").Return("jwt-token", nil)
+				mockAuthService.EXPECT().GenerateToken(gomock.Any(), "newuser", "newpass", gomock.Any()).Return(entity.AuthResponse{Token: "jwt-token", RefreshToken: "refresh-token"}, nil)
 			},
 			wantCode: http.StatusOK,
-			wantBody: `{"token":"jwt-token"}`,
+			wantBody: `{"token":"jwt-token","refreshToken":"refresh-token"}`,
 		},
 		{
 			name:         "Invalid Request Format",
@@ -79,10 +81,10 @@ func TestHandler_Authenticate(t *testing.T) {
 			mockBehavior: func() {
 				mockAuthService.EXPECT().GetUser(gomock.Any(), "failuser").Return(entity.User{}, errors.New("user not found"))
 				mockAuthService.EXPECT().CreateUser(gomock.Any(), "failuser", "failpass").Return(errors.New("creation failed"))
-				mockAuthService.EXPECT().GenerateToken(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				mockAuthService.EXPECT().GenerateToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			wantCode: http.StatusInternalServerError,
-			wantBody: `{"errors":"creation failed"}`,
+			wantBody: `{"errors":"internal server error"}`,
 		},
 		{
 			name: "Error Generating Token",
@@ -92,11 +94,38 @@ func TestHandler_Authenticate(t *testing.T) {
 			},
 			mockBehavior: func() {
 				mockAuthService.EXPECT().GetUser(gomock.Any(), "testuser").Return(entity.User{ID: 1, Username: "testuser"}, nil)
-				mockAuthService.EXPECT().GenerateToken(gomock.Any(), "testuser", "testpass").Return("", errors.New("token error"))
+				mockAuthService.EXPECT().GenerateToken(gomock.Any(), "testuser", "testpass", gomock.Any()).Return(entity.AuthResponse{}, errors.New("token error"))
 			},
 			wantCode: http.StatusUnauthorized,
 			wantBody: `{"errors":"token error"}`,
 		},
+		{
+			name: "Weak Password On Auto Registration",
+			requestBody: entity.AuthRequest{
+				Username: "newuser",
+				Password: "short",
+			},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().GetUser(gomock.Any(), "newuser").Return(entity.User{}, entity.ErrUserNotFound)
+				mockAuthService.EXPECT().CreateUser(gomock.Any(), "newuser", "short").
+					Return(fmt.Errorf("%w: password must be at least 8 characters long", entity.ErrWeakPassword))
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"weak password: password must be at least 8 characters long"}`,
+		},
+		{
+			name: "Incorrect Password",
+			requestBody: entity.AuthRequest{
+				Username: "testuser",
+				Password: "wrongpass",
+			},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().GetUser(gomock.Any(), "testuser").Return(entity.User{ID: 1, Username: "testuser"}, nil)
+				mockAuthService.EXPECT().GenerateToken(gomock.Any(), "testuser", "wrongpass", gomock.Any()).Return(entity.AuthResponse{}, entity.ErrIncorrectPassword)
+			},
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"errors":"invalid username or password"}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -118,3 +147,467 @@ func TestHandler_Authenticate(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_AuthenticateWithoutAutoRegister(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, autoRegister: false, log: mockLog}
+
+	tests := []struct {
+		name         string
+		requestBody  entity.AuthRequest
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Existing User",
+			requestBody: entity.AuthRequest{Username: "testuser", Password: "testpass"},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().GenerateToken(gomock.Any(), "testuser", "testpass", gomock.Any()).
+					Return(entity.AuthResponse{Token: "jwt-token", RefreshToken: "refresh-token"}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"token":"jwt-token","refreshToken":"refresh-token"}`,
+		},
+		{
+			name:        "Unknown User Rejected",
+			requestBody: entity.AuthRequest{Username: "typo", Password: "testpass"},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				mockAuthService.EXPECT().GenerateToken(gomock.Any(), "typo", "testpass", gomock.Any()).
+					Return(entity.AuthResponse{}, entity.ErrUserNotFound)
+			},
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"errors":"invalid username or password"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/api/auth", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			handler.authenticate(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_AuthenticateLocked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	mockAuthService.EXPECT().GenerateToken(gomock.Any(), "testuser", "testpass", "10.0.0.1").
+		Return(entity.AuthResponse{}, &entity.LoginLockedError{RetryAfter: 1500 * time.Millisecond})
+
+	body, _ := json.Marshal(entity.AuthRequest{Username: "testuser", Password: "testpass"})
+	req := httptest.NewRequest(http.MethodPost, "/api/auth", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "10.0.0.1:12345"
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.authenticate(c)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"errors":"too many failed login attempts, try again later"}`, w.Body.String())
+}
+
+func TestHandler_AuthenticateTwoFactorRequired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	mockAuthService.EXPECT().GenerateToken(gomock.Any(), "testuser", "testpass", gomock.Any()).
+		Return(entity.AuthResponse{}, &entity.TwoFactorRequiredError{ChallengeToken: "challenge"})
+
+	body, _ := json.Marshal(entity.AuthRequest{Username: "testuser", Password: "testpass"})
+	req := httptest.NewRequest(http.MethodPost, "/api/auth", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.authenticate(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"twoFactorRequired":true,"challengeToken":"challenge"}`, w.Body.String())
+}
+
+func TestHandler_Register(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	tests := []struct {
+		name         string
+		requestBody  entity.AuthRequest
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Success",
+			requestBody: entity.AuthRequest{Username: "newuser", Password: "strongpass"},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().Register(gomock.Any(), "newuser", "strongpass").
+					Return(entity.AuthResponse{Token: "jwt-token", RefreshToken: "refresh-token"}, nil)
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `{"token":"jwt-token","refreshToken":"refresh-token"}`,
+		},
+		{
+			name:         "Invalid Request Format",
+			requestBody:  entity.AuthRequest{Username: "newuser"},
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid request format"}`,
+		},
+		{
+			name:        "User Exists",
+			requestBody: entity.AuthRequest{Username: "existing", Password: "strongpass"},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().Register(gomock.Any(), "existing", "strongpass").
+					Return(entity.AuthResponse{}, entity.ErrUserExists)
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `{"errors":"user already exists"}`,
+		},
+		{
+			name:        "Weak Password",
+			requestBody: entity.AuthRequest{Username: "newuser", Password: "short"},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().Register(gomock.Any(), "newuser", "short").
+					Return(entity.AuthResponse{}, fmt.Errorf("%w: password must be at least 8 characters long", entity.ErrWeakPassword))
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"weak password: password must be at least 8 characters long"}`,
+		},
+		{
+			name:        "Invalid Username",
+			requestBody: entity.AuthRequest{Username: "a b", Password: "strongpass"},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().Register(gomock.Any(), "a b", "strongpass").
+					Return(entity.AuthResponse{}, fmt.Errorf("%w: bad characters", entity.ErrInvalidUsername))
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"invalid username: bad characters"}`,
+		},
+		{
+			name:        "Internal Error",
+			requestBody: entity.AuthRequest{Username: "newuser", Password: "strongpass"},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().Register(gomock.Any(), "newuser", "strongpass").
+					Return(entity.AuthResponse{}, errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/api/register", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			handler.register(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_Refresh(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	tests := []struct {
+		name         string
+		requestBody  entity.RefreshRequest
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Success",
+			requestBody: entity.RefreshRequest{RefreshToken: "old-refresh"},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().RefreshToken(gomock.Any(), "old-refresh").
+					Return(entity.AuthResponse{Token: "jwt-token", RefreshToken: "new-refresh"}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"token":"jwt-token","refreshToken":"new-refresh"}`,
+		},
+		{
+			name:         "Invalid Request Format",
+			requestBody:  entity.RefreshRequest{},
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid request format"}`,
+		},
+		{
+			name:        "Invalid Refresh Token",
+			requestBody: entity.RefreshRequest{RefreshToken: "unknown"},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().RefreshToken(gomock.Any(), "unknown").
+					Return(entity.AuthResponse{}, entity.ErrInvalidRefreshToken)
+			},
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"errors":"invalid or expired refresh token"}`,
+		},
+		{
+			name:        "Reused Refresh Token",
+			requestBody: entity.RefreshRequest{RefreshToken: "spent"},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().RefreshToken(gomock.Any(), "spent").
+					Return(entity.AuthResponse{}, entity.ErrRefreshTokenReused)
+			},
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"errors":"refresh token reuse detected"}`,
+		},
+		{
+			name:        "Internal Error",
+			requestBody: entity.RefreshRequest{RefreshToken: "old-refresh"},
+			mockBehavior: func() {
+				mockAuthService.EXPECT().RefreshToken(gomock.Any(), "old-refresh").
+					Return(entity.AuthResponse{}, errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			handler.refresh(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_Logout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	claims := entity.TokenClaims{UserID: 1, TokenID: "jti"}
+
+	tests := []struct {
+		name         string
+		claims       *entity.TokenClaims
+		requestBody  string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Success Without Refresh Token",
+			claims:      &claims,
+			requestBody: "",
+			mockBehavior: func() {
+				mockAuthService.EXPECT().Logout(gomock.Any(), claims, "").Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"successfully logged out"}`,
+		},
+		{
+			name:        "Success With Refresh Token",
+			claims:      &claims,
+			requestBody: `{"refreshToken":"refresh-token"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().Logout(gomock.Any(), claims, "refresh-token").Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"successfully logged out"}`,
+		},
+		{
+			name:         "Invalid Request Format",
+			claims:       &claims,
+			requestBody:  `{"refreshToken":`,
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid request format"}`,
+		},
+		{
+			name:        "Service Error",
+			claims:      &claims,
+			requestBody: "",
+			mockBehavior: func() {
+				mockAuthService.EXPECT().Logout(gomock.Any(), claims, "").Return(errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			if tt.claims != nil {
+				c.Set(claimsCtx, *tt.claims)
+			}
+
+			handler.logout(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_LogoutAll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mockAuthService.EXPECT().RevokeAllSessions(gomock.Any(), int64(1)).Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"all sessions were revoked"}`,
+		},
+		{
+			name: "Service Error",
+			mockBehavior: func() {
+				mockAuthService.EXPECT().RevokeAllSessions(gomock.Any(), int64(1)).Return(errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/auth/logout/all", nil)
+			c.Set(userCtx, int64(1))
+
+			handler.logoutAll(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_JWKS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	mockAuthService.EXPECT().GetJWKS().Return(entity.JWKS{Keys: []entity.JWK{
+		{KeyType: "OKP", KeyID: "ed-1", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519", X: "public-key"},
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+
+	handler.jwks(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"keys":[{"kty":"OKP","kid":"ed-1","use":"sig","alg":"EdDSA","crv":"Ed25519","x":"public-key"}]}`, w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("Cache-Control"))
+}
@@ -21,20 +21,20 @@ func (h *Handler) buyItem(c *gin.Context) {
 		return
 	}
 
-	err = h.services.Inventory.BuyItem(c.Request.Context(), userID, item)
+	receipt, err := h.services.Inventory.BuyItem(c.Request.Context(), userID, item)
 	if err != nil {
 		switch {
 		case errors.Is(err, entity.ErrItemNotFound):
 			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "item not found")
 		case errors.Is(err, entity.ErrInsufficientBalance):
 			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "insufficient balance")
+		case errors.Is(err, entity.ErrTransactionConflict):
+			entity.NewErrorResponse(c, h.log, http.StatusServiceUnavailable, "concurrent update, please retry")
 		default:
 			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, entity.StatusResponse{
-		Status: "item was successfully purchased",
-	})
+	c.JSON(http.StatusOK, receipt)
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -26,6 +27,16 @@ func TestHandler_BuyItem(t *testing.T) {
 	mockLog := logrus.New()
 	handler := &Handler{services: &service.Service{Inventory: mockInventoryService}, log: mockLog}
 
+	balance := int64(980)
+	receipt := entity.Receipt{
+		ID:        8,
+		Type:      entity.TransactionTypePurchase,
+		Amount:    20,
+		Item:      "cup",
+		Balance:   &balance,
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
 	tests := []struct {
 		name         string
 		userID       int64
@@ -41,10 +52,10 @@ func TestHandler_BuyItem(t *testing.T) {
 			mockBehavior: func() {
 				mockInventoryService.EXPECT().
 					BuyItem(gomock.Any(), int64(1), "cup").
-					Return(nil)
+					Return(receipt, nil)
 			},
 			wantStatus: http.StatusOK,
-			wantBody:   `{"status":"item was successfully purchased"}`,
+			wantBody:   `{"id":8,"type":"purchase","amount":20,"item":"cup","balance":980,"createdAt":"2025-01-01T12:00:00Z"}`,
 		},
 		{
 			name:         "Item parameter missing",
@@ -61,7 +72,7 @@ func TestHandler_BuyItem(t *testing.T) {
 			mockBehavior: func() {
 				mockInventoryService.EXPECT().
 					BuyItem(gomock.Any(), int64(1), "UnknownItem").
-					Return(entity.ErrItemNotFound)
+					Return(entity.Receipt{}, entity.ErrItemNotFound)
 			},
 			wantStatus: http.StatusBadRequest,
 			wantBody:   `{"errors":"item not found"}`,
@@ -73,11 +84,23 @@ func TestHandler_BuyItem(t *testing.T) {
 			mockBehavior: func() {
 				mockInventoryService.EXPECT().
 					BuyItem(gomock.Any(), int64(1), "cup").
-					Return(entity.ErrInsufficientBalance)
+					Return(entity.Receipt{}, entity.ErrInsufficientBalance)
 			},
 			wantStatus: http.StatusBadRequest,
 			wantBody:   `{"errors":"insufficient balance"}`,
 		},
+		{
+			name:      "Transaction conflict",
+			userID:    1,
+			itemParam: "cup",
+			mockBehavior: func() {
+				mockInventoryService.EXPECT().
+					BuyItem(gomock.Any(), int64(1), "cup").
+					Return(entity.Receipt{}, entity.ErrTransactionConflict)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"errors":"concurrent update, please retry"}`,
+		},
 		{
 			name:      "Transaction failure",
 			userID:    1,
@@ -85,7 +108,7 @@ func TestHandler_BuyItem(t *testing.T) {
 			mockBehavior: func() {
 				mockInventoryService.EXPECT().
 					BuyItem(gomock.Any(), int64(1), "cup").
-					Return(errors.New("internal server error"))
+					Return(entity.Receipt{}, errors.New("internal server error"))
 			},
 			wantStatus: http.StatusInternalServerError,
 			wantBody:   `{"errors":"internal server error"}`,
@@ -1,40 +1,103 @@
 package handler
 
 import (
+	"expvar"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/infrastructure/config"
 	"github.com/senyabanana/shop-service/internal/service"
 )
 
 type Handler struct {
-	services *service.Service
-	log      *logrus.Logger
+	services       *service.Service
+	autoRegister   bool
+	trustedProxies []string
+	log            *logrus.Logger
 }
 
-func NewHandler(services *service.Service, log *logrus.Logger) *Handler {
+func NewHandler(services *service.Service, cfg *config.Config, log *logrus.Logger) *Handler {
 	return &Handler{
-		services: services,
-		log:      log,
+		services:       services,
+		autoRegister:   cfg.AutoRegister,
+		trustedProxies: parseTrustedProxies(cfg.TrustedProxies),
+		log:            log,
 	}
 }
+
+// InitRoutes доверяет X-Forwarded-For только прокси из TRUSTED_PROXIES; по умолчанию клиентом
+// считается адрес TCP-соединения, иначе лимит входов по IP можно обойти или направить на чужой адрес.
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.New()
+	if err := router.SetTrustedProxies(h.trustedProxies); err != nil {
+		h.log.Fatalf("invalid TRUSTED_PROXIES: %s", err.Error())
+	}
 
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 
+	router.GET("/.well-known/jwks.json", h.jwks)
+	router.GET("/debug/vars", h.userIdentity, h.requireRole(entity.RoleAdmin), gin.WrapH(expvar.Handler()))
+
 	api := router.Group("/api")
 	{
+		api.POST("/register", h.register)
 		api.POST("/auth", h.authenticate)
+		api.POST("/auth/2fa", h.completeTwoFactorLogin)
+		api.POST("/auth/refresh", h.refresh)
 
 		protected := api.Group("/", h.userIdentity)
 		{
-			protected.GET("/info", h.getInfo)
-			protected.POST("/sendCoin", h.sendCoin)
-			protected.GET("/buy/:item", h.buyItem)
+			protected.GET("/info", h.requireScope(entity.ScopeInfo), h.getInfo)
+			protected.GET("/history", h.requireScope(entity.ScopeInfo), h.getHistory)
+			protected.POST("/sendCoin", h.requireScope(entity.ScopeSendCoin), h.idempotent(h.sendCoin))
+			protected.GET("/buy/:item", h.requireScope(entity.ScopeBuy), h.idempotent(h.buyItem))
+			protected.GET("/transactions/:id", h.requireScope(entity.ScopeInfo), h.getTransaction)
+
+			session := protected.Group("/", h.requireSession)
+			{
+				session.POST("/auth/logout", h.logout)
+				session.POST("/auth/logout/all", h.logoutAll)
+				session.POST("/password", h.changePassword)
+
+				session.POST("/2fa/enroll", h.enrollTOTP)
+				session.POST("/2fa/confirm", h.confirmTOTP)
+				session.POST("/2fa/disable", h.disableTOTP)
+
+				session.POST("/keys", h.createAPIKey)
+				session.GET("/keys", h.listAPIKeys)
+				session.DELETE("/keys/:id", h.revokeAPIKey)
+
+				admin := session.Group("/admin", h.requireRole(entity.RoleAdmin))
+				{
+					admin.PUT("/users/:username/role", h.setUserRole)
+					admin.POST("/users/:username/unlock", h.unlockUser)
+					admin.POST("/transactions/:id/reverse", h.reverseTransfer)
+					admin.POST("/transactions/:id/refund", h.refundPurchase)
+					admin.POST("/coins/mint", h.idempotent(h.mintCoins))
+					admin.POST("/coins/burn", h.idempotent(h.burnCoins))
+					admin.POST("/allowances", h.createAllowance)
+					admin.GET("/allowances", h.listAllowances)
+					admin.DELETE("/allowances/:id", h.disableAllowance)
+				}
+			}
 		}
 	}
 
 	return router
 }
+
+// parseTrustedProxies разбирает список адресов и подсетей прокси через запятую.
+func parseTrustedProxies(value string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(value, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/service"
+	mocks "github.com/senyabanana/shop-service/internal/service/mocks"
+)
+
+func TestHandler_DebugVars(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	handler := &Handler{services: &service.Service{Authorization: mockAuthService}, log: logrus.New()}
+	router := handler.InitRoutes()
+
+	tests := []struct {
+		name         string
+		authHeader   string
+		mockBehavior func()
+		wantStatus   int
+	}{
+		{
+			name:         "Anonymous",
+			mockBehavior: func() {},
+			wantStatus:   http.StatusUnauthorized,
+		},
+		{
+			name:       "Regular User",
+			authHeader: "Bearer user_token",
+			mockBehavior: func() {
+				mockAuthService.EXPECT().ParseToken(gomock.Any(), "user_token").
+					Return(entity.TokenClaims{UserID: 1, Role: entity.RoleUser}, nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Admin",
+			authHeader: "Bearer admin_token",
+			mockBehavior: func() {
+				mockAuthService.EXPECT().ParseToken(gomock.Any(), "admin_token").
+					Return(entity.TokenClaims{UserID: 2, Role: entity.RoleAdmin}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+			if tt.authHeader != "" {
+				req.Header.Set(authorizationHeader, tt.authHeader)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestHandler_ClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies string
+		wantIP         string
+	}{
+		{
+			name:   "Forwarded Header Ignored By Default",
+			wantIP: "192.0.2.1",
+		},
+		{
+			name:           "Forwarded Header From Trusted Proxy",
+			trustedProxies: "192.0.2.0/24, 10.0.0.1",
+			wantIP:         "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &Handler{trustedProxies: parseTrustedProxies(tt.trustedProxies), log: logrus.New()}
+			router := handler.InitRoutes()
+
+			var clientIP string
+			router.GET("/ip", func(c *gin.Context) {
+				clientIP = c.ClientIP()
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.wantIP, clientIP)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func (h *Handler) getHistory(c *gin.Context) {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	var filter entity.HistoryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	page, err := h.services.Transaction.GetHistory(c.Request.Context(), userID, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidCursor), errors.Is(err, entity.ErrInvalidHistoryFilter):
+			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, err.Error())
+		default:
+			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/service"
+	mocks "github.com/senyabanana/shop-service/internal/service/mocks"
+)
+
+func TestHandler_GetHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransactionService := mocks.NewMockTransaction(ctrl)
+	mockLog := logrus.New()
+	handler := &Handler{services: &service.Service{Transaction: mockTransactionService}, log: mockLog}
+
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	minAmount := int64(10)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		query        string
+		mockBehavior func()
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name:  "Success",
+			query: "?direction=sent&counterparty=bob&minAmount=10&from=2025-01-01T00:00:00Z&limit=1",
+			mockBehavior: func() {
+				mockTransactionService.EXPECT().GetHistory(gomock.Any(), int64(1), entity.HistoryFilter{
+					Direction:    entity.DirectionSent,
+					Counterparty: "bob",
+					MinAmount:    &minAmount,
+					From:         &from,
+					Limit:        1,
+				}).Return(entity.HistoryPage{
+					Items: []entity.TransactionDetail{
+						{ID: 7, Direction: entity.DirectionSent, ToUser: "bob", Amount: 50, CreatedAt: createdAt},
+					},
+					NextCursor: "next",
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"items":[{"id":7,"direction":"sent","toUser":"bob","amount":50,"createdAt":"2025-01-01T12:00:00Z"}],` +
+				`"nextCursor":"next"}`,
+		},
+		{
+			name:         "Invalid Direction",
+			query:        "?direction=sideways",
+			mockBehavior: func() {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid query parameters"}`,
+		},
+		{
+			name:         "Invalid Date",
+			query:        "?from=yesterday",
+			mockBehavior: func() {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid query parameters"}`,
+		},
+		{
+			name:  "Invalid Cursor",
+			query: "?cursor=broken",
+			mockBehavior: func() {
+				mockTransactionService.EXPECT().GetHistory(gomock.Any(), int64(1), entity.HistoryFilter{Cursor: "broken"}).
+					Return(entity.HistoryPage{}, entity.ErrInvalidCursor)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":"invalid cursor"}`,
+		},
+		{
+			name:  "Invalid Filter",
+			query: "?minAmount=100&maxAmount=10",
+			mockBehavior: func() {
+				mockTransactionService.EXPECT().GetHistory(gomock.Any(), int64(1), gomock.Any()).
+					Return(entity.HistoryPage{}, fmt.Errorf("%w: minAmount is greater than maxAmount", entity.ErrInvalidHistoryFilter))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":"invalid history filter: minAmount is greater than maxAmount"}`,
+		},
+		{
+			name:  "Internal Error",
+			query: "",
+			mockBehavior: func() {
+				mockTransactionService.EXPECT().GetHistory(gomock.Any(), int64(1), entity.HistoryFilter{}).
+					Return(entity.HistoryPage{}, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set(userCtx, int64(1))
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/history"+tt.query, nil)
+
+			handler.getHistory(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
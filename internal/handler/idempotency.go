@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotentResponseContent = "application/json; charset=utf-8"
+)
+
+// bufferedResponseWriter задерживает ответ обработчика, пока не зафиксирована транзакция с его сохранением.
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() {}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedResponseWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedResponseWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// idempotent делает запрос с заголовком Idempotency-Key однократным: next выполняется в транзакции вместе
+// с сохранением ответа, а повтор получает сохраненный ответ. При конфликте транзакций next вызывается заново
+// с тем же телом запроса. Запросы без заголовка обрабатываются как обычно. Должен подключаться после userIdentity.
+func (h *Handler) idempotent(next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			next(c)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid idempotency key")
+			return
+		}
+
+		userID, err := h.getUserID(c)
+		if err != nil {
+			entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+			return
+		}
+
+		original := c.Writer
+		request := c.Request
+		resp, err := h.services.Idempotency.Execute(request.Context(), userID, key, requestHash(request, body),
+			func(ctx context.Context) entity.IdempotentResponse {
+				writer := &bufferedResponseWriter{ResponseWriter: original, status: http.StatusOK}
+				c.Writer = writer
+				c.Request = request.WithContext(ctx)
+				c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+				next(c)
+
+				return entity.IdempotentResponse{StatusCode: writer.status, Body: writer.body.Bytes()}
+			})
+		c.Writer = original
+		c.Request = request
+
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrIdempotencyKeyConflict):
+				entity.NewErrorResponse(c, h.log, http.StatusUnprocessableEntity, err.Error())
+			case errors.Is(err, entity.ErrTransactionConflict):
+				entity.NewErrorResponse(c, h.log, http.StatusServiceUnavailable, "concurrent update, please retry")
+			default:
+				entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+			}
+			return
+		}
+
+		if resp.Replayed {
+			c.Header(idempotentReplayedHeader, "true")
+		}
+		c.Data(resp.StatusCode, idempotentResponseContent, resp.Body)
+	}
+}
+
+// requestHash отпечаток запроса: тот же ключ с другим методом, путем или телом считается конфликтом.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/service"
+	mocks "github.com/senyabanana/shop-service/internal/service/mocks"
+)
+
+func TestHandler_Idempotent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIdempotencyService := mocks.NewMockIdempotency(ctrl)
+	mockLog := logrus.New()
+	handler := &Handler{services: &service.Service{Idempotency: mockIdempotencyService}, log: mockLog}
+
+	const body = `{"toUser":"recipient","amount":50}`
+
+	executeOperation := func(_ context.Context, _ int64, _, _ string, operation func(ctx context.Context) entity.IdempotentResponse) (entity.IdempotentResponse, error) {
+		return operation(context.Background()), nil
+	}
+
+	tests := []struct {
+		name         string
+		key          string
+		mockBehavior func()
+		wantStatus   int
+		wantBody     string
+		wantReplayed string
+		wantCalls    int
+	}{
+		{
+			name:         "No Key",
+			key:          "",
+			mockBehavior: func() {},
+			wantStatus:   http.StatusOK,
+			wantBody:     `{"status":"ok"}`,
+			wantCalls:    1,
+		},
+		{
+			name: "First Request",
+			key:  "key-1",
+			mockBehavior: func() {
+				mockIdempotencyService.EXPECT().Execute(gomock.Any(), int64(1), "key-1", requestHash(httptest.NewRequest(http.MethodPost, "/sendCoin", nil), []byte(body)), gomock.Any()).
+					DoAndReturn(executeOperation)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ok"}`,
+			wantCalls:  1,
+		},
+		{
+			name: "Replay",
+			key:  "key-1",
+			mockBehavior: func() {
+				mockIdempotencyService.EXPECT().Execute(gomock.Any(), int64(1), "key-1", gomock.Any(), gomock.Any()).
+					Return(entity.IdempotentResponse{StatusCode: http.StatusOK, Body: []byte(`{"status":"ok"}`), Replayed: true}, nil)
+			},
+			wantStatus:   http.StatusOK,
+			wantBody:     `{"status":"ok"}`,
+			wantReplayed: "true",
+			wantCalls:    0,
+		},
+		{
+			name: "Conflicting Payload",
+			key:  "key-1",
+			mockBehavior: func() {
+				mockIdempotencyService.EXPECT().Execute(gomock.Any(), int64(1), "key-1", gomock.Any(), gomock.Any()).
+					Return(entity.IdempotentResponse{}, entity.ErrIdempotencyKeyConflict)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"errors":"idempotency key was already used with a different request"}`,
+			wantCalls:  0,
+		},
+		{
+			name: "Operation Retried After Conflict",
+			key:  "key-1",
+			mockBehavior: func() {
+				mockIdempotencyService.EXPECT().Execute(gomock.Any(), int64(1), "key-1", gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, userID int64, key, hash string, operation func(ctx context.Context) entity.IdempotentResponse) (entity.IdempotentResponse, error) {
+						operation(ctx)
+						return executeOperation(ctx, userID, key, hash, operation)
+					})
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ok"}`,
+			wantCalls:  2,
+		},
+		{
+			name: "Conflict Persisted",
+			key:  "key-1",
+			mockBehavior: func() {
+				mockIdempotencyService.EXPECT().Execute(gomock.Any(), int64(1), "key-1", gomock.Any(), gomock.Any()).
+					Return(entity.IdempotentResponse{}, entity.ErrTransactionConflict)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"errors":"concurrent update, please retry"}`,
+			wantCalls:  0,
+		},
+		{
+			name:         "Key Too Long",
+			key:          string(bytes.Repeat([]byte("k"), maxIdempotencyKeyLength+1)),
+			mockBehavior: func() {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid idempotency key"}`,
+			wantCalls:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			w := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(w)
+
+			calls := 0
+			router.POST("/sendCoin", func(c *gin.Context) {
+				c.Set(userCtx, int64(1))
+			}, handler.idempotent(func(c *gin.Context) {
+				calls++
+				var input entity.SendCoinRequest
+				if err := c.ShouldBindJSON(&input); err != nil {
+					c.JSON(http.StatusBadRequest, entity.ErrorResponse{Message: "invalid request format"})
+					return
+				}
+				c.JSON(http.StatusOK, entity.StatusResponse{Status: "ok"})
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/sendCoin", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.key != "" {
+				req.Header.Set(idempotencyKeyHeader, tt.key)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+			assert.Equal(t, tt.wantReplayed, w.Header().Get(idempotentReplayedHeader))
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
@@ -8,12 +8,23 @@ import (
 	"github.com/senyabanana/shop-service/internal/entity"
 )
 
+const groupByCounterparty = "counterparty"
+
 func (h *Handler) getInfo(c *gin.Context) {
 	userID, err := h.getUserID(c)
 	if err != nil {
 		return
 	}
 
+	if groupBy := c.Query("groupBy"); groupBy != "" {
+		if groupBy != groupByCounterparty {
+			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid groupBy parameter")
+			return
+		}
+		h.getInfoSummary(c, userID)
+		return
+	}
+
 	info, err := h.services.Transaction.GetUserInfo(c.Request.Context(), userID)
 	if err != nil {
 		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, err.Error())
@@ -22,3 +33,13 @@ func (h *Handler) getInfo(c *gin.Context) {
 
 	c.JSON(http.StatusOK, info)
 }
+
+func (h *Handler) getInfoSummary(c *gin.Context, userID int64) {
+	info, err := h.services.Transaction.GetUserInfoSummary(c.Request.Context(), userID)
+	if err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -30,6 +31,7 @@ func TestHandler_GetInfo(t *testing.T) {
 	tests := []struct {
 		name         string
 		userID       int64
+		query        string
 		mockBehavior func()
 		wantCode     int
 		wantBody     string
@@ -39,16 +41,23 @@ func TestHandler_GetInfo(t *testing.T) {
 			userID: 1,
 			mockBehavior: func() {
 				mockTransactionService.EXPECT().GetUserInfo(gomock.Any(), int64(1)).Return(entity.InfoResponse{
-					Coins:     500,
+					Coins: 500,
+					ExpiringCoins: []entity.CoinExpiration{
+						{Amount: 300, ExpiresAt: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+					},
 					Inventory: []entity.InventoryItem{{Type: "cup", Quantity: 1}},
 					CoinHistory: entity.CoinHistory{
 						Received: []entity.TransactionDetail{},
 						Sent:     []entity.TransactionDetail{},
+						Purchases: []entity.PurchaseDetail{
+							{ID: 9, Item: "cup", UnitPrice: 20, Quantity: 1, Amount: 20, CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+						},
 					},
 				}, nil)
 			},
 			wantCode: http.StatusOK,
-			wantBody: `{"coins":500,"inventory":[{"type":"cup","quantity":1}],"coinHistory":{"received":[],"sent":[]}}`,
+			wantBody: `{"coins":500,"expiringCoins":[{"amount":300,"expiresAt":"2025-04-01T00:00:00Z"}],"inventory":[{"type":"cup","quantity":1}],"coinHistory":{"received":[],"sent":[],` +
+				`"purchases":[{"id":9,"item":"cup","unitPrice":20,"quantity":1,"amount":20,"createdAt":"2025-01-01T12:00:00Z"}]}}`,
 		},
 		{
 			name:   "Error fetching user info",
@@ -59,13 +68,49 @@ func TestHandler_GetInfo(t *testing.T) {
 			wantCode: http.StatusInternalServerError,
 			wantBody: `{"errors":"db error"}`,
 		},
+		{
+			name:   "Grouped by counterparty",
+			userID: 1,
+			query:  "?groupBy=counterparty",
+			mockBehavior: func() {
+				mockTransactionService.EXPECT().GetUserInfoSummary(gomock.Any(), int64(1)).Return(entity.InfoSummaryResponse{
+					Coins:         500,
+					ExpiringCoins: []entity.CoinExpiration{},
+					Inventory:     []entity.InventoryItem{},
+					CoinHistory: entity.CoinHistorySummary{
+						Received: []entity.CounterpartyTotal{{FromUser: "alice", Amount: 150, Count: 3}},
+						Sent:     []entity.CounterpartyTotal{},
+					},
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"coins":500,"expiringCoins":[],"inventory":[],"coinHistory":{"received":[{"fromUser":"alice","amount":150,"count":3}],"sent":[]}}`,
+		},
+		{
+			name:         "Unknown groupBy",
+			userID:       1,
+			query:        "?groupBy=day",
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid groupBy parameter"}`,
+		},
+		{
+			name:   "Error fetching summary",
+			userID: 1,
+			query:  "?groupBy=counterparty",
+			mockBehavior: func() {
+				mockTransactionService.EXPECT().GetUserInfoSummary(gomock.Any(), int64(1)).Return(entity.InfoSummaryResponse{}, errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockBehavior()
 
-			req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+			req := httptest.NewRequest(http.MethodGet, "/api/info"+tt.query, nil)
 			w := httptest.NewRecorder()
 
 			c, _ := gin.CreateTestContext(w)
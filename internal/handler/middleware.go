@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -11,29 +12,114 @@ import (
 
 const (
 	authorizationHeader = "Authorization"
+	apiKeyHeader        = "X-Api-Key"
 	userCtx             = "userID"
+	claimsCtx           = "tokenClaims"
+	apiKeyCtx           = "apiKey"
 )
 
+// userIdentity аутентифицирует запрос по Bearer-токену или, если передан заголовок X-Api-Key, по API-ключу.
 func (h *Handler) userIdentity(c *gin.Context) {
+	if rawKey := c.GetHeader(apiKeyHeader); rawKey != "" {
+		h.apiKeyIdentity(c, rawKey)
+		return
+	}
+
 	header := c.GetHeader(authorizationHeader)
 	if header == "" {
 		entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, "empty auth header")
+		c.Abort()
 		return
 	}
 
 	headerParts := strings.Split(header, " ")
 	if len(headerParts) != 2 || headerParts[0] != "Bearer" {
 		entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, "invalid auth header format")
+		c.Abort()
 		return
 	}
 
-	userID, err := h.services.Authorization.ParseToken(headerParts[1])
+	claims, err := h.services.Authorization.ParseToken(c.Request.Context(), headerParts[1])
 	if err != nil {
-		entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, "invalid or expired token")
+		switch {
+		case errors.Is(err, entity.ErrTokenRevoked):
+			entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, "token has been revoked")
+		case errors.Is(err, entity.ErrInvalidToken), errors.Is(err, entity.ErrInvalidTokenClaimsType):
+			entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, "invalid or expired token")
+		default:
+			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		}
+		c.Abort()
 		return
 	}
 
-	c.Set(userCtx, userID)
+	c.Set(userCtx, claims.UserID)
+	c.Set(claimsCtx, claims)
+}
+
+func (h *Handler) apiKeyIdentity(c *gin.Context, rawKey string) {
+	key, err := h.services.APIKey.AuthenticateAPIKey(c.Request.Context(), rawKey)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidAPIKey) {
+			entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, "invalid or expired api key")
+		} else {
+			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		}
+		c.Abort()
+		return
+	}
+
+	c.Set(userCtx, key.UserID)
+	c.Set(apiKeyCtx, key)
+}
+
+// requireScope ограничивает запросы по API-ключу ключами с нужной областью действия.
+// Запросы с Bearer-токеном пропускаются без проверки.
+func (h *Handler) requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get(apiKeyCtx)
+		if !ok {
+			return
+		}
+
+		key, ok := value.(entity.APIKey)
+		if !ok || !key.HasScope(scope) {
+			h.log.Warnf("requireScope: api key %s has no scope %s", key.Prefix, scope)
+			entity.NewErrorResponse(c, h.log, http.StatusForbidden, "api key is missing required scope")
+			c.Abort()
+		}
+	}
+}
+
+// requireSession закрывает эндпоинт для API-ключей: управлять аккаунтом можно только из пользовательской сессии.
+func (h *Handler) requireSession(c *gin.Context) {
+	if _, ok := c.Get(apiKeyCtx); ok {
+		entity.NewErrorResponse(c, h.log, http.StatusForbidden, "api keys are not allowed for this endpoint")
+		c.Abort()
+	}
+}
+
+// requireRole пропускает запрос, только если роль из токена входит в список разрешенных.
+// Должен подключаться после userIdentity.
+func (h *Handler) requireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := h.getTokenClaims(c)
+		if err != nil {
+			entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, "unauthorized")
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if claims.Role == role {
+				return
+			}
+		}
+
+		h.log.Warnf("requireRole: user %d with role %s denied access to %s", claims.UserID, claims.Role, c.FullPath())
+		entity.NewErrorResponse(c, h.log, http.StatusForbidden, "insufficient permissions")
+		c.Abort()
+	}
 }
 
 func (h *Handler) getUserID(c *gin.Context) (int64, error) {
@@ -51,3 +137,19 @@ func (h *Handler) getUserID(c *gin.Context) (int64, error) {
 
 	return idInt, nil
 }
+
+func (h *Handler) getTokenClaims(c *gin.Context) (entity.TokenClaims, error) {
+	value, ok := c.Get(claimsCtx)
+	if !ok {
+		h.log.Warn("getTokenClaims: token claims not found in context")
+		return entity.TokenClaims{}, entity.ErrInvalidToken
+	}
+
+	claims, ok := value.(entity.TokenClaims)
+	if !ok {
+		h.log.Warn("getTokenClaims: token claims are of invalid type")
+		return entity.TokenClaims{}, entity.ErrInvalidTokenClaimsType
+	}
+
+	return claims, nil
+}
@@ -38,7 +38,7 @@ func TestHandler_UserIdentity(t *testing.T) {
 			name:       "Success",
 			authHeader: "Bearer valid_token",
 			mockBehavior: func() {
-				mockAuthService.EXPECT().ParseToken("valid_token").Return(int64(1), nil)
+				mockAuthService.EXPECT().ParseToken(gomock.Any(), "valid_token").Return(entity.TokenClaims{UserID: 1, TokenID: "jti"}, nil)
 			},
 			wantStatus: http.StatusOK,
 			wantBody:   ``,
@@ -61,11 +61,29 @@ func TestHandler_UserIdentity(t *testing.T) {
 			name:       "Invalid token",
 			authHeader: "Bearer invalid_token",
 			mockBehavior: func() {
-				mockAuthService.EXPECT().ParseToken("invalid_token").Return(int64(0), errors.New("invalid or expired token"))
+				mockAuthService.EXPECT().ParseToken(gomock.Any(), "invalid_token").Return(entity.TokenClaims{}, entity.ErrInvalidToken)
 			},
 			wantStatus: http.StatusUnauthorized,
 			wantBody:   `{"errors":"invalid or expired token"}`,
 		},
+		{
+			name:       "Revoked token",
+			authHeader: "Bearer revoked_token",
+			mockBehavior: func() {
+				mockAuthService.EXPECT().ParseToken(gomock.Any(), "revoked_token").Return(entity.TokenClaims{}, entity.ErrTokenRevoked)
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"errors":"token has been revoked"}`,
+		},
+		{
+			name:       "Revocation check failure",
+			authHeader: "Bearer valid_token",
+			mockBehavior: func() {
+				mockAuthService.EXPECT().ParseToken(gomock.Any(), "valid_token").Return(entity.TokenClaims{}, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"errors":"internal server error"}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -88,6 +106,217 @@ func TestHandler_UserIdentity(t *testing.T) {
 	}
 }
 
+func TestHandler_APIKeyIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyService := mocks.NewMockAPIKey(ctrl)
+	mockLog := logrus.New()
+
+	handler := &Handler{services: &service.Service{APIKey: mockAPIKeyService}, log: mockLog}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mockAPIKeyService.EXPECT().AuthenticateAPIKey(gomock.Any(), "shop_key").
+					Return(entity.APIKey{ID: 7, UserID: 1, Scopes: []string{entity.ScopeInfo}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Invalid Key",
+			mockBehavior: func() {
+				mockAPIKeyService.EXPECT().AuthenticateAPIKey(gomock.Any(), "shop_key").
+					Return(entity.APIKey{}, entity.ErrInvalidAPIKey)
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"errors":"invalid or expired api key"}`,
+		},
+		{
+			name: "Lookup Failure",
+			mockBehavior: func() {
+				mockAPIKeyService.EXPECT().AuthenticateAPIKey(gomock.Any(), "shop_key").
+					Return(entity.APIKey{}, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set(apiKeyHeader, "shop_key")
+
+			handler.userIdentity(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+				return
+			}
+
+			userID, err := handler.getUserID(c)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), userID)
+		})
+	}
+}
+
+func TestHandler_RequireScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockLog := logrus.New()
+	handler := &Handler{log: mockLog}
+
+	tests := []struct {
+		name       string
+		apiKey     *entity.APIKey
+		wantStatus int
+		wantBody   string
+		wantNext   bool
+	}{
+		{
+			name:       "Bearer Token Passes",
+			apiKey:     nil,
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+		},
+		{
+			name:       "Key With Scope",
+			apiKey:     &entity.APIKey{Prefix: "0a1b2c3d", Scopes: []string{entity.ScopeInfo}},
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+		},
+		{
+			name:       "Key Without Scope",
+			apiKey:     &entity.APIKey{Prefix: "0a1b2c3d", Scopes: []string{entity.ScopeBuy}},
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"errors":"api key is missing required scope"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(w)
+
+			nextCalled := false
+			router.GET("/info", func(c *gin.Context) {
+				if tt.apiKey != nil {
+					c.Set(apiKeyCtx, *tt.apiKey)
+				}
+			}, handler.requireScope(entity.ScopeInfo), func(c *gin.Context) {
+				nextCalled = true
+				c.Status(http.StatusOK)
+			})
+
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/info", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantNext, nextCalled)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_RequireSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockLog := logrus.New()
+	handler := &Handler{log: mockLog}
+
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+
+	nextCalled := false
+	router.POST("/password", func(c *gin.Context) {
+		c.Set(apiKeyCtx, entity.APIKey{Scopes: entity.APIKeyScopes})
+	}, handler.requireSession, func(c *gin.Context) {
+		nextCalled = true
+	})
+
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/password", nil))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.False(t, nextCalled)
+	assert.JSONEq(t, `{"errors":"api keys are not allowed for this endpoint"}`, w.Body.String())
+}
+
+func TestHandler_RequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockLog := logrus.New()
+	handler := &Handler{log: mockLog}
+
+	tests := []struct {
+		name       string
+		claims     *entity.TokenClaims
+		wantStatus int
+		wantBody   string
+		wantNext   bool
+	}{
+		{
+			name:       "Admin Allowed",
+			claims:     &entity.TokenClaims{UserID: 1, Role: entity.RoleAdmin},
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+		},
+		{
+			name:       "User Forbidden",
+			claims:     &entity.TokenClaims{UserID: 2, Role: entity.RoleUser},
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"errors":"insufficient permissions"}`,
+		},
+		{
+			name:       "No Claims",
+			claims:     nil,
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"errors":"unauthorized"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(w)
+
+			nextCalled := false
+			router.GET("/admin", func(c *gin.Context) {
+				if tt.claims != nil {
+					c.Set(claimsCtx, *tt.claims)
+				}
+			}, handler.requireRole(entity.RoleAdmin), func(c *gin.Context) {
+				nextCalled = true
+				c.Status(http.StatusOK)
+			})
+
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantNext, nextCalled)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestHandler_GetUserID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func (h *Handler) changePassword(c *gin.Context) {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	var input entity.PasswordChangeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	tokens, err := h.services.Authorization.ChangePassword(c.Request.Context(), userID, input.CurrentPassword, input.NewPassword)
+	if err != nil {
+		var lockErr *entity.LoginLockedError
+		switch {
+		case errors.As(err, &lockErr):
+			h.loginLocked(c, lockErr)
+		case errors.Is(err, entity.ErrIncorrectPassword):
+			entity.NewErrorResponse(c, h.log, http.StatusForbidden, "current password is incorrect")
+		case errors.Is(err, entity.ErrWeakPassword):
+			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, err.Error())
+		default:
+			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/service"
+	mocks "github.com/senyabanana/shop-service/internal/service/mocks"
+)
+
+func TestHandler_ChangePassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Success",
+			requestBody: `{"currentPassword":"oldpass123","newPassword":"newpass123"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().ChangePassword(gomock.Any(), int64(1), "oldpass123", "newpass123").
+					Return(entity.AuthResponse{Token: "jwt-token", RefreshToken: "refresh-token"}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"token":"jwt-token","refreshToken":"refresh-token"}`,
+		},
+		{
+			name:         "Invalid Request Format",
+			requestBody:  `{"currentPassword":"oldpass123"}`,
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid request format"}`,
+		},
+		{
+			name:        "Incorrect Current Password",
+			requestBody: `{"currentPassword":"wrong","newPassword":"newpass123"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().ChangePassword(gomock.Any(), int64(1), "wrong", "newpass123").
+					Return(entity.AuthResponse{}, entity.ErrIncorrectPassword)
+			},
+			wantCode: http.StatusForbidden,
+			wantBody: `{"errors":"current password is incorrect"}`,
+		},
+		{
+			name:        "Weak New Password",
+			requestBody: `{"currentPassword":"oldpass123","newPassword":"short"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().ChangePassword(gomock.Any(), int64(1), "oldpass123", "short").
+					Return(entity.AuthResponse{}, fmt.Errorf("%w: password must be at least 8 characters long", entity.ErrWeakPassword))
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"weak password: password must be at least 8 characters long"}`,
+		},
+		{
+			name:        "Internal Error",
+			requestBody: `{"currentPassword":"oldpass123","newPassword":"newpass123"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().ChangePassword(gomock.Any(), int64(1), "oldpass123", "newpass123").
+					Return(entity.AuthResponse{}, errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/password", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Set(userCtx, int64(1))
+
+			handler.changePassword(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
@@ -21,7 +21,7 @@ func (h *Handler) sendCoin(c *gin.Context) {
 		return
 	}
 
-	err = h.services.Transaction.SendCoin(c.Request.Context(), userID, input.ToUser, input.Amount)
+	receipt, err := h.services.Transaction.SendCoin(c.Request.Context(), userID, input.ToUser, input.Amount)
 	if err != nil {
 		switch {
 		case errors.Is(err, entity.ErrRecipientNotFound):
@@ -30,13 +30,13 @@ func (h *Handler) sendCoin(c *gin.Context) {
 			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "cannot send coins to yourself")
 		case errors.Is(err, entity.ErrInsufficientBalance):
 			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "insufficient balance")
+		case errors.Is(err, entity.ErrTransactionConflict):
+			entity.NewErrorResponse(c, h.log, http.StatusServiceUnavailable, "concurrent update, please retry")
 		default:
 			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, entity.StatusResponse{
-		Status: "coins were successfully sent to the user",
-	})
+	c.JSON(http.StatusOK, receipt)
 }
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -28,6 +30,16 @@ func TestHandler_SendCoin(t *testing.T) {
 	mockLog := logrus.New()
 	handler := &Handler{services: &service.Service{Transaction: mockTransactionService}, log: mockLog}
 
+	balance := int64(950)
+	receipt := entity.Receipt{
+		ID:        7,
+		Type:      entity.TransactionTypeTransfer,
+		Amount:    50,
+		ToUser:    "recipient",
+		Balance:   &balance,
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
 	tests := []struct {
 		name         string
 		userID       int64
@@ -43,10 +55,10 @@ func TestHandler_SendCoin(t *testing.T) {
 			mockBehavior: func() {
 				mockTransactionService.EXPECT().
 					SendCoin(gomock.Any(), int64(1), "recipient", int64(50)).
-					Return(nil)
+					Return(receipt, nil)
 			},
 			wantStatus: http.StatusOK,
-			wantBody:   `{"status":"coins were successfully sent to the user"}`,
+			wantBody:   `{"id":7,"type":"transfer","amount":50,"toUser":"recipient","balance":950,"createdAt":"2025-01-01T12:00:00Z"}`,
 		},
 		{
 			name:         "Invalid request format",
@@ -63,7 +75,7 @@ func TestHandler_SendCoin(t *testing.T) {
 			mockBehavior: func() {
 				mockTransactionService.EXPECT().
 					SendCoin(gomock.Any(), int64(1), "unknown", int64(50)).
-					Return(entity.ErrRecipientNotFound)
+					Return(entity.Receipt{}, entity.ErrRecipientNotFound)
 			},
 			wantStatus: http.StatusBadRequest,
 			wantBody:   `{"errors":"recipient not found"}`,
@@ -75,11 +87,23 @@ func TestHandler_SendCoin(t *testing.T) {
 			mockBehavior: func() {
 				mockTransactionService.EXPECT().
 					SendCoin(gomock.Any(), int64(1), "recipient", int64(1000)).
-					Return(entity.ErrInsufficientBalance)
+					Return(entity.Receipt{}, entity.ErrInsufficientBalance)
 			},
 			wantStatus: http.StatusBadRequest,
 			wantBody:   `{"errors":"insufficient balance"}`,
 		},
+		{
+			name:        "Transaction conflict",
+			userID:      1,
+			requestBody: entity.SendCoinRequest{ToUser: "recipient", Amount: 50},
+			mockBehavior: func() {
+				mockTransactionService.EXPECT().
+					SendCoin(gomock.Any(), int64(1), "recipient", int64(50)).
+					Return(entity.Receipt{}, fmt.Errorf("%w: deadlock detected", entity.ErrTransactionConflict))
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"errors":"concurrent update, please retry"}`,
+		},
 		{
 			name:        "Cannot send to self",
 			userID:      1,
@@ -87,7 +111,7 @@ func TestHandler_SendCoin(t *testing.T) {
 			mockBehavior: func() {
 				mockTransactionService.EXPECT().
 					SendCoin(gomock.Any(), int64(1), "sender", int64(50)).
-					Return(entity.ErrSendThemselves)
+					Return(entity.Receipt{}, entity.ErrSendThemselves)
 			},
 			wantStatus: http.StatusBadRequest,
 			wantBody:   `{"errors":"cannot send coins to yourself"}`,
@@ -99,7 +123,7 @@ func TestHandler_SendCoin(t *testing.T) {
 			mockBehavior: func() {
 				mockTransactionService.EXPECT().
 					SendCoin(gomock.Any(), int64(1), "recipient", int64(50)).
-					Return(errors.New("internal server error"))
+					Return(entity.Receipt{}, errors.New("internal server error"))
 			},
 			wantStatus: http.StatusInternalServerError,
 			wantBody:   `{"errors":"internal server error"}`,
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func (h *Handler) getTransaction(c *gin.Context) {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	transactionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid transaction id")
+		return
+	}
+
+	receipt, err := h.services.Transaction.GetReceipt(c.Request.Context(), userID, transactionID)
+	if err != nil {
+		if errors.Is(err, entity.ErrTransactionNotFound) {
+			entity.NewErrorResponse(c, h.log, http.StatusNotFound, err.Error())
+			return
+		}
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
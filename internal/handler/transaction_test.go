@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/service"
+	mocks "github.com/senyabanana/shop-service/internal/service/mocks"
+)
+
+func TestHandler_GetTransaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransactionService := mocks.NewMockTransaction(ctrl)
+	mockLog := logrus.New()
+	handler := &Handler{services: &service.Service{Transaction: mockTransactionService}, log: mockLog}
+
+	balance := int64(1050)
+	receipt := entity.Receipt{
+		ID:        7,
+		Type:      entity.TransactionTypeTransfer,
+		Amount:    50,
+		FromUser:  "sender",
+		Balance:   &balance,
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name         string
+		idParam      string
+		mockBehavior func()
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name:    "Success",
+			idParam: "7",
+			mockBehavior: func() {
+				mockTransactionService.EXPECT().GetReceipt(gomock.Any(), int64(1), int64(7)).Return(receipt, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":7,"type":"transfer","amount":50,"fromUser":"sender","balance":1050,"createdAt":"2025-01-01T12:00:00Z"}`,
+		},
+		{
+			name:         "Invalid ID",
+			idParam:      "abc",
+			mockBehavior: func() {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid transaction id"}`,
+		},
+		{
+			name:    "Not Found",
+			idParam: "8",
+			mockBehavior: func() {
+				mockTransactionService.EXPECT().GetReceipt(gomock.Any(), int64(1), int64(8)).
+					Return(entity.Receipt{}, entity.ErrTransactionNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"errors":"transaction not found"}`,
+		},
+		{
+			name:    "Internal Error",
+			idParam: "7",
+			mockBehavior: func() {
+				mockTransactionService.EXPECT().GetReceipt(gomock.Any(), int64(1), int64(7)).
+					Return(entity.Receipt{}, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set(userCtx, int64(1))
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/transactions/"+tt.idParam, nil)
+			c.Params = append(c.Params, gin.Param{Key: "id", Value: tt.idParam})
+
+			handler.getTransaction(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func (h *Handler) completeTwoFactorLogin(c *gin.Context) {
+	var input entity.TwoFactorLoginRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	tokens, err := h.services.Authorization.CompleteTwoFactorLogin(c.Request.Context(), input.ChallengeToken, input.Code, c.ClientIP())
+	if err != nil {
+		var lockErr *entity.LoginLockedError
+		switch {
+		case errors.As(err, &lockErr):
+			h.loginLocked(c, lockErr)
+		case errors.Is(err, entity.ErrInvalidChallengeToken), errors.Is(err, entity.ErrInvalidTwoFactorCode):
+			entity.NewErrorResponse(c, h.log, http.StatusUnauthorized, err.Error())
+		default:
+			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) enrollTOTP(c *gin.Context) {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	enrollment, err := h.services.Authorization.EnrollTOTP(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrTwoFactorEnabled) {
+			entity.NewErrorResponse(c, h.log, http.StatusConflict, err.Error())
+			return
+		}
+		entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (h *Handler) confirmTOTP(c *gin.Context) {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	var input entity.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	codes, err := h.services.Authorization.ConfirmTOTP(c.Request.Context(), userID, input.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrTwoFactorEnabled):
+			entity.NewErrorResponse(c, h.log, http.StatusConflict, err.Error())
+		case errors.Is(err, entity.ErrTwoFactorNotEnrolled), errors.Is(err, entity.ErrInvalidTwoFactorCode):
+			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, err.Error())
+		default:
+			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+func (h *Handler) disableTOTP(c *gin.Context) {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+
+	var input entity.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		entity.NewErrorResponse(c, h.log, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	if err := h.services.Authorization.DisableTOTP(c.Request.Context(), userID, input.Password, input.Code); err != nil {
+		var lockErr *entity.LoginLockedError
+		switch {
+		case errors.As(err, &lockErr):
+			h.loginLocked(c, lockErr)
+		case errors.Is(err, entity.ErrTwoFactorNotEnabled):
+			entity.NewErrorResponse(c, h.log, http.StatusBadRequest, err.Error())
+		case errors.Is(err, entity.ErrIncorrectPassword):
+			entity.NewErrorResponse(c, h.log, http.StatusForbidden, "password is incorrect")
+		case errors.Is(err, entity.ErrInvalidTwoFactorCode):
+			entity.NewErrorResponse(c, h.log, http.StatusForbidden, err.Error())
+		default:
+			entity.NewErrorResponse(c, h.log, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.StatusResponse{
+		Status: "two-factor authentication disabled",
+	})
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/service"
+	mocks "github.com/senyabanana/shop-service/internal/service/mocks"
+)
+
+func TestHandler_CompleteTwoFactorLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Success",
+			requestBody: `{"challengeToken":"challenge","code":"123456"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().CompleteTwoFactorLogin(gomock.Any(), "challenge", "123456", gomock.Any()).
+					Return(entity.AuthResponse{Token: "jwt-token", RefreshToken: "refresh-token"}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"token":"jwt-token","refreshToken":"refresh-token"}`,
+		},
+		{
+			name:         "Invalid Request Format",
+			requestBody:  `{"code":"123456"}`,
+			mockBehavior: func() {},
+			wantCode:     http.StatusBadRequest,
+			wantBody:     `{"errors":"invalid request format"}`,
+		},
+		{
+			name:        "Invalid Code",
+			requestBody: `{"challengeToken":"challenge","code":"000000"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().CompleteTwoFactorLogin(gomock.Any(), "challenge", "000000", gomock.Any()).
+					Return(entity.AuthResponse{}, entity.ErrInvalidTwoFactorCode)
+			},
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"errors":"invalid two-factor code"}`,
+		},
+		{
+			name:        "Expired Challenge",
+			requestBody: `{"challengeToken":"expired","code":"123456"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().CompleteTwoFactorLogin(gomock.Any(), "expired", "123456", gomock.Any()).
+					Return(entity.AuthResponse{}, entity.ErrInvalidChallengeToken)
+			},
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"errors":"invalid or expired challenge token"}`,
+		},
+		{
+			name:        "Internal Error",
+			requestBody: `{"challengeToken":"challenge","code":"123456"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().CompleteTwoFactorLogin(gomock.Any(), "challenge", "123456", gomock.Any()).
+					Return(entity.AuthResponse{}, errors.New("db error"))
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"errors":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/2fa", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			handler.completeTwoFactorLogin(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_ConfirmTOTP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthorization(ctrl)
+	mockService := &service.Service{Authorization: mockAuthService}
+	mockLog := logrus.New()
+	handler := &Handler{services: mockService, log: mockLog}
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockBehavior func()
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:        "Success",
+			requestBody: `{"code":"123456"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().ConfirmTOTP(gomock.Any(), int64(1), "123456").
+					Return(entity.RecoveryCodesResponse{RecoveryCodes: []string{"abcde-fghij"}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"recoveryCodes":["abcde-fghij"]}`,
+		},
+		{
+			name:        "Invalid Code",
+			requestBody: `{"code":"000000"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().ConfirmTOTP(gomock.Any(), int64(1), "000000").
+					Return(entity.RecoveryCodesResponse{}, entity.ErrInvalidTwoFactorCode)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"errors":"invalid two-factor code"}`,
+		},
+		{
+			name:        "Already Enabled",
+			requestBody: `{"code":"123456"}`,
+			mockBehavior: func() {
+				mockAuthService.EXPECT().ConfirmTOTP(gomock.Any(), int64(1), "123456").
+					Return(entity.RecoveryCodesResponse{}, entity.ErrTwoFactorEnabled)
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `{"errors":"two-factor authentication is already enabled"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/2fa/confirm", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Set(userCtx, int64(1))
+
+			handler.confirmTOTP(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
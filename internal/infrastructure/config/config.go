@@ -1,6 +1,10 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type Config struct {
 	ServerPort       string `mapstructure:"SERVER_PORT"`
@@ -11,12 +15,87 @@ type Config struct {
 	PostgresDB       string `mapstructure:"POSTGRES_DB"`
 	SSLMode          string `mapstructure:"SSLMODE"`
 	JwtSecretKey     string `mapstructure:"JWTKEY"`
+
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+
+	JwtKeys        string `mapstructure:"JWT_KEYS"`
+	JwtKeysDir     string `mapstructure:"JWT_KEYS_DIR"`
+	JwtActiveKeyID string `mapstructure:"JWT_ACTIVE_KID"`
+
+	AccessTokenTTL  time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+
+	RevocationCacheTTL time.Duration `mapstructure:"REVOCATION_CACHE_TTL"`
+
+	PasswordHashAlgorithm string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	PasswordMinLength     int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	AutoRegister          bool   `mapstructure:"AUTO_REGISTER"`
+
+	SignupGrant      int64  `mapstructure:"SIGNUP_GRANT"`
+	SignupGrantRules string `mapstructure:"SIGNUP_GRANT_RULES"`
+
+	LoginAttemptStore  string        `mapstructure:"LOGIN_ATTEMPT_STORE"`
+	LoginMaxFailures   int           `mapstructure:"LOGIN_MAX_FAILURES"`
+	LoginIPMaxFailures int           `mapstructure:"LOGIN_IP_MAX_FAILURES"`
+	LoginLockoutBase   time.Duration `mapstructure:"LOGIN_LOCKOUT_BASE"`
+	LoginLockoutMax    time.Duration `mapstructure:"LOGIN_LOCKOUT_MAX"`
+	LoginFailureWindow time.Duration `mapstructure:"LOGIN_FAILURE_WINDOW"`
+
+	APIKeyTTL time.Duration `mapstructure:"API_KEY_TTL"`
+
+	TOTPIssuer       string        `mapstructure:"TOTP_ISSUER"`
+	TOTPChallengeTTL time.Duration `mapstructure:"TOTP_CHALLENGE_TTL"`
+
+	IdempotencyKeyTTL time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+
+	InfoHistoryLimit int `mapstructure:"INFO_HISTORY_LIMIT"`
+
+	ReconcileInterval       time.Duration `mapstructure:"RECONCILE_INTERVAL"`
+	AllowanceCheckInterval  time.Duration `mapstructure:"ALLOWANCE_CHECK_INTERVAL"`
+	AllowanceCoinTTL        time.Duration `mapstructure:"ALLOWANCE_COIN_TTL"`
+	CoinExpiryCheckInterval time.Duration `mapstructure:"COIN_EXPIRY_CHECK_INTERVAL"`
+
+	TxMaxAttempts       int           `mapstructure:"TX_MAX_ATTEMPTS"`
+	TxRetryBaseDelay    time.Duration `mapstructure:"TX_RETRY_BASE_DELAY"`
+	TxRetryMaxDelay     time.Duration `mapstructure:"TX_RETRY_MAX_DELAY"`
+	TxSendCoinIsolation string        `mapstructure:"TX_ISOLATION_SEND_COIN"`
+	TxBuyItemIsolation  string        `mapstructure:"TX_ISOLATION_BUY_ITEM"`
 }
 
 func LoadConfig(path string) (cfg *Config, err error) {
 	viper.AddConfigPath(path)
 	viper.SetConfigFile(".env")
 
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("PASSWORD_HASH_ALGORITHM", "argon2id")
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	viper.SetDefault("AUTO_REGISTER", true)
+	viper.SetDefault("SIGNUP_GRANT", 1000)
+	viper.SetDefault("SIGNUP_GRANT_RULES", "")
+	viper.SetDefault("ACCESS_TOKEN_TTL", "15m")
+	viper.SetDefault("REFRESH_TOKEN_TTL", "720h")
+	viper.SetDefault("REVOCATION_CACHE_TTL", "30s")
+	viper.SetDefault("LOGIN_ATTEMPT_STORE", "postgres")
+	viper.SetDefault("LOGIN_MAX_FAILURES", 5)
+	viper.SetDefault("LOGIN_IP_MAX_FAILURES", 20)
+	viper.SetDefault("LOGIN_LOCKOUT_BASE", "30s")
+	viper.SetDefault("LOGIN_LOCKOUT_MAX", "15m")
+	viper.SetDefault("LOGIN_FAILURE_WINDOW", "15m")
+	viper.SetDefault("API_KEY_TTL", "2160h")
+	viper.SetDefault("TOTP_ISSUER", "Shop Service")
+	viper.SetDefault("TOTP_CHALLENGE_TTL", "5m")
+	viper.SetDefault("IDEMPOTENCY_KEY_TTL", "24h")
+	viper.SetDefault("INFO_HISTORY_LIMIT", 0)
+	viper.SetDefault("RECONCILE_INTERVAL", "0s")
+	viper.SetDefault("ALLOWANCE_CHECK_INTERVAL", "1m")
+	viper.SetDefault("ALLOWANCE_COIN_TTL", "0s")
+	viper.SetDefault("COIN_EXPIRY_CHECK_INTERVAL", "1m")
+	viper.SetDefault("TX_MAX_ATTEMPTS", 3)
+	viper.SetDefault("TX_RETRY_BASE_DELAY", "10ms")
+	viper.SetDefault("TX_RETRY_MAX_DELAY", "200ms")
+	viper.SetDefault("TX_ISOLATION_SEND_COIN", "read committed")
+	viper.SetDefault("TX_ISOLATION_BUY_ITEM", "read committed")
+
 	err = viper.ReadInConfig()
 	if err != nil {
 		return
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+type AllowancePostgres struct {
+	db     *sqlx.DB
+	getter *trmsqlx.CtxGetter
+}
+
+func NewAllowancePostgres(db *sqlx.DB) *AllowancePostgres {
+	return &AllowancePostgres{
+		db:     db,
+		getter: trmsqlx.DefaultCtxGetter,
+	}
+}
+
+type allowanceRow struct {
+	ID         int64          `db:"id"`
+	Name       string         `db:"name"`
+	Amount     int64          `db:"amount"`
+	Cadence    string         `db:"cadence"`
+	AllUsers   bool           `db:"all_users"`
+	Usernames  pq.StringArray `db:"usernames"`
+	CreatedBy  int64          `db:"created_by"`
+	CreatedAt  time.Time      `db:"created_at"`
+	LastPeriod *time.Time     `db:"last_period"`
+}
+
+func (row allowanceRow) toEntity() entity.Allowance {
+	return entity.Allowance{
+		ID:         row.ID,
+		Name:       row.Name,
+		Amount:     row.Amount,
+		Cadence:    row.Cadence,
+		AllUsers:   row.AllUsers,
+		Usernames:  row.Usernames,
+		CreatedBy:  row.CreatedBy,
+		CreatedAt:  row.CreatedAt,
+		LastPeriod: row.LastPeriod,
+	}
+}
+
+func (r *AllowancePostgres) CreateAllowance(ctx context.Context, allowance entity.Allowance) (entity.Allowance, error) {
+	query := `
+		INSERT INTO allowances (name, amount, cadence, all_users, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	row := r.getter.DefaultTrOrDB(ctx, r.db).QueryRowContext(ctx, query,
+		allowance.Name, allowance.Amount, allowance.Cadence, allowance.AllUsers, allowance.CreatedBy)
+	if err := row.Scan(&allowance.ID, &allowance.CreatedAt); err != nil {
+		return entity.Allowance{}, err
+	}
+
+	return allowance, nil
+}
+
+func (r *AllowancePostgres) AddAllowanceRecipients(ctx context.Context, allowanceID int64, userIDs []int64) error {
+	query := `
+		INSERT INTO allowance_recipients (allowance_id, user_id)
+		SELECT $1, unnest($2::bigint[])
+		ON CONFLICT DO NOTHING`
+
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, allowanceID, pq.Array(userIDs))
+	return err
+}
+
+// ListAllowances возвращает действующие начисления с получателями и началом последнего выплаченного периода.
+func (r *AllowancePostgres) ListAllowances(ctx context.Context) ([]entity.Allowance, error) {
+	var rows []allowanceRow
+	query := `
+		SELECT a.id, a.name, a.amount, a.cadence, a.all_users, a.created_by, a.created_at,
+			ARRAY(
+				SELECT u.username FROM allowance_recipients AS ar
+				JOIN users AS u ON ar.user_id = u.id
+				WHERE ar.allowance_id = a.id
+				ORDER BY u.username
+			) AS usernames,
+			(
+				SELECT MAX(r.period_start) FROM allowance_runs AS r
+				WHERE r.allowance_id = a.id AND r.completed_at IS NOT NULL
+			) AS last_period
+		FROM allowances AS a
+		WHERE a.disabled_at IS NULL
+		ORDER BY a.id`
+
+	if err := r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+
+	allowances := make([]entity.Allowance, 0, len(rows))
+	for _, row := range rows {
+		allowances = append(allowances, row.toEntity())
+	}
+
+	return allowances, nil
+}
+
+func (r *AllowancePostgres) DisableAllowance(ctx context.Context, allowanceID int64) error {
+	query := `UPDATE allowances SET disabled_at = CURRENT_TIMESTAMP WHERE id = $1 AND disabled_at IS NULL`
+
+	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, allowanceID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return entity.ErrAllowanceNotFound
+	}
+
+	return nil
+}
+
+// ClaimAllowanceRun закрепляет за начислением выплату периода и возвращает ее ID. Если выплата периода
+// уже начата, но прервалась, возвращает ее же, чтобы ее можно было продолжить. Если период уже выплачен
+// полностью, возвращает false.
+func (r *AllowancePostgres) ClaimAllowanceRun(ctx context.Context, allowanceID int64, periodStart time.Time) (int64, bool, error) {
+	var runID int64
+	query := `
+		INSERT INTO allowance_runs (allowance_id, period_start)
+		VALUES ($1, $2)
+		ON CONFLICT (allowance_id, period_start) DO UPDATE SET allowance_id = EXCLUDED.allowance_id
+		WHERE allowance_runs.completed_at IS NULL
+		RETURNING id`
+
+	err := r.getter.DefaultTrOrDB(ctx, r.db).QueryRowContext(ctx, query, allowanceID, periodStart.UTC()).Scan(&runID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return runID, true, nil
+}
+
+// LockAllowanceRun блокирует строку незавершенной выплаты до конца транзакции, чтобы реплики
+// выплачивали пачки одного периода по очереди. Если выплата уже завершена, возвращает false.
+func (r *AllowancePostgres) LockAllowanceRun(ctx context.Context, runID int64) (bool, error) {
+	var id int64
+	query := `SELECT id FROM allowance_runs WHERE id = $1 AND completed_at IS NULL FOR UPDATE`
+
+	err := r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &id, query, runID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetPendingAllowanceRecipients возвращает не больше limit получателей начисления, которым выплата runID
+// еще не досталась, в порядке возрастания ID.
+func (r *AllowancePostgres) GetPendingAllowanceRecipients(ctx context.Context, allowanceID, runID int64, limit int) ([]int64, error) {
+	var userIDs []int64
+	query := `
+		SELECT u.id
+		FROM users AS u
+		JOIN allowances AS a ON a.id = $1
+		WHERE (a.all_users OR EXISTS (
+			SELECT 1 FROM allowance_recipients AS ar
+			WHERE ar.allowance_id = a.id AND ar.user_id = u.id
+		))
+		AND NOT EXISTS (
+			SELECT 1 FROM allowance_payouts AS p
+			WHERE p.run_id = $2 AND p.user_id = u.id
+		)
+		ORDER BY u.id
+		LIMIT $3`
+
+	if err := r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &userIDs, query, allowanceID, runID, limit); err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+func (r *AllowancePostgres) InsertAllowancePayout(ctx context.Context, runID, userID, transactionID int64) error {
+	query := `INSERT INTO allowance_payouts (transaction_id, run_id, user_id) VALUES ($1, $2, $3)`
+
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, transactionID, runID, userID)
+	return err
+}
+
+// CompleteAllowanceRun отмечает, что выплату периода получили все получатели.
+func (r *AllowancePostgres) CompleteAllowanceRun(ctx context.Context, runID int64) error {
+	query := `UPDATE allowance_runs SET completed_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, runID)
+	return err
+}
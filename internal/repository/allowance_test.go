@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func TestAllowancePostgres_CreateAllowance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAllowancePostgres(sqlxDB)
+
+	createdAt := time.Now()
+	allowance := entity.Allowance{Name: "monthly", Amount: 500, Cadence: entity.CadenceMonthly, AllUsers: true, CreatedBy: 1}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+		wantData     entity.Allowance
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO allowances \(name, amount, cadence, all_users, created_by\)`).
+					WithArgs("monthly", int64(500), entity.CadenceMonthly, true, int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(3), createdAt))
+			},
+			wantData: entity.Allowance{
+				ID: 3, Name: "monthly", Amount: 500, Cadence: entity.CadenceMonthly, AllUsers: true, CreatedBy: 1, CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "Query Error",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO allowances`).
+					WillReturnError(errors.New("insert error"))
+			},
+			wantError: errors.New("insert error"),
+			wantData:  entity.Allowance{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			got, err := repo.CreateAllowance(context.Background(), allowance)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantData, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAllowancePostgres_AddAllowanceRecipients(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAllowancePostgres(sqlxDB)
+
+	mock.ExpectExec(`INSERT INTO allowance_recipients \(allowance_id, user_id\)`).
+		WithArgs(int64(3), pq.Array([]int64{1, 2})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err = repo.AddAllowanceRecipients(context.Background(), 3, []int64{1, 2})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAllowancePostgres_ListAllowances(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAllowancePostgres(sqlxDB)
+
+	createdAt := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	lastPeriod := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "name", "amount", "cadence", "all_users", "created_by", "created_at", "usernames", "last_period"}
+
+	mock.ExpectQuery(`SELECT (.+) FROM allowances AS a WHERE a.disabled_at IS NULL`).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "monthly", 500, entity.CadenceMonthly, true, 1, createdAt, "{}", lastPeriod).
+			AddRow(2, "team", 50, entity.CadenceWeekly, false, 1, createdAt, "{alice,bob}", nil))
+
+	got, err := repo.ListAllowances(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Allowance{
+		{ID: 1, Name: "monthly", Amount: 500, Cadence: entity.CadenceMonthly, AllUsers: true, Usernames: []string{}, CreatedBy: 1, CreatedAt: createdAt, LastPeriod: &lastPeriod},
+		{ID: 2, Name: "team", Amount: 50, Cadence: entity.CadenceWeekly, Usernames: []string{"alice", "bob"}, CreatedBy: 1, CreatedAt: createdAt},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAllowancePostgres_DisableAllowance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAllowancePostgres(sqlxDB)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE allowances SET disabled_at").
+					WithArgs(int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Not Found",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE allowances SET disabled_at").
+					WithArgs(int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantError: entity.ErrAllowanceNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := repo.DisableAllowance(context.Background(), 3)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAllowancePostgres_ClaimAllowanceRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAllowancePostgres(sqlxDB)
+
+	periodStart := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantRunID    int64
+		wantClaimed  bool
+		wantError    error
+	}{
+		{
+			name: "Claimed",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO allowance_runs \(allowance_id, period_start\) VALUES \(\$1, \$2\) ON CONFLICT`).
+					WithArgs(int64(3), periodStart).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(9)))
+			},
+			wantRunID:   9,
+			wantClaimed: true,
+		},
+		{
+			name: "Already Paid",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO allowance_runs`).
+					WithArgs(int64(3), periodStart).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+		},
+		{
+			name: "Query Error",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO allowance_runs`).
+					WillReturnError(errors.New("insert error"))
+			},
+			wantError: errors.New("insert error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			runID, claimed, err := repo.ClaimAllowanceRun(context.Background(), 3, periodStart)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantRunID, runID)
+			assert.Equal(t, tt.wantClaimed, claimed)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAllowancePostgres_LockAllowanceRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAllowancePostgres(sqlxDB)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantLocked   bool
+		wantError    error
+	}{
+		{
+			name: "Locked",
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT id FROM allowance_runs WHERE id = \$1 AND completed_at IS NULL FOR UPDATE`).
+					WithArgs(int64(9)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(9)))
+			},
+			wantLocked: true,
+		},
+		{
+			name: "Already Completed",
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT id FROM allowance_runs`).
+					WithArgs(int64(9)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+		},
+		{
+			name: "Query Error",
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT id FROM allowance_runs`).
+					WillReturnError(errors.New("select error"))
+			},
+			wantError: errors.New("select error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			locked, err := repo.LockAllowanceRun(context.Background(), 9)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantLocked, locked)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAllowancePostgres_GetPendingAllowanceRecipients(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAllowancePostgres(sqlxDB)
+
+	mock.ExpectQuery(`SELECT u.id FROM users AS u JOIN allowances AS a ON a.id = \$1 (.+) p.run_id = \$2 (.+) LIMIT \$3`).
+		WithArgs(int64(3), int64(9), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)).AddRow(int64(4)))
+
+	got, err := repo.GetPendingAllowanceRecipients(context.Background(), 3, 9, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 4}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAllowancePostgres_InsertAllowancePayout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAllowancePostgres(sqlxDB)
+
+	mock.ExpectExec(`INSERT INTO allowance_payouts \(transaction_id, run_id, user_id\)`).
+		WithArgs(int64(20), int64(9), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.InsertAllowancePayout(context.Background(), 9, 1, 20)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAllowancePostgres_CompleteAllowanceRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAllowancePostgres(sqlxDB)
+
+	mock.ExpectExec(`UPDATE allowance_runs SET completed_at = CURRENT_TIMESTAMP WHERE id = \$1`).
+		WithArgs(int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.CompleteAllowanceRun(context.Background(), 9)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
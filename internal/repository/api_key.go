@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+type APIKeyPostgres struct {
+	db     *sqlx.DB
+	getter *trmsqlx.CtxGetter
+}
+
+func NewAPIKeyPostgres(db *sqlx.DB) *APIKeyPostgres {
+	return &APIKeyPostgres{
+		db:     db,
+		getter: trmsqlx.DefaultCtxGetter,
+	}
+}
+
+type apiKeyRow struct {
+	ID         int64          `db:"id"`
+	UserID     int64          `db:"user_id"`
+	Name       string         `db:"name"`
+	Prefix     string         `db:"prefix"`
+	KeyHash    string         `db:"key_hash"`
+	Scopes     pq.StringArray `db:"scopes"`
+	ExpiresAt  time.Time      `db:"expires_at"`
+	LastUsedAt *time.Time     `db:"last_used_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+	RevokedAt  *time.Time     `db:"revoked_at"`
+}
+
+func (row apiKeyRow) toEntity() entity.APIKey {
+	return entity.APIKey{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		Name:       row.Name,
+		Prefix:     row.Prefix,
+		KeyHash:    row.KeyHash,
+		Scopes:     row.Scopes,
+		ExpiresAt:  row.ExpiresAt,
+		LastUsedAt: row.LastUsedAt,
+		CreatedAt:  row.CreatedAt,
+		RevokedAt:  row.RevokedAt,
+	}
+}
+
+func (r *APIKeyPostgres) CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error) {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	row := r.getter.DefaultTrOrDB(ctx, r.db).QueryRowContext(ctx, query,
+		key.UserID, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Scopes), key.ExpiresAt)
+	if err := row.Scan(&key.ID, &key.CreatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return entity.APIKey{}, entity.ErrAPIKeyPrefixTaken
+		}
+		return entity.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyPostgres) GetAPIKeyByPrefix(ctx context.Context, prefix string) (entity.APIKey, error) {
+	var row apiKeyRow
+	query := `
+		SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at, revoked_at
+		FROM api_keys
+		WHERE prefix = $1`
+
+	if err := r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &row, query, prefix); err != nil {
+		return entity.APIKey{}, err
+	}
+
+	return row.toEntity(), nil
+}
+
+func (r *APIKeyPostgres) ListAPIKeys(ctx context.Context, userID int64) ([]entity.APIKey, error) {
+	var rows []apiKeyRow
+	query := `
+		SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at, revoked_at
+		FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY id`
+
+	if err := r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &rows, query, userID); err != nil {
+		return nil, err
+	}
+
+	keys := make([]entity.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row.toEntity())
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyPostgres) RevokeAPIKey(ctx context.Context, userID, keyID int64) error {
+	query := `UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, keyID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return entity.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// TouchAPIKey обновляет время последнего использования не чаще, чем раз в interval,
+// чтобы каждый запрос с ключом не превращался в запись в БД.
+func (r *APIKeyPostgres) TouchAPIKey(ctx context.Context, keyID int64, usedAt time.Time, interval time.Duration) error {
+	query := `UPDATE api_keys SET last_used_at = $1 WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, usedAt, keyID, usedAt.Add(-interval))
+
+	return err
+}
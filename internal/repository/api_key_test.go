@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+var apiKeyColumns = []string{"id", "user_id", "name", "prefix", "key_hash", "scopes", "expires_at", "last_used_at", "created_at", "revoked_at"}
+
+func TestAPIKeyPostgres_CreateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAPIKeyPostgres(sqlxDB)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	createdAt := time.Now().Truncate(time.Second)
+	input := entity.APIKey{
+		UserID:    1,
+		Name:      "bot",
+		Prefix:    "0a1b2c3d",
+		KeyHash:   "hash",
+		Scopes:    []string{entity.ScopeInfo, entity.ScopeBuy},
+		ExpiresAt: expiresAt,
+	}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantID       int64
+		wantError    error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectQuery("INSERT INTO api_keys").
+					WithArgs(int64(1), "bot", "0a1b2c3d", "hash", pq.Array(input.Scopes), expiresAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, createdAt))
+			},
+			wantID:    7,
+			wantError: nil,
+		},
+		{
+			name: "Insert Error",
+			mockBehavior: func() {
+				mock.ExpectQuery("INSERT INTO api_keys").
+					WithArgs(int64(1), "bot", "0a1b2c3d", "hash", pq.Array(input.Scopes), expiresAt).
+					WillReturnError(errors.New("insert error"))
+			},
+			wantID:    0,
+			wantError: errors.New("insert error"),
+		},
+		{
+			name: "Prefix Taken",
+			mockBehavior: func() {
+				mock.ExpectQuery("INSERT INTO api_keys").
+					WithArgs(int64(1), "bot", "0a1b2c3d", "hash", pq.Array(input.Scopes), expiresAt).
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			wantID:    0,
+			wantError: entity.ErrAPIKeyPrefixTaken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			got, err := repo.CreateAPIKey(context.Background(), input)
+
+			assert.Equal(t, tt.wantID, got.ID)
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAPIKeyPostgres_GetAPIKeyByPrefix(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAPIKeyPostgres(sqlxDB)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	createdAt := time.Now().Truncate(time.Second)
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE prefix = \\$1").
+		WithArgs("0a1b2c3d").
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+			AddRow(7, 1, "bot", "0a1b2c3d", "hash", "{info,buy}", expiresAt, nil, createdAt, nil))
+
+	got, err := repo.GetAPIKeyByPrefix(context.Background(), "0a1b2c3d")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), got.ID)
+	assert.Equal(t, []string{entity.ScopeInfo, entity.ScopeBuy}, got.Scopes)
+	assert.Nil(t, got.RevokedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyPostgres_RevokeAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewAPIKeyPostgres(sqlxDB)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE api_keys SET revoked_at").
+					WithArgs(int64(7), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantError: nil,
+		},
+		{
+			name: "Not Found",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE api_keys SET revoked_at").
+					WithArgs(int64(7), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantError: entity.ErrAPIKeyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := repo.RevokeAPIKey(context.Background(), 1, 7)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+type CoinAdjustmentPostgres struct {
+	db     *sqlx.DB
+	getter *trmsqlx.CtxGetter
+}
+
+func NewCoinAdjustmentPostgres(db *sqlx.DB) *CoinAdjustmentPostgres {
+	return &CoinAdjustmentPostgres{
+		db:     db,
+		getter: trmsqlx.DefaultCtxGetter,
+	}
+}
+
+func (r *CoinAdjustmentPostgres) InsertCoinAdjustment(ctx context.Context, adjustment entity.CoinAdjustment) (entity.CoinAdjustment, error) {
+	query := `
+		INSERT INTO coin_adjustments (transaction_id, admin_id, reason)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+
+	row := r.getter.DefaultTrOrDB(ctx, r.db).QueryRowContext(ctx, query,
+		adjustment.TransactionID, adjustment.AdminID, adjustment.Reason)
+	if err := row.Scan(&adjustment.ID, &adjustment.CreatedAt); err != nil {
+		return entity.CoinAdjustment{}, err
+	}
+
+	return adjustment, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func TestCoinAdjustmentPostgres_InsertCoinAdjustment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewCoinAdjustmentPostgres(sqlxDB)
+
+	createdAt := time.Now()
+	adjustment := entity.CoinAdjustment{TransactionID: 7, AdminID: 1, Reason: "Q1 bonus"}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+		wantData     entity.CoinAdjustment
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO coin_adjustments \(transaction_id, admin_id, reason\)`).
+					WithArgs(int64(7), int64(1), "Q1 bonus").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(3), createdAt))
+			},
+			wantError: nil,
+			wantData:  entity.CoinAdjustment{ID: 3, TransactionID: 7, AdminID: 1, Reason: "Q1 bonus", CreatedAt: createdAt},
+		},
+		{
+			name: "Query Error",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO coin_adjustments`).
+					WillReturnError(errors.New("insert error"))
+			},
+			wantError: errors.New("insert error"),
+			wantData:  entity.CoinAdjustment{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			got, err := repo.InsertCoinAdjustment(context.Background(), adjustment)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantData, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
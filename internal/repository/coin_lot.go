@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+type CoinLotPostgres struct {
+	db     *sqlx.DB
+	getter *trmsqlx.CtxGetter
+}
+
+func NewCoinLotPostgres(db *sqlx.DB) *CoinLotPostgres {
+	return &CoinLotPostgres{
+		db:     db,
+		getter: trmsqlx.DefaultCtxGetter,
+	}
+}
+
+func (r *CoinLotPostgres) InsertCoinLot(ctx context.Context, lot entity.CoinLot) (entity.CoinLot, error) {
+	query := `
+		INSERT INTO coin_lots (user_id, transaction_id, amount, remaining, granted_at, expires_at)
+		VALUES ($1, $2, $3, $3, $4, $5)
+		RETURNING id`
+
+	row := r.getter.DefaultTrOrDB(ctx, r.db).QueryRowContext(ctx, query,
+		lot.UserID, lot.TransactionID, lot.Amount, lot.GrantedAt.UTC(), lot.ExpiresAt.UTC())
+	if err := row.Scan(&lot.ID); err != nil {
+		return entity.CoinLot{}, err
+	}
+	lot.Remaining = lot.Amount
+
+	return lot, nil
+}
+
+// ConsumeCoinLots списывает amount монет с несгоревших партий пользователя, начиная с самой ранней.
+// Если в партиях меньше amount, они обнуляются, а остаток тратится из несгораемых монет.
+// Строка пользователя должна быть заблокирована вызывающим, иначе параллельные траты могут списать
+// одну и ту же часть партии дважды.
+func (r *CoinLotPostgres) ConsumeCoinLots(ctx context.Context, userID, amount int64, now time.Time) error {
+	query := `
+		WITH open AS (
+			SELECT id, remaining,
+				SUM(remaining) OVER (ORDER BY granted_at, id) - remaining AS spent_before
+			FROM coin_lots
+			WHERE user_id = $1 AND remaining > 0 AND expires_at > $3
+		)
+		UPDATE coin_lots AS l
+		SET remaining = l.remaining - LEAST(o.remaining, $2 - o.spent_before)
+		FROM open AS o
+		WHERE l.id = o.id AND o.spent_before < $2`
+
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, userID, amount, now.UTC())
+	return err
+}
+
+// GetUpcomingExpirations возвращает несгоревшие остатки партий пользователя в порядке сгорания.
+func (r *CoinLotPostgres) GetUpcomingExpirations(ctx context.Context, userID int64, now time.Time) ([]entity.CoinExpiration, error) {
+	var expirations []entity.CoinExpiration
+	query := `
+		SELECT remaining AS amount, expires_at
+		FROM coin_lots
+		WHERE user_id = $1 AND remaining > 0 AND expires_at > $2
+		ORDER BY expires_at, id`
+
+	if err := r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &expirations, query, userID, now.UTC()); err != nil {
+		return nil, err
+	}
+
+	return expirations, nil
+}
+
+func (r *CoinLotPostgres) GetUsersWithExpiredLots(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	var userIDs []int64
+	query := `
+		SELECT DISTINCT user_id
+		FROM coin_lots
+		WHERE remaining > 0 AND expires_at <= $1
+		ORDER BY user_id
+		LIMIT $2`
+
+	if err := r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &userIDs, query, now.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+// ExpireCoinLots обнуляет сгоревшие партии пользователя и возвращает сумму их остатков.
+func (r *CoinLotPostgres) ExpireCoinLots(ctx context.Context, userID int64, now time.Time) (int64, error) {
+	var expired int64
+	query := `
+		WITH expired AS (
+			SELECT id, remaining
+			FROM coin_lots
+			WHERE user_id = $1 AND remaining > 0 AND expires_at <= $2
+			FOR UPDATE
+		), updated AS (
+			UPDATE coin_lots AS l
+			SET remaining = 0
+			FROM expired AS e
+			WHERE l.id = e.id
+		)
+		SELECT COALESCE(SUM(remaining), 0) FROM expired`
+
+	if err := r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &expired, query, userID, now.UTC()); err != nil {
+		return 0, err
+	}
+
+	return expired, nil
+}
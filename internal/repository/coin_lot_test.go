@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func TestCoinLotPostgres_InsertCoinLot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewCoinLotPostgres(sqlxDB)
+
+	grantedAt := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := grantedAt.AddDate(0, 3, 0)
+	lot := entity.CoinLot{UserID: 1, TransactionID: 20, Amount: 500, GrantedAt: grantedAt, ExpiresAt: expiresAt}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+		wantData     entity.CoinLot
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO coin_lots \(user_id, transaction_id, amount, remaining, granted_at, expires_at\)`).
+					WithArgs(int64(1), int64(20), int64(500), grantedAt, expiresAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(4)))
+			},
+			wantData: entity.CoinLot{
+				ID: 4, UserID: 1, TransactionID: 20, Amount: 500, Remaining: 500, GrantedAt: grantedAt, ExpiresAt: expiresAt,
+			},
+		},
+		{
+			name: "Query Error",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO coin_lots`).
+					WillReturnError(errors.New("insert error"))
+			},
+			wantError: errors.New("insert error"),
+			wantData:  entity.CoinLot{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			got, err := repo.InsertCoinLot(context.Background(), lot)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantData, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCoinLotPostgres_ConsumeCoinLots(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewCoinLotPostgres(sqlxDB)
+
+	now := time.Date(2025, 2, 13, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(`(?s)ORDER BY granted_at, id.*UPDATE coin_lots AS l SET remaining = l.remaining - LEAST`).
+		WithArgs(int64(1), int64(300), now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err = repo.ConsumeCoinLots(context.Background(), 1, 300, now)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCoinLotPostgres_GetUpcomingExpirations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewCoinLotPostgres(sqlxDB)
+
+	now := time.Date(2025, 2, 13, 9, 0, 0, 0, time.UTC)
+	first := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	second := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT remaining AS amount, expires_at FROM coin_lots WHERE user_id = \$1 AND remaining > 0 AND expires_at > \$2`).
+		WithArgs(int64(1), now).
+		WillReturnRows(sqlmock.NewRows([]string{"amount", "expires_at"}).AddRow(200, first).AddRow(500, second))
+
+	got, err := repo.GetUpcomingExpirations(context.Background(), 1, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.CoinExpiration{{Amount: 200, ExpiresAt: first}, {Amount: 500, ExpiresAt: second}}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCoinLotPostgres_GetUsersWithExpiredLots(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewCoinLotPostgres(sqlxDB)
+
+	now := time.Date(2025, 2, 13, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT DISTINCT user_id FROM coin_lots WHERE remaining > 0 AND expires_at <= \$1`).
+		WithArgs(now, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(int64(1)).AddRow(int64(3)))
+
+	got, err := repo.GetUsersWithExpiredLots(context.Background(), now, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCoinLotPostgres_ExpireCoinLots(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewCoinLotPostgres(sqlxDB)
+
+	now := time.Date(2025, 2, 13, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantExpired  int64
+		wantError    error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectQuery(`(?s)FOR UPDATE.*UPDATE coin_lots AS l SET remaining = 0.*SELECT COALESCE\(SUM\(remaining\), 0\) FROM expired`).
+					WithArgs(int64(1), now).
+					WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(int64(350)))
+			},
+			wantExpired: 350,
+		},
+		{
+			name: "Query Error",
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT COALESCE`).
+					WillReturnError(errors.New("update error"))
+			},
+			wantError: errors.New("update error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			expired, err := repo.ExpireCoinLots(context.Background(), 1, now)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantExpired, expired)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	uniqueViolationCode      = "23505"
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+)
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode
+}
+
+// IsRetryable сообщает, что Postgres прервал транзакцию из-за конфликта с параллельной
+// (serialization failure или deadlock) и ее можно безопасно повторить целиком.
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == serializationFailureCode || pqErr.Code == deadlockDetectedCode
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+type IdempotencyPostgres struct {
+	db     *sqlx.DB
+	getter *trmsqlx.CtxGetter
+}
+
+func NewIdempotencyPostgres(db *sqlx.DB) *IdempotencyPostgres {
+	return &IdempotencyPostgres{
+		db:     db,
+		getter: trmsqlx.DefaultCtxGetter,
+	}
+}
+
+// CreateIdempotencyKey резервирует ключ. Возвращает false, если ключ уже использован.
+// Параллельный запрос с тем же ключом ждет на уникальном индексе, пока первая транзакция
+// не завершится, поэтому видит либо сохраненный ответ, либо свободный ключ после отката.
+func (r *IdempotencyPostgres) CreateIdempotencyKey(ctx context.Context, userID int64, key, requestHash string) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (user_id, key, request_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, key) DO NOTHING`
+
+	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, userID, key, requestHash)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+func (r *IdempotencyPostgres) GetIdempotencyKey(ctx context.Context, userID int64, key string) (entity.IdempotencyRecord, error) {
+	var record entity.IdempotencyRecord
+	query := `
+		SELECT user_id, key, request_hash, COALESCE(status_code, 0) AS status_code, response_body, created_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2`
+
+	return record, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &record, query, userID, key)
+}
+
+func (r *IdempotencyPostgres) SaveIdempotentResponse(ctx context.Context, userID int64, key string, statusCode int, body []byte) error {
+	query := `UPDATE idempotency_keys SET status_code = $1, response_body = $2 WHERE user_id = $3 AND key = $4`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, statusCode, body, userID, key)
+
+	return err
+}
+
+func (r *IdempotencyPostgres) DeleteExpiredIdempotencyKeys(ctx context.Context, userID int64, createdBefore time.Time) error {
+	query := `DELETE FROM idempotency_keys WHERE user_id = $1 AND created_at < $2`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, userID, createdBefore)
+
+	return err
+}
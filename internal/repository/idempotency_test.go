@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyPostgres_CreateIdempotencyKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewIdempotencyPostgres(sqlxDB)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		want         bool
+		wantError    error
+	}{
+		{
+			name: "New Key",
+			mockBehavior: func() {
+				mock.ExpectExec("INSERT INTO idempotency_keys").
+					WithArgs(int64(1), "key-1", "hash").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want:      true,
+			wantError: nil,
+		},
+		{
+			name: "Key Already Used",
+			mockBehavior: func() {
+				mock.ExpectExec("INSERT INTO idempotency_keys").
+					WithArgs(int64(1), "key-1", "hash").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want:      false,
+			wantError: nil,
+		},
+		{
+			name: "Insert Error",
+			mockBehavior: func() {
+				mock.ExpectExec("INSERT INTO idempotency_keys").
+					WithArgs(int64(1), "key-1", "hash").
+					WillReturnError(errors.New("insert error"))
+			},
+			want:      false,
+			wantError: errors.New("insert error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			got, err := repo.CreateIdempotencyKey(context.Background(), 1, "key-1", "hash")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestIdempotencyPostgres_GetIdempotencyKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewIdempotencyPostgres(sqlxDB)
+
+	mock.ExpectQuery("SELECT (.+) FROM idempotency_keys WHERE user_id = \\$1 AND key = \\$2").
+		WithArgs(int64(1), "key-1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "key", "request_hash", "status_code", "response_body", "created_at"}).
+			AddRow(1, "key-1", "hash", 200, []byte(`{"status":"ok"}`), time.Now()))
+
+	got, err := repo.GetIdempotencyKey(context.Background(), 1, "key-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hash", got.RequestHash)
+	assert.Equal(t, 200, got.StatusCode)
+	assert.Equal(t, []byte(`{"status":"ok"}`), got.ResponseBody)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
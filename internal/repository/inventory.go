@@ -35,7 +35,8 @@ func (r *InventoryPostgres) GetUserInventory(ctx context.Context, userID int64)
 		FROM inventory AS i
 		JOIN merch_items AS mi ON i.merch_id = mi.id
 		WHERE i.user_id = $1
-		GROUP BY mi.item_type`
+		GROUP BY mi.item_type
+		HAVING SUM(i.quantity) > 0`
 
 	return inventory, r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &inventory, query, userID)
 }
@@ -57,6 +58,27 @@ func (r *InventoryPostgres) UpdateInventoryItem(ctx context.Context, userID, mer
 	return err
 }
 
+// RemoveInventoryItems забирает у пользователя quantity единиц товара.
+// Если столько у него нет, инвентарь не меняется и возвращается entity.ErrItemNotOwned.
+func (r *InventoryPostgres) RemoveInventoryItems(ctx context.Context, userID, merchID int64, quantity int) error {
+	query := `UPDATE inventory SET quantity = quantity - $3 WHERE user_id = $1 AND merch_id = $2 AND quantity >= $3`
+
+	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, userID, merchID, quantity)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return entity.ErrItemNotOwned
+	}
+
+	return nil
+}
+
 func (r *InventoryPostgres) InsertInventoryItem(ctx context.Context, userID, merchID int64) error {
 	query := `INSERT INTO inventory (user_id, merch_id, quantity) VALUES ($1, $2, 1)`
 	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, userID, merchID)
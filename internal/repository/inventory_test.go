@@ -246,6 +246,51 @@ func TestInventoryPostgres_UpdateInventoryItem(t *testing.T) {
 	}
 }
 
+func TestInventoryPostgres_RemoveInventoryItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewInventoryPostgres(sqlxDB)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectExec(`UPDATE inventory SET quantity = quantity - \$3 WHERE user_id = \$1 AND merch_id = \$2 AND quantity >= \$3`).
+					WithArgs(int64(1), int64(2), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantError: nil,
+		},
+		{
+			name: "Not Owned",
+			mockBehavior: func() {
+				mock.ExpectExec(`UPDATE inventory SET quantity = quantity - \$3`).
+					WithArgs(int64(1), int64(2), 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantError: entity.ErrItemNotOwned,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := repo.RemoveInventoryItems(context.Background(), 1, 2, 1)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestInventoryPostgres_InsertInventoryItem(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+type LedgerPostgres struct {
+	db     *sqlx.DB
+	getter *trmsqlx.CtxGetter
+}
+
+func NewLedgerPostgres(db *sqlx.DB) *LedgerPostgres {
+	return &LedgerPostgres{
+		db:     db,
+		getter: trmsqlx.DefaultCtxGetter,
+	}
+}
+
+func (r *LedgerPostgres) CreateUserAccount(ctx context.Context, userID int64) (int64, error) {
+	var id int64
+	query := `INSERT INTO ledger_accounts (type, user_id) VALUES ('user', $1) RETURNING id`
+
+	return id, r.getter.DefaultTrOrDB(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(&id)
+}
+
+func (r *LedgerPostgres) GetUserAccountID(ctx context.Context, userID int64) (int64, error) {
+	var id int64
+	query := `SELECT id FROM ledger_accounts WHERE user_id = $1`
+
+	return id, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &id, query, userID)
+}
+
+func (r *LedgerPostgres) GetSystemAccountID(ctx context.Context, accountType string) (int64, error) {
+	var id int64
+	query := `SELECT id FROM ledger_accounts WHERE type = $1 AND user_id IS NULL`
+
+	return id, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &id, query, accountType)
+}
+
+// CreateJournalEntry сохраняет запись журнала вместе с проводками.
+// Сбалансированность записи дополнительно проверяется триггером при коммите.
+func (r *LedgerPostgres) CreateJournalEntry(ctx context.Context, entry entity.JournalEntry) (int64, error) {
+	db := r.getter.DefaultTrOrDB(ctx, r.db)
+
+	var entryID int64
+	query := `INSERT INTO journal_entries (kind, transaction_id) VALUES ($1, $2) RETURNING id`
+	if err := db.QueryRowContext(ctx, query, entry.Kind, entry.TransactionID).Scan(&entryID); err != nil {
+		return 0, err
+	}
+
+	accountIDs := make([]int64, 0, len(entry.Postings))
+	amounts := make([]int64, 0, len(entry.Postings))
+	for _, posting := range entry.Postings {
+		accountIDs = append(accountIDs, posting.AccountID)
+		amounts = append(amounts, posting.Amount)
+	}
+
+	query = `
+		INSERT INTO ledger_postings (entry_id, account_id, amount)
+		SELECT $1, account_id, amount
+		FROM unnest($2::bigint[], $3::bigint[]) AS p(account_id, amount)`
+	if _, err := db.ExecContext(ctx, query, entryID, pq.Array(accountIDs), pq.Array(amounts)); err != nil {
+		return 0, err
+	}
+
+	return entryID, nil
+}
+
+// GetBalanceDrifts возвращает пользователей, у которых users.coins не совпадает с суммой проводок по их счету.
+// Пользователи без счета в журнале тоже попадают в выборку с отметкой missing_account.
+func (r *LedgerPostgres) GetBalanceDrifts(ctx context.Context) ([]entity.BalanceDrift, error) {
+	var drifts []entity.BalanceDrift
+	query := `
+		SELECT u.id AS user_id, u.username, u.coins AS cached,
+			COALESCE(SUM(p.amount), 0) AS expected, a.id IS NULL AS missing_account
+		FROM users AS u
+		LEFT JOIN ledger_accounts AS a ON a.user_id = u.id
+		LEFT JOIN ledger_postings AS p ON p.account_id = a.id
+		GROUP BY u.id, a.id
+		HAVING a.id IS NULL OR u.coins <> COALESCE(SUM(p.amount), 0)
+		ORDER BY u.id`
+
+	return drifts, r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &drifts, query)
+}
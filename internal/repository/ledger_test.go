@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func TestLedgerPostgres_CreateUserAccount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewLedgerPostgres(sqlxDB)
+
+	mock.ExpectQuery(`INSERT INTO ledger_accounts \(type, user_id\) VALUES \('user', \$1\) RETURNING id`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(10)))
+
+	id, err := repo.CreateUserAccount(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLedgerPostgres_GetSystemAccountID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewLedgerPostgres(sqlxDB)
+
+	mock.ExpectQuery(`SELECT id FROM ledger_accounts WHERE type = \$1 AND user_id IS NULL`).
+		WithArgs(entity.LedgerAccountShop).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+
+	id, err := repo.GetSystemAccountID(context.Background(), entity.LedgerAccountShop)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLedgerPostgres_CreateJournalEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewLedgerPostgres(sqlxDB)
+
+	transactionID := int64(7)
+	entry := entity.JournalEntry{
+		Kind:          entity.JournalTransfer,
+		TransactionID: &transactionID,
+		Postings: []entity.LedgerPosting{
+			{AccountID: 11, Amount: -50},
+			{AccountID: 12, Amount: 50},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+		wantID       int64
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO journal_entries \(kind, transaction_id\) VALUES \(\$1, \$2\) RETURNING id`).
+					WithArgs(entity.JournalTransfer, &transactionID).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(4)))
+				mock.ExpectExec(`INSERT INTO ledger_postings \(entry_id, account_id, amount\)
+					SELECT \$1, account_id, amount
+					FROM unnest\(\$2::bigint\[\], \$3::bigint\[\]\) AS p\(account_id, amount\)`).
+					WithArgs(int64(4), pq.Array([]int64{11, 12}), pq.Array([]int64{-50, 50})).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			wantError: nil,
+			wantID:    4,
+		},
+		{
+			name: "Postings Error",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO journal_entries`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(4)))
+				mock.ExpectExec(`INSERT INTO ledger_postings`).
+					WillReturnError(errors.New("insert error"))
+			},
+			wantError: errors.New("insert error"),
+			wantID:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			id, err := repo.CreateJournalEntry(context.Background(), entry)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantID, id)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLedgerPostgres_GetBalanceDrifts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewLedgerPostgres(sqlxDB)
+
+	mock.ExpectQuery(`SELECT u.id AS user_id, u.username, u.coins AS cached,
+			COALESCE\(SUM\(p.amount\), 0\) AS expected, a.id IS NULL AS missing_account
+		FROM users AS u
+		LEFT JOIN ledger_accounts AS a ON a.user_id = u.id
+		LEFT JOIN ledger_postings AS p ON p.account_id = a.id
+		GROUP BY u.id, a.id
+		HAVING a.id IS NULL OR u.coins <> COALESCE\(SUM\(p.amount\), 0\)
+		ORDER BY u.id`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "cached", "expected", "missing_account"}).
+			AddRow(int64(1), "alice", int64(950), int64(900), false).
+			AddRow(int64(2), "bob", int64(1000), int64(0), true))
+
+	drifts, err := repo.GetBalanceDrifts(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.BalanceDrift{
+		{UserID: 1, Username: "alice", Cached: 950, Expected: 900},
+		{UserID: 2, Username: "bob", Cached: 1000, Expected: 0, MissingAccount: true},
+	}, drifts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type LoginAttemptPostgres struct {
+	db     *sqlx.DB
+	getter *trmsqlx.CtxGetter
+}
+
+func NewLoginAttemptPostgres(db *sqlx.DB) *LoginAttemptPostgres {
+	return &LoginAttemptPostgres{
+		db:     db,
+		getter: trmsqlx.DefaultCtxGetter,
+	}
+}
+
+func (r *LoginAttemptPostgres) GetLoginLockout(ctx context.Context, keys ...string) (time.Time, error) {
+	var lockedUntil sql.NullTime
+	query := `SELECT MAX(locked_until) FROM login_attempts WHERE key = ANY($1)`
+
+	if err := r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &lockedUntil, query, pq.Array(keys)); err != nil {
+		return time.Time{}, err
+	}
+
+	return lockedUntil.Time, nil
+}
+
+func (r *LoginAttemptPostgres) RegisterLoginFailure(ctx context.Context, key string, now, windowStart time.Time) (int, error) {
+	var failures int
+	query := `
+		INSERT INTO login_attempts (key, failures, last_failure_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+				WHEN login_attempts.last_failure_at < $3 THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING failures`
+
+	return failures, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &failures, query, key, now, windowStart)
+}
+
+func (r *LoginAttemptPostgres) LockLogin(ctx context.Context, key string, until time.Time) error {
+	query := `UPDATE login_attempts SET locked_until = GREATEST(locked_until, $1) WHERE key = $2`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, until, key)
+
+	return err
+}
+
+func (r *LoginAttemptPostgres) ResetLoginAttempts(ctx context.Context, key string) error {
+	query := `DELETE FROM login_attempts WHERE key = $1`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, key)
+
+	return err
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const loginAttemptSweepInterval = time.Minute
+
+type loginAttempt struct {
+	failures      int
+	lastFailureAt time.Time
+	lockedUntil   time.Time
+}
+
+// LoginAttemptMemory хранит счетчики неудачных входов в памяти процесса.
+// Подходит только для развертывания в одном экземпляре: счетчики не разделяются между репликами
+// и сбрасываются при перезапуске.
+type LoginAttemptMemory struct {
+	mu        sync.Mutex
+	attempts  map[string]*loginAttempt
+	lastSweep time.Time
+}
+
+func NewLoginAttemptMemory() *LoginAttemptMemory {
+	return &LoginAttemptMemory{
+		attempts:  make(map[string]*loginAttempt),
+		lastSweep: time.Now(),
+	}
+}
+
+func (r *LoginAttemptMemory) GetLoginLockout(_ context.Context, keys ...string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var lockedUntil time.Time
+	for _, key := range keys {
+		if attempt, ok := r.attempts[key]; ok && attempt.lockedUntil.After(lockedUntil) {
+			lockedUntil = attempt.lockedUntil
+		}
+	}
+
+	return lockedUntil, nil
+}
+
+func (r *LoginAttemptMemory) RegisterLoginFailure(_ context.Context, key string, now, windowStart time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sweep(now, windowStart)
+
+	attempt, ok := r.attempts[key]
+	if !ok {
+		attempt = &loginAttempt{}
+		r.attempts[key] = attempt
+	}
+
+	if attempt.lastFailureAt.Before(windowStart) {
+		attempt.failures = 0
+	}
+	attempt.failures++
+	attempt.lastFailureAt = now
+
+	return attempt.failures, nil
+}
+
+func (r *LoginAttemptMemory) LockLogin(_ context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attempt, ok := r.attempts[key]; ok && until.After(attempt.lockedUntil) {
+		attempt.lockedUntil = until
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptMemory) ResetLoginAttempts(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+// sweep удаляет записи, которые уже не влияют ни на счетчик, ни на блокировку.
+func (r *LoginAttemptMemory) sweep(now, windowStart time.Time) {
+	if now.Sub(r.lastSweep) < loginAttemptSweepInterval {
+		return
+	}
+	r.lastSweep = now
+
+	for key, attempt := range r.attempts {
+		if attempt.lastFailureAt.Before(windowStart) && !attempt.lockedUntil.After(now) {
+			delete(r.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginAttemptPostgres_GetLoginLockout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewLoginAttemptPostgres(sqlxDB)
+
+	lockedUntil := time.Now().Add(time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		want         time.Time
+		wantError    error
+	}{
+		{
+			name: "Locked",
+			mockBehavior: func() {
+				mock.ExpectQuery("SELECT MAX\\(locked_until\\) FROM login_attempts").
+					WithArgs(pq.Array([]string{"user:alice", "ip:10.0.0.1"})).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(lockedUntil))
+			},
+			want:      lockedUntil,
+			wantError: nil,
+		},
+		{
+			name: "Not Locked",
+			mockBehavior: func() {
+				mock.ExpectQuery("SELECT MAX\\(locked_until\\) FROM login_attempts").
+					WithArgs(pq.Array([]string{"user:alice", "ip:10.0.0.1"})).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+			},
+			want:      time.Time{},
+			wantError: nil,
+		},
+		{
+			name: "Query Error",
+			mockBehavior: func() {
+				mock.ExpectQuery("SELECT MAX\\(locked_until\\) FROM login_attempts").
+					WithArgs(pq.Array([]string{"user:alice", "ip:10.0.0.1"})).
+					WillReturnError(errors.New("query error"))
+			},
+			want:      time.Time{},
+			wantError: errors.New("query error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			got, err := repo.GetLoginLockout(context.Background(), "user:alice", "ip:10.0.0.1")
+
+			assert.True(t, tt.want.Equal(got))
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLoginAttemptPostgres_RegisterLoginFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewLoginAttemptPostgres(sqlxDB)
+
+	now := time.Now()
+	windowStart := now.Add(-time.Hour)
+
+	mock.ExpectQuery("INSERT INTO login_attempts").
+		WithArgs("user:alice", now, windowStart).
+		WillReturnRows(sqlmock.NewRows([]string{"failures"}).AddRow(3))
+
+	failures, err := repo.RegisterLoginFailure(context.Background(), "user:alice", now, windowStart)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, failures)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttemptPostgres_LockAndReset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewLoginAttemptPostgres(sqlxDB)
+
+	until := time.Now().Add(time.Minute)
+
+	mock.ExpectExec("UPDATE login_attempts SET locked_until").
+		WithArgs(until, "user:alice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM login_attempts").
+		WithArgs("user:alice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.LockLogin(context.Background(), "user:alice", until))
+	assert.NoError(t, repo.ResetLoginAttempts(context.Background(), "user:alice"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttemptMemory(t *testing.T) {
+	repo := NewLoginAttemptMemory()
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 1; i <= 3; i++ {
+		failures, err := repo.RegisterLoginFailure(ctx, "user:alice", now, now.Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, i, failures)
+	}
+
+	// Неудача за пределами окна начинает отсчет заново.
+	failures, err := repo.RegisterLoginFailure(ctx, "user:alice", now.Add(2*time.Hour), now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	lockedUntil, err := repo.GetLoginLockout(ctx, "user:alice", "ip:10.0.0.1")
+	assert.NoError(t, err)
+	assert.True(t, lockedUntil.IsZero())
+
+	until := now.Add(time.Minute)
+	assert.NoError(t, repo.LockLogin(ctx, "user:alice", until))
+	assert.NoError(t, repo.LockLogin(ctx, "user:alice", now), "shorter lock must not override a longer one")
+
+	lockedUntil, err = repo.GetLoginLockout(ctx, "user:alice", "ip:10.0.0.1")
+	assert.NoError(t, err)
+	assert.True(t, until.Equal(lockedUntil))
+
+	assert.NoError(t, repo.ResetLoginAttempts(ctx, "user:alice"))
+
+	lockedUntil, err = repo.GetLoginLockout(ctx, "user:alice")
+	assert.NoError(t, err)
+	assert.True(t, lockedUntil.IsZero())
+}
@@ -7,6 +7,7 @@ package mock_repository
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	entity "github.com/senyabanana/shop-service/internal/entity"
@@ -35,130 +36,1325 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AdjustCoins mocks base method.
+func (m *MockUserRepository) AdjustCoins(ctx context.Context, userID, amount int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustCoins", ctx, userID, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustCoins indicates an expected call of AdjustCoins.
+func (mr *MockUserRepositoryMockRecorder) AdjustCoins(ctx, userID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustCoins", reflect.TypeOf((*MockUserRepository)(nil).AdjustCoins), ctx, userID, amount)
+}
+
+// CountUsers mocks base method.
+func (m *MockUserRepository) CountUsers(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUsers", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUsers indicates an expected call of CountUsers.
+func (mr *MockUserRepositoryMockRecorder) CountUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockUserRepository)(nil).CountUsers), ctx)
+}
+
 // CreateUser mocks base method.
 func (m *MockUserRepository) CreateUser(ctx context.Context, user entity.User) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
-	ret0, _ := ret[0].(int64)
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUserRepositoryMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepository)(nil).CreateUser), ctx, user)
+}
+
+// GetUser mocks base method.
+func (m *MockUserRepository) GetUser(ctx context.Context, username string) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, username)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockUserRepositoryMockRecorder) GetUser(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserRepository)(nil).GetUser), ctx, username)
+}
+
+// GetUserBalance mocks base method.
+func (m *MockUserRepository) GetUserBalance(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserBalance", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserBalance indicates an expected call of GetUserBalance.
+func (mr *MockUserRepositoryMockRecorder) GetUserBalance(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserBalance", reflect.TypeOf((*MockUserRepository)(nil).GetUserBalance), ctx, userID)
+}
+
+// GetUserBalanceForUpdate mocks base method.
+func (m *MockUserRepository) GetUserBalanceForUpdate(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserBalanceForUpdate", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserBalanceForUpdate indicates an expected call of GetUserBalanceForUpdate.
+func (mr *MockUserRepositoryMockRecorder) GetUserBalanceForUpdate(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserBalanceForUpdate", reflect.TypeOf((*MockUserRepository)(nil).GetUserBalanceForUpdate), ctx, userID)
+}
+
+// GetUserByID mocks base method.
+func (m *MockUserRepository) GetUserByID(ctx context.Context, userID int64) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserRepositoryMockRecorder) GetUserByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), ctx, userID)
+}
+
+// SetCoins mocks base method.
+func (m *MockUserRepository) SetCoins(ctx context.Context, userID, from, to int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCoins", ctx, userID, from, to)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCoins indicates an expected call of SetCoins.
+func (mr *MockUserRepositoryMockRecorder) SetCoins(ctx, userID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCoins", reflect.TypeOf((*MockUserRepository)(nil).SetCoins), ctx, userID, from, to)
+}
+
+// SetTwoFactorEnabled mocks base method.
+func (m *MockUserRepository) SetTwoFactorEnabled(ctx context.Context, userID int64, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTwoFactorEnabled", ctx, userID, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTwoFactorEnabled indicates an expected call of SetTwoFactorEnabled.
+func (mr *MockUserRepositoryMockRecorder) SetTwoFactorEnabled(ctx, userID, enabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTwoFactorEnabled", reflect.TypeOf((*MockUserRepository)(nil).SetTwoFactorEnabled), ctx, userID, enabled)
+}
+
+// SetUserRole mocks base method.
+func (m *MockUserRepository) SetUserRole(ctx context.Context, username, role string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, username, role)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockUserRepositoryMockRecorder) SetUserRole(ctx, username, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockUserRepository)(nil).SetUserRole), ctx, username, role)
+}
+
+// UpdateCoins mocks base method.
+func (m *MockUserRepository) UpdateCoins(ctx context.Context, userID, amount int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCoins", ctx, userID, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCoins indicates an expected call of UpdateCoins.
+func (mr *MockUserRepositoryMockRecorder) UpdateCoins(ctx, userID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCoins", reflect.TypeOf((*MockUserRepository)(nil).UpdateCoins), ctx, userID, amount)
+}
+
+// UpdatePasswordHash mocks base method.
+func (m *MockUserRepository) UpdatePasswordHash(ctx context.Context, userID int64, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", ctx, userID, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockUserRepositoryMockRecorder) UpdatePasswordHash(ctx, userID, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockUserRepository)(nil).UpdatePasswordHash), ctx, userID, passwordHash)
+}
+
+// MockRefreshTokenRepository is a mock of RefreshTokenRepository interface.
+type MockRefreshTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefreshTokenRepositoryMockRecorder
+}
+
+// MockRefreshTokenRepositoryMockRecorder is the mock recorder for MockRefreshTokenRepository.
+type MockRefreshTokenRepositoryMockRecorder struct {
+	mock *MockRefreshTokenRepository
+}
+
+// NewMockRefreshTokenRepository creates a new mock instance.
+func NewMockRefreshTokenRepository(ctrl *gomock.Controller) *MockRefreshTokenRepository {
+	mock := &MockRefreshTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockRefreshTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefreshTokenRepository) EXPECT() *MockRefreshTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockRefreshTokenRepository) CreateRefreshToken(ctx context.Context, token entity.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockRefreshTokenRepositoryMockRecorder) CreateRefreshToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockRefreshTokenRepository)(nil).CreateRefreshToken), ctx, token)
+}
+
+// GetRefreshTokenForUpdate mocks base method.
+func (m *MockRefreshTokenRepository) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (entity.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenForUpdate", ctx, tokenHash)
+	ret0, _ := ret[0].(entity.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenForUpdate indicates an expected call of GetRefreshTokenForUpdate.
+func (mr *MockRefreshTokenRepositoryMockRecorder) GetRefreshTokenForUpdate(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenForUpdate", reflect.TypeOf((*MockRefreshTokenRepository)(nil).GetRefreshTokenForUpdate), ctx, tokenHash)
+}
+
+// MarkRefreshTokenUsed mocks base method.
+func (m *MockRefreshTokenRepository) MarkRefreshTokenUsed(ctx context.Context, tokenID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRefreshTokenUsed", ctx, tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRefreshTokenUsed indicates an expected call of MarkRefreshTokenUsed.
+func (mr *MockRefreshTokenRepositoryMockRecorder) MarkRefreshTokenUsed(ctx, tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefreshTokenUsed", reflect.TypeOf((*MockRefreshTokenRepository)(nil).MarkRefreshTokenUsed), ctx, tokenID)
+}
+
+// RevokeRefreshTokenFamily mocks base method.
+func (m *MockRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokenFamily indicates an expected call of RevokeRefreshTokenFamily.
+func (mr *MockRefreshTokenRepositoryMockRecorder) RevokeRefreshTokenFamily(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamily", reflect.TypeOf((*MockRefreshTokenRepository)(nil).RevokeRefreshTokenFamily), ctx, familyID)
+}
+
+// RevokeUserRefreshTokens mocks base method.
+func (m *MockRefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserRefreshTokens", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserRefreshTokens indicates an expected call of RevokeUserRefreshTokens.
+func (mr *MockRefreshTokenRepositoryMockRecorder) RevokeUserRefreshTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserRefreshTokens", reflect.TypeOf((*MockRefreshTokenRepository)(nil).RevokeUserRefreshTokens), ctx, userID)
+}
+
+// MockTokenRevocationRepository is a mock of TokenRevocationRepository interface.
+type MockTokenRevocationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRevocationRepositoryMockRecorder
+}
+
+// MockTokenRevocationRepositoryMockRecorder is the mock recorder for MockTokenRevocationRepository.
+type MockTokenRevocationRepositoryMockRecorder struct {
+	mock *MockTokenRevocationRepository
+}
+
+// NewMockTokenRevocationRepository creates a new mock instance.
+func NewMockTokenRevocationRepository(ctrl *gomock.Controller) *MockTokenRevocationRepository {
+	mock := &MockTokenRevocationRepository{ctrl: ctrl}
+	mock.recorder = &MockTokenRevocationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRevocationRepository) EXPECT() *MockTokenRevocationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpiredRevokedTokens mocks base method.
+func (m *MockTokenRevocationRepository) DeleteExpiredRevokedTokens(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRevokedTokens", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredRevokedTokens indicates an expected call of DeleteExpiredRevokedTokens.
+func (mr *MockTokenRevocationRepositoryMockRecorder) DeleteExpiredRevokedTokens(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRevokedTokens", reflect.TypeOf((*MockTokenRevocationRepository)(nil).DeleteExpiredRevokedTokens), ctx)
+}
+
+// IsTokenRevoked mocks base method.
+func (m *MockTokenRevocationRepository) IsTokenRevoked(ctx context.Context, tokenID string, userID int64, issuedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenRevoked", ctx, tokenID, userID, issuedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockTokenRevocationRepositoryMockRecorder) IsTokenRevoked(ctx, tokenID, userID, issuedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockTokenRevocationRepository)(nil).IsTokenRevoked), ctx, tokenID, userID, issuedAt)
+}
+
+// RevokeToken mocks base method.
+func (m *MockTokenRevocationRepository) RevokeToken(ctx context.Context, tokenID string, userID int64, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", ctx, tokenID, userID, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockTokenRevocationRepositoryMockRecorder) RevokeToken(ctx, tokenID, userID, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockTokenRevocationRepository)(nil).RevokeToken), ctx, tokenID, userID, expiresAt)
+}
+
+// RevokeUserTokens mocks base method.
+func (m *MockTokenRevocationRepository) RevokeUserTokens(ctx context.Context, userID int64, revokedBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserTokens", ctx, userID, revokedBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserTokens indicates an expected call of RevokeUserTokens.
+func (mr *MockTokenRevocationRepositoryMockRecorder) RevokeUserTokens(ctx, userID, revokedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserTokens", reflect.TypeOf((*MockTokenRevocationRepository)(nil).RevokeUserTokens), ctx, userID, revokedBefore)
+}
+
+// MockLoginAttemptRepository is a mock of LoginAttemptRepository interface.
+type MockLoginAttemptRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptRepositoryMockRecorder
+}
+
+// MockLoginAttemptRepositoryMockRecorder is the mock recorder for MockLoginAttemptRepository.
+type MockLoginAttemptRepositoryMockRecorder struct {
+	mock *MockLoginAttemptRepository
+}
+
+// NewMockLoginAttemptRepository creates a new mock instance.
+func NewMockLoginAttemptRepository(ctrl *gomock.Controller) *MockLoginAttemptRepository {
+	mock := &MockLoginAttemptRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttemptRepository) EXPECT() *MockLoginAttemptRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetLoginLockout mocks base method.
+func (m *MockLoginAttemptRepository) GetLoginLockout(ctx context.Context, keys ...string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetLoginLockout", varargs...)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginLockout indicates an expected call of GetLoginLockout.
+func (mr *MockLoginAttemptRepositoryMockRecorder) GetLoginLockout(ctx interface{}, keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, keys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginLockout", reflect.TypeOf((*MockLoginAttemptRepository)(nil).GetLoginLockout), varargs...)
+}
+
+// LockLogin mocks base method.
+func (m *MockLoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLogin", ctx, key, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLogin indicates an expected call of LockLogin.
+func (mr *MockLoginAttemptRepositoryMockRecorder) LockLogin(ctx, key, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLogin", reflect.TypeOf((*MockLoginAttemptRepository)(nil).LockLogin), ctx, key, until)
+}
+
+// RegisterLoginFailure mocks base method.
+func (m *MockLoginAttemptRepository) RegisterLoginFailure(ctx context.Context, key string, now, windowStart time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterLoginFailure", ctx, key, now, windowStart)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterLoginFailure indicates an expected call of RegisterLoginFailure.
+func (mr *MockLoginAttemptRepositoryMockRecorder) RegisterLoginFailure(ctx, key, now, windowStart interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterLoginFailure", reflect.TypeOf((*MockLoginAttemptRepository)(nil).RegisterLoginFailure), ctx, key, now, windowStart)
+}
+
+// ResetLoginAttempts mocks base method.
+func (m *MockLoginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginAttempts", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginAttempts indicates an expected call of ResetLoginAttempts.
+func (mr *MockLoginAttemptRepositoryMockRecorder) ResetLoginAttempts(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginAttempts", reflect.TypeOf((*MockLoginAttemptRepository)(nil).ResetLoginAttempts), ctx, key)
+}
+
+// MockAPIKeyRepository is a mock of APIKeyRepository interface.
+type MockAPIKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyRepositoryMockRecorder
+}
+
+// MockAPIKeyRepositoryMockRecorder is the mock recorder for MockAPIKeyRepository.
+type MockAPIKeyRepositoryMockRecorder struct {
+	mock *MockAPIKeyRepository
+}
+
+// NewMockAPIKeyRepository creates a new mock instance.
+func NewMockAPIKeyRepository(ctrl *gomock.Controller) *MockAPIKeyRepository {
+	mock := &MockAPIKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyRepository) EXPECT() *MockAPIKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, key)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) CreateAPIKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).CreateAPIKey), ctx, key)
+}
+
+// GetAPIKeyByPrefix mocks base method.
+func (m *MockAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByPrefix", ctx, prefix)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByPrefix indicates an expected call of GetAPIKeyByPrefix.
+func (mr *MockAPIKeyRepositoryMockRecorder) GetAPIKeyByPrefix(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByPrefix", reflect.TypeOf((*MockAPIKeyRepository)(nil).GetAPIKeyByPrefix), ctx, prefix)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAPIKeyRepository) ListAPIKeys(ctx context.Context, userID int64) ([]entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, userID)
+	ret0, _ := ret[0].([]entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAPIKeyRepositoryMockRecorder) ListAPIKeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAPIKeyRepository)(nil).ListAPIKeys), ctx, userID)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, userID, keyID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, userID, keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) RevokeAPIKey(ctx, userID, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).RevokeAPIKey), ctx, userID, keyID)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockAPIKeyRepository) TouchAPIKey(ctx context.Context, keyID int64, usedAt time.Time, interval time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", ctx, keyID, usedAt, interval)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) TouchAPIKey(ctx, keyID, usedAt, interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).TouchAPIKey), ctx, keyID, usedAt, interval)
+}
+
+// MockTwoFactorRepository is a mock of TwoFactorRepository interface.
+type MockTwoFactorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorRepositoryMockRecorder
+}
+
+// MockTwoFactorRepositoryMockRecorder is the mock recorder for MockTwoFactorRepository.
+type MockTwoFactorRepositoryMockRecorder struct {
+	mock *MockTwoFactorRepository
+}
+
+// NewMockTwoFactorRepository creates a new mock instance.
+func NewMockTwoFactorRepository(ctrl *gomock.Controller) *MockTwoFactorRepository {
+	mock := &MockTwoFactorRepository{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorRepository) EXPECT() *MockTwoFactorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockTwoFactorRepository) ConfirmTOTP(ctx context.Context, userID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockTwoFactorRepositoryMockRecorder) ConfirmTOTP(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockTwoFactorRepository)(nil).ConfirmTOTP), ctx, userID, step)
+}
+
+// DeleteTOTP mocks base method.
+func (m *MockTwoFactorRepository) DeleteTOTP(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTP", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTP indicates an expected call of DeleteTOTP.
+func (mr *MockTwoFactorRepositoryMockRecorder) DeleteTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTP", reflect.TypeOf((*MockTwoFactorRepository)(nil).DeleteTOTP), ctx, userID)
+}
+
+// GetTOTP mocks base method.
+func (m *MockTwoFactorRepository) GetTOTP(ctx context.Context, userID int64) (entity.TOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, userID)
+	ret0, _ := ret[0].(entity.TOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockTwoFactorRepositoryMockRecorder) GetTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockTwoFactorRepository)(nil).GetTOTP), ctx, userID)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, userID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockTwoFactorRepositoryMockRecorder) ReplaceRecoveryCodes(ctx, userID, codeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockTwoFactorRepository)(nil).ReplaceRecoveryCodes), ctx, userID, codeHashes)
+}
+
+// SaveTOTPSecret mocks base method.
+func (m *MockTwoFactorRepository) SaveTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTPSecret", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTPSecret indicates an expected call of SaveTOTPSecret.
+func (mr *MockTwoFactorRepositoryMockRecorder) SaveTOTPSecret(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTPSecret", reflect.TypeOf((*MockTwoFactorRepository)(nil).SaveTOTPSecret), ctx, userID, secret)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockTwoFactorRepositoryMockRecorder) UseRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTwoFactorRepository)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockTwoFactorRepository) UseTOTPStep(ctx context.Context, userID, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, userID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockTwoFactorRepositoryMockRecorder) UseTOTPStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockTwoFactorRepository)(nil).UseTOTPStep), ctx, userID, step)
+}
+
+// MockIdempotencyRepository is a mock of IdempotencyRepository interface.
+type MockIdempotencyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyRepositoryMockRecorder
+}
+
+// MockIdempotencyRepositoryMockRecorder is the mock recorder for MockIdempotencyRepository.
+type MockIdempotencyRepositoryMockRecorder struct {
+	mock *MockIdempotencyRepository
+}
+
+// NewMockIdempotencyRepository creates a new mock instance.
+func NewMockIdempotencyRepository(ctrl *gomock.Controller) *MockIdempotencyRepository {
+	mock := &MockIdempotencyRepository{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyRepository) EXPECT() *MockIdempotencyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateIdempotencyKey mocks base method.
+func (m *MockIdempotencyRepository) CreateIdempotencyKey(ctx context.Context, userID int64, key, requestHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", ctx, userID, key, requestHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockIdempotencyRepositoryMockRecorder) CreateIdempotencyKey(ctx, userID, key, requestHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockIdempotencyRepository)(nil).CreateIdempotencyKey), ctx, userID, key, requestHash)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockIdempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, userID int64, createdBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", ctx, userID, createdBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockIdempotencyRepositoryMockRecorder) DeleteExpiredIdempotencyKeys(ctx, userID, createdBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockIdempotencyRepository)(nil).DeleteExpiredIdempotencyKeys), ctx, userID, createdBefore)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockIdempotencyRepository) GetIdempotencyKey(ctx context.Context, userID int64, key string) (entity.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", ctx, userID, key)
+	ret0, _ := ret[0].(entity.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockIdempotencyRepositoryMockRecorder) GetIdempotencyKey(ctx, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockIdempotencyRepository)(nil).GetIdempotencyKey), ctx, userID, key)
+}
+
+// SaveIdempotentResponse mocks base method.
+func (m *MockIdempotencyRepository) SaveIdempotentResponse(ctx context.Context, userID int64, key string, statusCode int, body []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIdempotentResponse", ctx, userID, key, statusCode, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIdempotentResponse indicates an expected call of SaveIdempotentResponse.
+func (mr *MockIdempotencyRepositoryMockRecorder) SaveIdempotentResponse(ctx, userID, key, statusCode, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdempotentResponse", reflect.TypeOf((*MockIdempotencyRepository)(nil).SaveIdempotentResponse), ctx, userID, key, statusCode, body)
+}
+
+// MockTransactionRepository is a mock of TransactionRepository interface.
+type MockTransactionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionRepositoryMockRecorder
+}
+
+// MockTransactionRepositoryMockRecorder is the mock recorder for MockTransactionRepository.
+type MockTransactionRepositoryMockRecorder struct {
+	mock *MockTransactionRepository
+}
+
+// NewMockTransactionRepository creates a new mock instance.
+func NewMockTransactionRepository(ctrl *gomock.Controller) *MockTransactionRepository {
+	mock := &MockTransactionRepository{ctrl: ctrl}
+	mock.recorder = &MockTransactionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactionRepository) EXPECT() *MockTransactionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetHistory mocks base method.
+func (m *MockTransactionRepository) GetHistory(ctx context.Context, userID int64, filter entity.HistoryFilter) ([]entity.TransactionDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, userID, filter)
+	ret0, _ := ret[0].([]entity.TransactionDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockTransactionRepositoryMockRecorder) GetHistory(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockTransactionRepository)(nil).GetHistory), ctx, userID, filter)
+}
+
+// GetReceivedTotals mocks base method.
+func (m *MockTransactionRepository) GetReceivedTotals(ctx context.Context, userID int64) ([]entity.CounterpartyTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReceivedTotals", ctx, userID)
+	ret0, _ := ret[0].([]entity.CounterpartyTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReceivedTotals indicates an expected call of GetReceivedTotals.
+func (mr *MockTransactionRepositoryMockRecorder) GetReceivedTotals(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceivedTotals", reflect.TypeOf((*MockTransactionRepository)(nil).GetReceivedTotals), ctx, userID)
+}
+
+// GetReceivedTransactions mocks base method.
+func (m *MockTransactionRepository) GetReceivedTransactions(ctx context.Context, userID int64, limit int) ([]entity.TransactionDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReceivedTransactions", ctx, userID, limit)
+	ret0, _ := ret[0].([]entity.TransactionDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReceivedTransactions indicates an expected call of GetReceivedTransactions.
+func (mr *MockTransactionRepositoryMockRecorder) GetReceivedTransactions(ctx, userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceivedTransactions", reflect.TypeOf((*MockTransactionRepository)(nil).GetReceivedTransactions), ctx, userID, limit)
+}
+
+// GetSentTotals mocks base method.
+func (m *MockTransactionRepository) GetSentTotals(ctx context.Context, userID int64) ([]entity.CounterpartyTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSentTotals", ctx, userID)
+	ret0, _ := ret[0].([]entity.CounterpartyTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSentTotals indicates an expected call of GetSentTotals.
+func (mr *MockTransactionRepositoryMockRecorder) GetSentTotals(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSentTotals", reflect.TypeOf((*MockTransactionRepository)(nil).GetSentTotals), ctx, userID)
+}
+
+// GetSentTransactions mocks base method.
+func (m *MockTransactionRepository) GetSentTransactions(ctx context.Context, userID int64, limit int) ([]entity.TransactionDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSentTransactions", ctx, userID, limit)
+	ret0, _ := ret[0].([]entity.TransactionDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSentTransactions indicates an expected call of GetSentTransactions.
+func (mr *MockTransactionRepositoryMockRecorder) GetSentTransactions(ctx, userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSentTransactions", reflect.TypeOf((*MockTransactionRepository)(nil).GetSentTransactions), ctx, userID, limit)
+}
+
+// GetTransaction mocks base method.
+func (m *MockTransactionRepository) GetTransaction(ctx context.Context, transactionID int64) (entity.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", ctx, transactionID)
+	ret0, _ := ret[0].(entity.Transaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockUserRepositoryMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockTransactionRepositoryMockRecorder) GetTransaction(ctx, transactionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepository)(nil).CreateUser), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockTransactionRepository)(nil).GetTransaction), ctx, transactionID)
 }
 
-// GetUser mocks base method.
-func (m *MockUserRepository) GetUser(ctx context.Context, username string) (entity.User, error) {
+// InsertTransaction mocks base method.
+func (m *MockTransactionRepository) InsertTransaction(ctx context.Context, transaction entity.Transaction) (entity.Transaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", ctx, username)
-	ret0, _ := ret[0].(entity.User)
+	ret := m.ctrl.Call(m, "InsertTransaction", ctx, transaction)
+	ret0, _ := ret[0].(entity.Transaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockUserRepositoryMockRecorder) GetUser(ctx, username interface{}) *gomock.Call {
+// InsertTransaction indicates an expected call of InsertTransaction.
+func (mr *MockTransactionRepositoryMockRecorder) InsertTransaction(ctx, transaction interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserRepository)(nil).GetUser), ctx, username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTransaction", reflect.TypeOf((*MockTransactionRepository)(nil).InsertTransaction), ctx, transaction)
 }
 
-// GetUserBalance mocks base method.
-func (m *MockUserRepository) GetUserBalance(ctx context.Context, userID int64) (int64, error) {
+// MockPurchaseRepository is a mock of PurchaseRepository interface.
+type MockPurchaseRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPurchaseRepositoryMockRecorder
+}
+
+// MockPurchaseRepositoryMockRecorder is the mock recorder for MockPurchaseRepository.
+type MockPurchaseRepositoryMockRecorder struct {
+	mock *MockPurchaseRepository
+}
+
+// NewMockPurchaseRepository creates a new mock instance.
+func NewMockPurchaseRepository(ctrl *gomock.Controller) *MockPurchaseRepository {
+	mock := &MockPurchaseRepository{ctrl: ctrl}
+	mock.recorder = &MockPurchaseRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPurchaseRepository) EXPECT() *MockPurchaseRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetPurchaseByTransaction mocks base method.
+func (m *MockPurchaseRepository) GetPurchaseByTransaction(ctx context.Context, transactionID int64) (entity.Purchase, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserBalance", ctx, userID)
+	ret := m.ctrl.Call(m, "GetPurchaseByTransaction", ctx, transactionID)
+	ret0, _ := ret[0].(entity.Purchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPurchaseByTransaction indicates an expected call of GetPurchaseByTransaction.
+func (mr *MockPurchaseRepositoryMockRecorder) GetPurchaseByTransaction(ctx, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurchaseByTransaction", reflect.TypeOf((*MockPurchaseRepository)(nil).GetPurchaseByTransaction), ctx, transactionID)
+}
+
+// GetUserPurchases mocks base method.
+func (m *MockPurchaseRepository) GetUserPurchases(ctx context.Context, userID int64, limit int) ([]entity.PurchaseDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPurchases", ctx, userID, limit)
+	ret0, _ := ret[0].([]entity.PurchaseDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserPurchases indicates an expected call of GetUserPurchases.
+func (mr *MockPurchaseRepositoryMockRecorder) GetUserPurchases(ctx, userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPurchases", reflect.TypeOf((*MockPurchaseRepository)(nil).GetUserPurchases), ctx, userID, limit)
+}
+
+// InsertPurchase mocks base method.
+func (m *MockPurchaseRepository) InsertPurchase(ctx context.Context, purchase entity.Purchase) (entity.Purchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPurchase", ctx, purchase)
+	ret0, _ := ret[0].(entity.Purchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertPurchase indicates an expected call of InsertPurchase.
+func (mr *MockPurchaseRepositoryMockRecorder) InsertPurchase(ctx, purchase interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPurchase", reflect.TypeOf((*MockPurchaseRepository)(nil).InsertPurchase), ctx, purchase)
+}
+
+// MockCoinAdjustmentRepository is a mock of CoinAdjustmentRepository interface.
+type MockCoinAdjustmentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCoinAdjustmentRepositoryMockRecorder
+}
+
+// MockCoinAdjustmentRepositoryMockRecorder is the mock recorder for MockCoinAdjustmentRepository.
+type MockCoinAdjustmentRepositoryMockRecorder struct {
+	mock *MockCoinAdjustmentRepository
+}
+
+// NewMockCoinAdjustmentRepository creates a new mock instance.
+func NewMockCoinAdjustmentRepository(ctrl *gomock.Controller) *MockCoinAdjustmentRepository {
+	mock := &MockCoinAdjustmentRepository{ctrl: ctrl}
+	mock.recorder = &MockCoinAdjustmentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCoinAdjustmentRepository) EXPECT() *MockCoinAdjustmentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// InsertCoinAdjustment mocks base method.
+func (m *MockCoinAdjustmentRepository) InsertCoinAdjustment(ctx context.Context, adjustment entity.CoinAdjustment) (entity.CoinAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertCoinAdjustment", ctx, adjustment)
+	ret0, _ := ret[0].(entity.CoinAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertCoinAdjustment indicates an expected call of InsertCoinAdjustment.
+func (mr *MockCoinAdjustmentRepositoryMockRecorder) InsertCoinAdjustment(ctx, adjustment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCoinAdjustment", reflect.TypeOf((*MockCoinAdjustmentRepository)(nil).InsertCoinAdjustment), ctx, adjustment)
+}
+
+// MockAllowanceRepository is a mock of AllowanceRepository interface.
+type MockAllowanceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAllowanceRepositoryMockRecorder
+}
+
+// MockAllowanceRepositoryMockRecorder is the mock recorder for MockAllowanceRepository.
+type MockAllowanceRepositoryMockRecorder struct {
+	mock *MockAllowanceRepository
+}
+
+// NewMockAllowanceRepository creates a new mock instance.
+func NewMockAllowanceRepository(ctrl *gomock.Controller) *MockAllowanceRepository {
+	mock := &MockAllowanceRepository{ctrl: ctrl}
+	mock.recorder = &MockAllowanceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAllowanceRepository) EXPECT() *MockAllowanceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddAllowanceRecipients mocks base method.
+func (m *MockAllowanceRepository) AddAllowanceRecipients(ctx context.Context, allowanceID int64, userIDs []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAllowanceRecipients", ctx, allowanceID, userIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAllowanceRecipients indicates an expected call of AddAllowanceRecipients.
+func (mr *MockAllowanceRepositoryMockRecorder) AddAllowanceRecipients(ctx, allowanceID, userIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAllowanceRecipients", reflect.TypeOf((*MockAllowanceRepository)(nil).AddAllowanceRecipients), ctx, allowanceID, userIDs)
+}
+
+// ClaimAllowanceRun mocks base method.
+func (m *MockAllowanceRepository) ClaimAllowanceRun(ctx context.Context, allowanceID int64, periodStart time.Time) (int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimAllowanceRun", ctx, allowanceID, periodStart)
 	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimAllowanceRun indicates an expected call of ClaimAllowanceRun.
+func (mr *MockAllowanceRepositoryMockRecorder) ClaimAllowanceRun(ctx, allowanceID, periodStart interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimAllowanceRun", reflect.TypeOf((*MockAllowanceRepository)(nil).ClaimAllowanceRun), ctx, allowanceID, periodStart)
+}
+
+// CompleteAllowanceRun mocks base method.
+func (m *MockAllowanceRepository) CompleteAllowanceRun(ctx context.Context, runID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteAllowanceRun", ctx, runID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteAllowanceRun indicates an expected call of CompleteAllowanceRun.
+func (mr *MockAllowanceRepositoryMockRecorder) CompleteAllowanceRun(ctx, runID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteAllowanceRun", reflect.TypeOf((*MockAllowanceRepository)(nil).CompleteAllowanceRun), ctx, runID)
+}
+
+// CreateAllowance mocks base method.
+func (m *MockAllowanceRepository) CreateAllowance(ctx context.Context, allowance entity.Allowance) (entity.Allowance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAllowance", ctx, allowance)
+	ret0, _ := ret[0].(entity.Allowance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserBalance indicates an expected call of GetUserBalance.
-func (mr *MockUserRepositoryMockRecorder) GetUserBalance(ctx, userID interface{}) *gomock.Call {
+// CreateAllowance indicates an expected call of CreateAllowance.
+func (mr *MockAllowanceRepositoryMockRecorder) CreateAllowance(ctx, allowance interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserBalance", reflect.TypeOf((*MockUserRepository)(nil).GetUserBalance), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAllowance", reflect.TypeOf((*MockAllowanceRepository)(nil).CreateAllowance), ctx, allowance)
 }
 
-// UpdateCoins mocks base method.
-func (m *MockUserRepository) UpdateCoins(ctx context.Context, userID, amount int64) error {
+// DisableAllowance mocks base method.
+func (m *MockAllowanceRepository) DisableAllowance(ctx context.Context, allowanceID int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateCoins", ctx, userID, amount)
+	ret := m.ctrl.Call(m, "DisableAllowance", ctx, allowanceID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateCoins indicates an expected call of UpdateCoins.
-func (mr *MockUserRepositoryMockRecorder) UpdateCoins(ctx, userID, amount interface{}) *gomock.Call {
+// DisableAllowance indicates an expected call of DisableAllowance.
+func (mr *MockAllowanceRepositoryMockRecorder) DisableAllowance(ctx, allowanceID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCoins", reflect.TypeOf((*MockUserRepository)(nil).UpdateCoins), ctx, userID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableAllowance", reflect.TypeOf((*MockAllowanceRepository)(nil).DisableAllowance), ctx, allowanceID)
 }
 
-// MockTransactionRepository is a mock of TransactionRepository interface.
-type MockTransactionRepository struct {
+// GetPendingAllowanceRecipients mocks base method.
+func (m *MockAllowanceRepository) GetPendingAllowanceRecipients(ctx context.Context, allowanceID, runID int64, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingAllowanceRecipients", ctx, allowanceID, runID, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingAllowanceRecipients indicates an expected call of GetPendingAllowanceRecipients.
+func (mr *MockAllowanceRepositoryMockRecorder) GetPendingAllowanceRecipients(ctx, allowanceID, runID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingAllowanceRecipients", reflect.TypeOf((*MockAllowanceRepository)(nil).GetPendingAllowanceRecipients), ctx, allowanceID, runID, limit)
+}
+
+// InsertAllowancePayout mocks base method.
+func (m *MockAllowanceRepository) InsertAllowancePayout(ctx context.Context, runID, userID, transactionID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAllowancePayout", ctx, runID, userID, transactionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertAllowancePayout indicates an expected call of InsertAllowancePayout.
+func (mr *MockAllowanceRepositoryMockRecorder) InsertAllowancePayout(ctx, runID, userID, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAllowancePayout", reflect.TypeOf((*MockAllowanceRepository)(nil).InsertAllowancePayout), ctx, runID, userID, transactionID)
+}
+
+// ListAllowances mocks base method.
+func (m *MockAllowanceRepository) ListAllowances(ctx context.Context) ([]entity.Allowance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllowances", ctx)
+	ret0, _ := ret[0].([]entity.Allowance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllowances indicates an expected call of ListAllowances.
+func (mr *MockAllowanceRepositoryMockRecorder) ListAllowances(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllowances", reflect.TypeOf((*MockAllowanceRepository)(nil).ListAllowances), ctx)
+}
+
+// LockAllowanceRun mocks base method.
+func (m *MockAllowanceRepository) LockAllowanceRun(ctx context.Context, runID int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAllowanceRun", ctx, runID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockAllowanceRun indicates an expected call of LockAllowanceRun.
+func (mr *MockAllowanceRepositoryMockRecorder) LockAllowanceRun(ctx, runID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAllowanceRun", reflect.TypeOf((*MockAllowanceRepository)(nil).LockAllowanceRun), ctx, runID)
+}
+
+// MockCoinLotRepository is a mock of CoinLotRepository interface.
+type MockCoinLotRepository struct {
 	ctrl     *gomock.Controller
-	recorder *MockTransactionRepositoryMockRecorder
+	recorder *MockCoinLotRepositoryMockRecorder
 }
 
-// MockTransactionRepositoryMockRecorder is the mock recorder for MockTransactionRepository.
-type MockTransactionRepositoryMockRecorder struct {
-	mock *MockTransactionRepository
+// MockCoinLotRepositoryMockRecorder is the mock recorder for MockCoinLotRepository.
+type MockCoinLotRepositoryMockRecorder struct {
+	mock *MockCoinLotRepository
 }
 
-// NewMockTransactionRepository creates a new mock instance.
-func NewMockTransactionRepository(ctrl *gomock.Controller) *MockTransactionRepository {
-	mock := &MockTransactionRepository{ctrl: ctrl}
-	mock.recorder = &MockTransactionRepositoryMockRecorder{mock}
+// NewMockCoinLotRepository creates a new mock instance.
+func NewMockCoinLotRepository(ctrl *gomock.Controller) *MockCoinLotRepository {
+	mock := &MockCoinLotRepository{ctrl: ctrl}
+	mock.recorder = &MockCoinLotRepositoryMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockTransactionRepository) EXPECT() *MockTransactionRepositoryMockRecorder {
+func (m *MockCoinLotRepository) EXPECT() *MockCoinLotRepositoryMockRecorder {
 	return m.recorder
 }
 
-// GetReceivedTransactions mocks base method.
-func (m *MockTransactionRepository) GetReceivedTransactions(ctx context.Context, userID int64) ([]entity.TransactionDetail, error) {
+// ConsumeCoinLots mocks base method.
+func (m *MockCoinLotRepository) ConsumeCoinLots(ctx context.Context, userID, amount int64, now time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetReceivedTransactions", ctx, userID)
-	ret0, _ := ret[0].([]entity.TransactionDetail)
+	ret := m.ctrl.Call(m, "ConsumeCoinLots", ctx, userID, amount, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeCoinLots indicates an expected call of ConsumeCoinLots.
+func (mr *MockCoinLotRepositoryMockRecorder) ConsumeCoinLots(ctx, userID, amount, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeCoinLots", reflect.TypeOf((*MockCoinLotRepository)(nil).ConsumeCoinLots), ctx, userID, amount, now)
+}
+
+// ExpireCoinLots mocks base method.
+func (m *MockCoinLotRepository) ExpireCoinLots(ctx context.Context, userID int64, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireCoinLots", ctx, userID, now)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetReceivedTransactions indicates an expected call of GetReceivedTransactions.
-func (mr *MockTransactionRepositoryMockRecorder) GetReceivedTransactions(ctx, userID interface{}) *gomock.Call {
+// ExpireCoinLots indicates an expected call of ExpireCoinLots.
+func (mr *MockCoinLotRepositoryMockRecorder) ExpireCoinLots(ctx, userID, now interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceivedTransactions", reflect.TypeOf((*MockTransactionRepository)(nil).GetReceivedTransactions), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireCoinLots", reflect.TypeOf((*MockCoinLotRepository)(nil).ExpireCoinLots), ctx, userID, now)
 }
 
-// GetSentTransactions mocks base method.
-func (m *MockTransactionRepository) GetSentTransactions(ctx context.Context, userID int64) ([]entity.TransactionDetail, error) {
+// GetUpcomingExpirations mocks base method.
+func (m *MockCoinLotRepository) GetUpcomingExpirations(ctx context.Context, userID int64, now time.Time) ([]entity.CoinExpiration, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSentTransactions", ctx, userID)
-	ret0, _ := ret[0].([]entity.TransactionDetail)
+	ret := m.ctrl.Call(m, "GetUpcomingExpirations", ctx, userID, now)
+	ret0, _ := ret[0].([]entity.CoinExpiration)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSentTransactions indicates an expected call of GetSentTransactions.
-func (mr *MockTransactionRepositoryMockRecorder) GetSentTransactions(ctx, userID interface{}) *gomock.Call {
+// GetUpcomingExpirations indicates an expected call of GetUpcomingExpirations.
+func (mr *MockCoinLotRepositoryMockRecorder) GetUpcomingExpirations(ctx, userID, now interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSentTransactions", reflect.TypeOf((*MockTransactionRepository)(nil).GetSentTransactions), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpcomingExpirations", reflect.TypeOf((*MockCoinLotRepository)(nil).GetUpcomingExpirations), ctx, userID, now)
 }
 
-// InsertTransaction mocks base method.
-func (m *MockTransactionRepository) InsertTransaction(ctx context.Context, fromUserID, toUserID, amount int64) error {
+// GetUsersWithExpiredLots mocks base method.
+func (m *MockCoinLotRepository) GetUsersWithExpiredLots(ctx context.Context, now time.Time, limit int) ([]int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertTransaction", ctx, fromUserID, toUserID, amount)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetUsersWithExpiredLots", ctx, now, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// InsertTransaction indicates an expected call of InsertTransaction.
-func (mr *MockTransactionRepositoryMockRecorder) InsertTransaction(ctx, fromUserID, toUserID, amount interface{}) *gomock.Call {
+// GetUsersWithExpiredLots indicates an expected call of GetUsersWithExpiredLots.
+func (mr *MockCoinLotRepositoryMockRecorder) GetUsersWithExpiredLots(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersWithExpiredLots", reflect.TypeOf((*MockCoinLotRepository)(nil).GetUsersWithExpiredLots), ctx, now, limit)
+}
+
+// InsertCoinLot mocks base method.
+func (m *MockCoinLotRepository) InsertCoinLot(ctx context.Context, lot entity.CoinLot) (entity.CoinLot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertCoinLot", ctx, lot)
+	ret0, _ := ret[0].(entity.CoinLot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertCoinLot indicates an expected call of InsertCoinLot.
+func (mr *MockCoinLotRepositoryMockRecorder) InsertCoinLot(ctx, lot interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTransaction", reflect.TypeOf((*MockTransactionRepository)(nil).InsertTransaction), ctx, fromUserID, toUserID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCoinLot", reflect.TypeOf((*MockCoinLotRepository)(nil).InsertCoinLot), ctx, lot)
+}
+
+// MockLedgerRepository is a mock of LedgerRepository interface.
+type MockLedgerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerRepositoryMockRecorder
+}
+
+// MockLedgerRepositoryMockRecorder is the mock recorder for MockLedgerRepository.
+type MockLedgerRepositoryMockRecorder struct {
+	mock *MockLedgerRepository
+}
+
+// NewMockLedgerRepository creates a new mock instance.
+func NewMockLedgerRepository(ctrl *gomock.Controller) *MockLedgerRepository {
+	mock := &MockLedgerRepository{ctrl: ctrl}
+	mock.recorder = &MockLedgerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerRepository) EXPECT() *MockLedgerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateJournalEntry mocks base method.
+func (m *MockLedgerRepository) CreateJournalEntry(ctx context.Context, entry entity.JournalEntry) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJournalEntry", ctx, entry)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJournalEntry indicates an expected call of CreateJournalEntry.
+func (mr *MockLedgerRepositoryMockRecorder) CreateJournalEntry(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJournalEntry", reflect.TypeOf((*MockLedgerRepository)(nil).CreateJournalEntry), ctx, entry)
+}
+
+// CreateUserAccount mocks base method.
+func (m *MockLedgerRepository) CreateUserAccount(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserAccount", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserAccount indicates an expected call of CreateUserAccount.
+func (mr *MockLedgerRepositoryMockRecorder) CreateUserAccount(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserAccount", reflect.TypeOf((*MockLedgerRepository)(nil).CreateUserAccount), ctx, userID)
+}
+
+// GetBalanceDrifts mocks base method.
+func (m *MockLedgerRepository) GetBalanceDrifts(ctx context.Context) ([]entity.BalanceDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceDrifts", ctx)
+	ret0, _ := ret[0].([]entity.BalanceDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceDrifts indicates an expected call of GetBalanceDrifts.
+func (mr *MockLedgerRepositoryMockRecorder) GetBalanceDrifts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceDrifts", reflect.TypeOf((*MockLedgerRepository)(nil).GetBalanceDrifts), ctx)
+}
+
+// GetSystemAccountID mocks base method.
+func (m *MockLedgerRepository) GetSystemAccountID(ctx context.Context, accountType string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSystemAccountID", ctx, accountType)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSystemAccountID indicates an expected call of GetSystemAccountID.
+func (mr *MockLedgerRepositoryMockRecorder) GetSystemAccountID(ctx, accountType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemAccountID", reflect.TypeOf((*MockLedgerRepository)(nil).GetSystemAccountID), ctx, accountType)
+}
+
+// GetUserAccountID mocks base method.
+func (m *MockLedgerRepository) GetUserAccountID(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAccountID", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAccountID indicates an expected call of GetUserAccountID.
+func (mr *MockLedgerRepositoryMockRecorder) GetUserAccountID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAccountID", reflect.TypeOf((*MockLedgerRepository)(nil).GetUserAccountID), ctx, userID)
 }
 
 // MockInventoryRepository is a mock of InventoryRepository interface.
@@ -243,6 +1439,20 @@ func (mr *MockInventoryRepositoryMockRecorder) InsertInventoryItem(ctx, userID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertInventoryItem", reflect.TypeOf((*MockInventoryRepository)(nil).InsertInventoryItem), ctx, userID, merchID)
 }
 
+// RemoveInventoryItems mocks base method.
+func (m *MockInventoryRepository) RemoveInventoryItems(ctx context.Context, userID, merchID int64, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveInventoryItems", ctx, userID, merchID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveInventoryItems indicates an expected call of RemoveInventoryItems.
+func (mr *MockInventoryRepositoryMockRecorder) RemoveInventoryItems(ctx, userID, merchID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveInventoryItems", reflect.TypeOf((*MockInventoryRepository)(nil).RemoveInventoryItems), ctx, userID, merchID, quantity)
+}
+
 // UpdateInventoryItem mocks base method.
 func (m *MockInventoryRepository) UpdateInventoryItem(ctx context.Context, userID, merchID int64) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+type PurchasePostgres struct {
+	db     *sqlx.DB
+	getter *trmsqlx.CtxGetter
+}
+
+func NewPurchasePostgres(db *sqlx.DB) *PurchasePostgres {
+	return &PurchasePostgres{
+		db:     db,
+		getter: trmsqlx.DefaultCtxGetter,
+	}
+}
+
+func (r *PurchasePostgres) InsertPurchase(ctx context.Context, purchase entity.Purchase) (entity.Purchase, error) {
+	query := `
+		INSERT INTO purchases (user_id, merch_id, unit_price, quantity, transaction_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	row := r.getter.DefaultTrOrDB(ctx, r.db).QueryRowContext(ctx, query,
+		purchase.UserID, purchase.MerchID, purchase.UnitPrice, purchase.Quantity, purchase.TransactionID)
+	if err := row.Scan(&purchase.ID, &purchase.CreatedAt); err != nil {
+		return entity.Purchase{}, err
+	}
+
+	return purchase, nil
+}
+
+// GetUserPurchases возвращает покупки пользователя, начиная с последних. limit = 0 снимает ограничение.
+func (r *PurchasePostgres) GetUserPurchases(ctx context.Context, userID int64, limit int) ([]entity.PurchaseDetail, error) {
+	var purchases []entity.PurchaseDetail
+	query := `
+		SELECT p.transaction_id AS id, mi.item_type AS item, p.unit_price, p.quantity,
+			p.unit_price * p.quantity AS amount, p.created_at
+		FROM purchases AS p
+		JOIN merch_items AS mi ON p.merch_id = mi.id
+		WHERE p.user_id = $1
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT NULLIF($2, 0)`
+
+	return purchases, r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &purchases, query, userID, limit)
+}
+
+// GetPurchaseByTransaction возвращает покупку по ID операции списания.
+func (r *PurchasePostgres) GetPurchaseByTransaction(ctx context.Context, transactionID int64) (entity.Purchase, error) {
+	var purchase entity.Purchase
+	query := `
+		SELECT id, user_id, merch_id, unit_price, quantity, transaction_id, created_at
+		FROM purchases
+		WHERE transaction_id = $1`
+
+	return purchase, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &purchase, query, transactionID)
+}
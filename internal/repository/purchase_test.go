@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func TestPurchasePostgres_InsertPurchase(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewPurchasePostgres(sqlxDB)
+
+	createdAt := time.Now()
+	purchase := entity.Purchase{UserID: 1, MerchID: 3, UnitPrice: 80, Quantity: 1, TransactionID: 7}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+		wantData     entity.Purchase
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO purchases \(user_id, merch_id, unit_price, quantity, transaction_id\)`).
+					WithArgs(int64(1), int64(3), int64(80), 1, int64(7)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(5), createdAt))
+			},
+			wantError: nil,
+			wantData:  entity.Purchase{ID: 5, UserID: 1, MerchID: 3, UnitPrice: 80, Quantity: 1, TransactionID: 7, CreatedAt: createdAt},
+		},
+		{
+			name: "Query Error",
+			mockBehavior: func() {
+				mock.ExpectQuery(`INSERT INTO purchases`).
+					WillReturnError(errors.New("insert error"))
+			},
+			wantError: errors.New("insert error"),
+			wantData:  entity.Purchase{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			got, err := repo.InsertPurchase(context.Background(), purchase)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantData, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPurchasePostgres_GetUserPurchases(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewPurchasePostgres(sqlxDB)
+
+	createdAt := time.Now()
+
+	mock.ExpectQuery(`SELECT p.transaction_id AS id, mi.item_type AS item, p.unit_price, p.quantity,
+			p.unit_price \* p.quantity AS amount, p.created_at FROM purchases AS p
+			JOIN merch_items AS mi ON p.merch_id = mi.id WHERE p.user_id = \$1
+			ORDER BY p.created_at DESC, p.id DESC LIMIT NULLIF\(\$2, 0\)`).
+		WithArgs(int64(1), 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "item", "unit_price", "quantity", "amount", "created_at"}).
+			AddRow(int64(9), "cup", int64(20), 2, int64(40), createdAt))
+
+	data, err := repo.GetUserPurchases(context.Background(), 1, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.PurchaseDetail{
+		{ID: 9, Item: "cup", UnitPrice: 20, Quantity: 2, Amount: 40, CreatedAt: createdAt},
+	}, data)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurchasePostgres_GetPurchaseByTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewPurchasePostgres(sqlxDB)
+
+	createdAt := time.Now()
+	mock.ExpectQuery(`SELECT id, user_id, merch_id, unit_price, quantity, transaction_id, created_at
+		FROM purchases WHERE transaction_id = \$1`).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "merch_id", "unit_price", "quantity", "transaction_id", "created_at"}).
+			AddRow(int64(3), int64(1), int64(2), int64(80), 1, int64(7), createdAt))
+
+	purchase, err := repo.GetPurchaseByTransaction(context.Background(), 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.Purchase{ID: 3, UserID: 1, MerchID: 2, UnitPrice: 80, Quantity: 1, TransactionID: 7, CreatedAt: createdAt}, purchase)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+type RefreshTokenPostgres struct {
+	db     *sqlx.DB
+	getter *trmsqlx.CtxGetter
+}
+
+func NewRefreshTokenPostgres(db *sqlx.DB) *RefreshTokenPostgres {
+	return &RefreshTokenPostgres{
+		db:     db,
+		getter: trmsqlx.DefaultCtxGetter,
+	}
+}
+
+func (r *RefreshTokenPostgres) CreateRefreshToken(ctx context.Context, token entity.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt)
+
+	return err
+}
+
+func (r *RefreshTokenPostgres) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE`
+
+	return token, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &token, query, tokenHash)
+}
+
+func (r *RefreshTokenPostgres) MarkRefreshTokenUsed(ctx context.Context, tokenID int64) error {
+	query := `UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, tokenID)
+
+	return err
+}
+
+func (r *RefreshTokenPostgres) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE family_id = $1 AND revoked_at IS NULL`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, familyID)
+
+	return err
+}
+
+func (r *RefreshTokenPostgres) RevokeUserRefreshTokens(ctx context.Context, userID int64) error {
+	query := `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, userID)
+
+	return err
+}
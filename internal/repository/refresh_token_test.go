@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func TestRefreshTokenPostgres_CreateRefreshToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewRefreshTokenPostgres(sqlxDB)
+
+	expiresAt := time.Now().Add(time.Hour)
+	token := entity.RefreshToken{UserID: 1, FamilyID: "family", TokenHash: "hash", ExpiresAt: expiresAt}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectExec("INSERT INTO refresh_tokens").
+					WithArgs(int64(1), "family", "hash", expiresAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantError: nil,
+		},
+		{
+			name: "Insert Error",
+			mockBehavior: func() {
+				mock.ExpectExec("INSERT INTO refresh_tokens").
+					WithArgs(int64(1), "family", "hash", expiresAt).
+					WillReturnError(errors.New("insert error"))
+			},
+			wantError: errors.New("insert error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := repo.CreateRefreshToken(context.Background(), token)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRefreshTokenPostgres_GetRefreshTokenForUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewRefreshTokenPostgres(sqlxDB)
+
+	expiresAt := time.Now().Add(time.Hour)
+	usedAt := time.Now()
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantToken    entity.RefreshToken
+		wantError    error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at FROM refresh_tokens WHERE token_hash = \$1 FOR UPDATE`).
+					WithArgs("hash").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "family_id", "token_hash", "expires_at", "used_at", "revoked_at"}).
+						AddRow(int64(1), int64(2), "family", "hash", expiresAt, usedAt, nil))
+			},
+			wantToken: entity.RefreshToken{ID: 1, UserID: 2, FamilyID: "family", TokenHash: "hash", ExpiresAt: expiresAt, UsedAt: &usedAt},
+			wantError: nil,
+		},
+		{
+			name: "Query Error",
+			mockBehavior: func() {
+				mock.ExpectQuery("SELECT id, user_id, family_id").
+					WithArgs("hash").
+					WillReturnError(errors.New("query error"))
+			},
+			wantToken: entity.RefreshToken{},
+			wantError: errors.New("query error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			token, err := repo.GetRefreshTokenForUpdate(context.Background(), "hash")
+
+			assert.Equal(t, tt.wantToken, token)
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRefreshTokenPostgres_MarkRefreshTokenUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewRefreshTokenPostgres(sqlxDB)
+
+	mock.ExpectExec("UPDATE refresh_tokens SET used_at").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.MarkRefreshTokenUsed(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenPostgres_RevokeRefreshTokenFamily(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewRefreshTokenPostgres(sqlxDB)
+
+	mock.ExpectExec("UPDATE refresh_tokens SET revoked_at").
+		WithArgs("family").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err = repo.RevokeRefreshTokenFamily(context.Background(), "family")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenPostgres_RevokeUserRefreshTokens(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewRefreshTokenPostgres(sqlxDB)
+
+	mock.ExpectExec("UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err = repo.RevokeUserRefreshTokens(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
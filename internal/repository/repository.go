@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
@@ -13,14 +14,119 @@ import (
 type UserRepository interface {
 	CreateUser(ctx context.Context, user entity.User) (int64, error)
 	GetUser(ctx context.Context, username string) (entity.User, error)
+	GetUserByID(ctx context.Context, userID int64) (entity.User, error)
 	GetUserBalance(ctx context.Context, userID int64) (int64, error)
+	GetUserBalanceForUpdate(ctx context.Context, userID int64) (int64, error)
 	UpdateCoins(ctx context.Context, userID, amount int64) error
+	AdjustCoins(ctx context.Context, userID, amount int64) error
+	SetCoins(ctx context.Context, userID, from, to int64) (bool, error)
+	CountUsers(ctx context.Context) (int, error)
+	UpdatePasswordHash(ctx context.Context, userID int64, passwordHash string) error
+	SetUserRole(ctx context.Context, username, role string) (int64, error)
+	SetTwoFactorEnabled(ctx context.Context, userID int64, enabled bool) error
+}
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token entity.RefreshToken) error
+	GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (entity.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, tokenID int64) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int64) error
+}
+
+type TokenRevocationRepository interface {
+	RevokeToken(ctx context.Context, tokenID string, userID int64, expiresAt time.Time) error
+	RevokeUserTokens(ctx context.Context, userID int64, revokedBefore time.Time) error
+	IsTokenRevoked(ctx context.Context, tokenID string, userID int64, issuedAt time.Time) (bool, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) error
+}
+
+// LoginAttemptRepository хранит счетчики неудачных попыток входа по ключу (имя пользователя или IP).
+type LoginAttemptRepository interface {
+	GetLoginLockout(ctx context.Context, keys ...string) (time.Time, error)
+	RegisterLoginFailure(ctx context.Context, key string, now, windowStart time.Time) (int, error)
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	ResetLoginAttempts(ctx context.Context, key string) error
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (entity.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID int64) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, keyID int64) error
+	TouchAPIKey(ctx context.Context, keyID int64, usedAt time.Time, interval time.Duration) error
+}
+
+// TwoFactorRepository хранит TOTP-секреты и одноразовые коды восстановления.
+type TwoFactorRepository interface {
+	SaveTOTPSecret(ctx context.Context, userID int64, secret string) error
+	GetTOTP(ctx context.Context, userID int64) (entity.TOTP, error)
+	ConfirmTOTP(ctx context.Context, userID, step int64) error
+	UseTOTPStep(ctx context.Context, userID, step int64) (bool, error)
+	DeleteTOTP(ctx context.Context, userID int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+}
+
+// IdempotencyRepository хранит результаты запросов с Idempotency-Key.
+type IdempotencyRepository interface {
+	CreateIdempotencyKey(ctx context.Context, userID int64, key, requestHash string) (bool, error)
+	GetIdempotencyKey(ctx context.Context, userID int64, key string) (entity.IdempotencyRecord, error)
+	SaveIdempotentResponse(ctx context.Context, userID int64, key string, statusCode int, body []byte) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, userID int64, createdBefore time.Time) error
 }
 
 type TransactionRepository interface {
-	GetReceivedTransactions(ctx context.Context, userID int64) ([]entity.TransactionDetail, error)
-	GetSentTransactions(ctx context.Context, userID int64) ([]entity.TransactionDetail, error)
-	InsertTransaction(ctx context.Context, fromUserID, toUserID, amount int64) error
+	GetReceivedTransactions(ctx context.Context, userID int64, limit int) ([]entity.TransactionDetail, error)
+	GetSentTransactions(ctx context.Context, userID int64, limit int) ([]entity.TransactionDetail, error)
+	GetReceivedTotals(ctx context.Context, userID int64) ([]entity.CounterpartyTotal, error)
+	GetSentTotals(ctx context.Context, userID int64) ([]entity.CounterpartyTotal, error)
+	GetHistory(ctx context.Context, userID int64, filter entity.HistoryFilter) ([]entity.TransactionDetail, error)
+	InsertTransaction(ctx context.Context, transaction entity.Transaction) (entity.Transaction, error)
+	GetTransaction(ctx context.Context, transactionID int64) (entity.Transaction, error)
+}
+
+// PurchaseRepository хранит покупки мерча с ценой на момент покупки.
+type PurchaseRepository interface {
+	InsertPurchase(ctx context.Context, purchase entity.Purchase) (entity.Purchase, error)
+	GetUserPurchases(ctx context.Context, userID int64, limit int) ([]entity.PurchaseDetail, error)
+	GetPurchaseByTransaction(ctx context.Context, transactionID int64) (entity.Purchase, error)
+}
+
+// CoinAdjustmentRepository хранит аудит начислений и списаний монет администраторами.
+type CoinAdjustmentRepository interface {
+	InsertCoinAdjustment(ctx context.Context, adjustment entity.CoinAdjustment) (entity.CoinAdjustment, error)
+}
+
+// AllowanceRepository хранит регулярные начисления и их выплаты по периодам.
+type AllowanceRepository interface {
+	CreateAllowance(ctx context.Context, allowance entity.Allowance) (entity.Allowance, error)
+	AddAllowanceRecipients(ctx context.Context, allowanceID int64, userIDs []int64) error
+	ListAllowances(ctx context.Context) ([]entity.Allowance, error)
+	DisableAllowance(ctx context.Context, allowanceID int64) error
+	ClaimAllowanceRun(ctx context.Context, allowanceID int64, periodStart time.Time) (int64, bool, error)
+	LockAllowanceRun(ctx context.Context, runID int64) (bool, error)
+	GetPendingAllowanceRecipients(ctx context.Context, allowanceID, runID int64, limit int) ([]int64, error)
+	InsertAllowancePayout(ctx context.Context, runID, userID, transactionID int64) error
+	CompleteAllowanceRun(ctx context.Context, runID int64) error
+}
+
+// CoinLotRepository хранит партии сгорающих монет.
+type CoinLotRepository interface {
+	InsertCoinLot(ctx context.Context, lot entity.CoinLot) (entity.CoinLot, error)
+	ConsumeCoinLots(ctx context.Context, userID, amount int64, now time.Time) error
+	GetUpcomingExpirations(ctx context.Context, userID int64, now time.Time) ([]entity.CoinExpiration, error)
+	GetUsersWithExpiredLots(ctx context.Context, now time.Time, limit int) ([]int64, error)
+	ExpireCoinLots(ctx context.Context, userID int64, now time.Time) (int64, error)
+}
+
+// LedgerRepository хранит счета и журнал двойной записи, по которому можно восстановить любой баланс.
+type LedgerRepository interface {
+	CreateUserAccount(ctx context.Context, userID int64) (int64, error)
+	GetUserAccountID(ctx context.Context, userID int64) (int64, error)
+	GetSystemAccountID(ctx context.Context, accountType string) (int64, error)
+	CreateJournalEntry(ctx context.Context, entry entity.JournalEntry) (int64, error)
+	GetBalanceDrifts(ctx context.Context) ([]entity.BalanceDrift, error)
 }
 
 type InventoryRepository interface {
@@ -28,19 +134,42 @@ type InventoryRepository interface {
 	GetUserInventory(ctx context.Context, userID int64) ([]entity.InventoryItem, error)
 	GetInventoryItem(ctx context.Context, userID, merchID int64) (int, error)
 	UpdateInventoryItem(ctx context.Context, userID, merchID int64) error
+	RemoveInventoryItems(ctx context.Context, userID, merchID int64, quantity int) error
 	InsertInventoryItem(ctx context.Context, userID, merchID int64) error
 }
 
 type Repository struct {
 	UserRepository
+	RefreshTokenRepository
+	TokenRevocationRepository
+	LoginAttemptRepository
+	APIKeyRepository
+	TwoFactorRepository
+	IdempotencyRepository
 	TransactionRepository
+	PurchaseRepository
+	CoinAdjustmentRepository
+	AllowanceRepository
+	CoinLotRepository
+	LedgerRepository
 	InventoryRepository
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
 	return &Repository{
-		UserRepository:        NewUserPostgres(db),
-		TransactionRepository: NewTransactionPostgres(db),
-		InventoryRepository:   NewInventoryPostgres(db),
+		UserRepository:            NewUserPostgres(db),
+		RefreshTokenRepository:    NewRefreshTokenPostgres(db),
+		TokenRevocationRepository: NewTokenRevocationPostgres(db),
+		LoginAttemptRepository:    NewLoginAttemptPostgres(db),
+		APIKeyRepository:          NewAPIKeyPostgres(db),
+		TwoFactorRepository:       NewTwoFactorPostgres(db),
+		IdempotencyRepository:     NewIdempotencyPostgres(db),
+		TransactionRepository:     NewTransactionPostgres(db),
+		PurchaseRepository:        NewPurchasePostgres(db),
+		CoinAdjustmentRepository:  NewCoinAdjustmentPostgres(db),
+		AllowanceRepository:       NewAllowancePostgres(db),
+		CoinLotRepository:         NewCoinLotPostgres(db),
+		LedgerRepository:          NewLedgerPostgres(db),
+		InventoryRepository:       NewInventoryPostgres(db),
 	}
 }
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/jmoiron/sqlx"
+)
+
+type TokenRevocationPostgres struct {
+	db     *sqlx.DB
+	getter *trmsqlx.CtxGetter
+}
+
+func NewTokenRevocationPostgres(db *sqlx.DB) *TokenRevocationPostgres {
+	return &TokenRevocationPostgres{
+		db:     db,
+		getter: trmsqlx.DefaultCtxGetter,
+	}
+}
+
+func (r *TokenRevocationPostgres) RevokeToken(ctx context.Context, tokenID string, userID int64, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, tokenID, userID, expiresAt)
+
+	return err
+}
+
+func (r *TokenRevocationPostgres) RevokeUserTokens(ctx context.Context, userID int64, revokedBefore time.Time) error {
+	query := `
+		INSERT INTO user_token_revocations (user_id, revoked_before) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET revoked_before = GREATEST(user_token_revocations.revoked_before, EXCLUDED.revoked_before)`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, userID, revokedBefore)
+
+	return err
+}
+
+func (r *TokenRevocationPostgres) IsTokenRevoked(ctx context.Context, tokenID string, userID int64, issuedAt time.Time) (bool, error) {
+	var revoked bool
+	query := `
+		SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+			OR EXISTS (SELECT 1 FROM user_token_revocations WHERE user_id = $2 AND revoked_before >= $3)`
+
+	return revoked, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &revoked, query, tokenID, userID, issuedAt)
+}
+
+func (r *TokenRevocationPostgres) DeleteExpiredRevokedTokens(ctx context.Context) error {
+	query := `DELETE FROM revoked_tokens WHERE expires_at < CURRENT_TIMESTAMP`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query)
+
+	return err
+}
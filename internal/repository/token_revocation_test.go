@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenRevocationPostgres_RevokeToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewTokenRevocationPostgres(sqlxDB)
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectExec("INSERT INTO revoked_tokens").
+					WithArgs("jti", int64(1), expiresAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantError: nil,
+		},
+		{
+			name: "Insert Error",
+			mockBehavior: func() {
+				mock.ExpectExec("INSERT INTO revoked_tokens").
+					WithArgs("jti", int64(1), expiresAt).
+					WillReturnError(errors.New("insert error"))
+			},
+			wantError: errors.New("insert error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := repo.RevokeToken(context.Background(), "jti", 1, expiresAt)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTokenRevocationPostgres_RevokeUserTokens(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewTokenRevocationPostgres(sqlxDB)
+
+	revokedBefore := time.Now()
+
+	mock.ExpectExec("INSERT INTO user_token_revocations").
+		WithArgs(int64(1), revokedBefore).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.RevokeUserTokens(context.Background(), 1, revokedBefore)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRevocationPostgres_IsTokenRevoked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewTokenRevocationPostgres(sqlxDB)
+
+	issuedAt := time.Now()
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantRevoked  bool
+		wantError    error
+	}{
+		{
+			name: "Revoked",
+			mockBehavior: func() {
+				mock.ExpectQuery("SELECT EXISTS").
+					WithArgs("jti", int64(1), issuedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"revoked"}).AddRow(true))
+			},
+			wantRevoked: true,
+			wantError:   nil,
+		},
+		{
+			name: "Not Revoked",
+			mockBehavior: func() {
+				mock.ExpectQuery("SELECT EXISTS").
+					WithArgs("jti", int64(1), issuedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"revoked"}).AddRow(false))
+			},
+			wantRevoked: false,
+			wantError:   nil,
+		},
+		{
+			name: "Query Error",
+			mockBehavior: func() {
+				mock.ExpectQuery("SELECT EXISTS").
+					WithArgs("jti", int64(1), issuedAt).
+					WillReturnError(errors.New("query error"))
+			},
+			wantRevoked: false,
+			wantError:   errors.New("query error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			revoked, err := repo.IsTokenRevoked(context.Background(), "jti", 1, issuedAt)
+
+			assert.Equal(t, tt.wantRevoked, revoked)
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTokenRevocationPostgres_DeleteExpiredRevokedTokens(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewTokenRevocationPostgres(sqlxDB)
+
+	mock.ExpectExec("DELETE FROM revoked_tokens WHERE expires_at").
+		WillReturnResult(sqlmock.NewResult(0, 5))
+
+	err = repo.DeleteExpiredRevokedTokens(context.Background())
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
 	"github.com/jmoiron/sqlx"
@@ -21,31 +23,178 @@ func NewTransactionPostgres(db *sqlx.DB) *TransactionPostgres {
 	}
 }
 
-func (r *TransactionPostgres) GetReceivedTransactions(ctx context.Context, userID int64) ([]entity.TransactionDetail, error) {
+// GetReceivedTransactions возвращает входящие переводы без сторно, начиная с последних. limit = 0 снимает ограничение.
+func (r *TransactionPostgres) GetReceivedTransactions(ctx context.Context, userID int64, limit int) ([]entity.TransactionDetail, error) {
 	var received []entity.TransactionDetail
 	query := `
-		SELECT u.username AS from_user, t.amount
+		SELECT t.id, u.username AS from_user, t.amount, t.created_at
 		FROM transactions AS t
 		JOIN users AS u ON t.from_user = u.id
-		WHERE t.to_user = $1`
+		WHERE t.to_user = $1 AND t.type = 'transfer'
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT NULLIF($2, 0)`
 
-	return received, r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &received, query, userID)
+	return received, r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &received, query, userID, limit)
 }
 
-func (r *TransactionPostgres) GetSentTransactions(ctx context.Context, userID int64) ([]entity.TransactionDetail, error) {
+// GetSentTransactions возвращает исходящие переводы без сторно, начиная с последних. limit = 0 снимает ограничение.
+func (r *TransactionPostgres) GetSentTransactions(ctx context.Context, userID int64, limit int) ([]entity.TransactionDetail, error) {
 	var sent []entity.TransactionDetail
 	query := `
-		SELECT u.username AS to_user, t.amount
+		SELECT t.id, u.username AS to_user, t.amount, t.created_at
 		FROM transactions AS t
 		JOIN users AS u ON t.to_user = u.id
-		WHERE t.from_user = $1`
+		WHERE t.from_user = $1 AND t.type = 'transfer'
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT NULLIF($2, 0)`
+
+	return sent, r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &sent, query, userID, limit)
+}
+
+// GetReceivedTotals группирует входящие переводы без сторно по отправителю, начиная с наибольшей суммы.
+func (r *TransactionPostgres) GetReceivedTotals(ctx context.Context, userID int64) ([]entity.CounterpartyTotal, error) {
+	var received []entity.CounterpartyTotal
+	query := `
+		SELECT u.username AS from_user, SUM(t.amount) AS amount, COUNT(*) AS count
+		FROM transactions AS t
+		JOIN users AS u ON t.from_user = u.id
+		WHERE t.to_user = $1 AND t.type = 'transfer'
+		GROUP BY u.username
+		ORDER BY amount DESC, u.username`
+
+	return received, r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &received, query, userID)
+}
+
+// GetSentTotals группирует исходящие переводы без сторно по получателю, начиная с наибольшей суммы.
+func (r *TransactionPostgres) GetSentTotals(ctx context.Context, userID int64) ([]entity.CounterpartyTotal, error) {
+	var sent []entity.CounterpartyTotal
+	query := `
+		SELECT u.username AS to_user, SUM(t.amount) AS amount, COUNT(*) AS count
+		FROM transactions AS t
+		JOIN users AS u ON t.to_user = u.id
+		WHERE t.from_user = $1 AND t.type = 'transfer'
+		GROUP BY u.username
+		ORDER BY amount DESC, u.username`
 
 	return sent, r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &sent, query, userID)
 }
 
-func (r *TransactionPostgres) InsertTransaction(ctx context.Context, fromUserID, toUserID, amount int64) error {
-	query := `INSERT INTO transactions (from_user, to_user, amount) VALUES ($1, $2, $3)`
-	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, fromUserID, toUserID, amount)
+// GetHistory возвращает страницу истории пользователя — переводы в обоих направлениях, покупки, их отмены
+// и выпуск монет (стартовые монеты, начисления администратором), начиная с последних. Сторно перевода показывается как обычный перевод в обратную сторону.
+// Фильтры из filter добавляются к запросу, только если заданы.
+func (r *TransactionPostgres) GetHistory(ctx context.Context, userID int64, filter entity.HistoryFilter) ([]entity.TransactionDetail, error) {
+	var history []entity.TransactionDetail
+	args := []interface{}{userID}
+	conditions := []string{"(t.from_user = $1 OR t.to_user = $1)"}
+
+	addCondition := func(format string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	switch filter.Direction {
+	case entity.DirectionReceived:
+		conditions = append(conditions, "t.to_user = $1")
+	case entity.DirectionSent:
+		conditions = append(conditions, "t.type IN ('transfer', 'reversal') AND t.from_user = $1")
+	case entity.DirectionPurchase:
+		conditions = append(conditions, "t.type = 'purchase'")
+	case entity.DirectionRefund:
+		conditions = append(conditions, "t.type = 'refund'")
+	case entity.DirectionMint:
+		conditions = append(conditions, "t.type = 'mint'")
+	case entity.DirectionBurn:
+		conditions = append(conditions, "t.type = 'burn'")
+	case entity.DirectionSignupGrant:
+		conditions = append(conditions, "t.type = 'signup_grant'")
+	case entity.DirectionAllowance:
+		conditions = append(conditions, "t.type = 'allowance'")
+	case entity.DirectionExpiration:
+		conditions = append(conditions, "t.type = 'expiration'")
+	}
+	if filter.Counterparty != "" {
+		addCondition("t.type IN ('transfer', 'reversal') AND CASE WHEN t.from_user = $1 THEN tu.username ELSE fu.username END = $%d", filter.Counterparty)
+	}
+	if filter.MinAmount != nil {
+		addCondition("t.amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		addCondition("t.amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.From != nil {
+		addCondition("t.created_at >= $%d", filter.From.UTC())
+	}
+	if filter.To != nil {
+		addCondition("t.created_at < $%d", filter.To.UTC())
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.CreatedAt.UTC(), filter.After.ID)
+		conditions = append(conditions, fmt.Sprintf("(t.created_at, t.id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT t.id, t.amount, t.reverses_id, t.created_at,
+			CASE
+				WHEN t.type = 'purchase' THEN 'purchase'
+				WHEN t.type IN ('refund', 'mint', 'burn', 'signup_grant', 'allowance', 'expiration') THEN t.type
+				WHEN t.from_user = $1 THEN 'sent'
+				ELSE 'received'
+			END AS direction,
+			CASE WHEN t.to_user = $1 THEN fu.username ELSE '' END AS from_user,
+			CASE WHEN t.type IN ('transfer', 'reversal') AND t.from_user = $1 THEN tu.username ELSE '' END AS to_user,
+			COALESCE(mi.item_type, '') AS item,
+			COALESCE(p.quantity, 0) AS quantity
+		FROM transactions AS t
+		JOIN users AS fu ON t.from_user = fu.id
+		LEFT JOIN users AS tu ON t.to_user = tu.id
+		LEFT JOIN purchases AS p ON p.transaction_id = COALESCE(t.reverses_id, t.id)
+		LEFT JOIN merch_items AS mi ON t.merch_id = mi.id
+		WHERE %s
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	return history, r.getter.DefaultTrOrDB(ctx, r.db).SelectContext(ctx, &history, query, args...)
+}
+
+// InsertTransaction сохраняет операцию и возвращает ее с заполненными ID и временем создания.
+// Повторная отмена той же операции возвращает entity.ErrAlreadyReversed.
+func (r *TransactionPostgres) InsertTransaction(ctx context.Context, transaction entity.Transaction) (entity.Transaction, error) {
+	query := `
+		INSERT INTO transactions (type, from_user, to_user, merch_id, amount, sender_balance, recipient_balance, reverses_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+
+	row := r.getter.DefaultTrOrDB(ctx, r.db).QueryRowContext(ctx, query,
+		transaction.Type, transaction.FromUserID, transaction.ToUserID, transaction.MerchID,
+		transaction.Amount, transaction.SenderBalance, transaction.RecipientBalance, transaction.ReversesID)
+	if err := row.Scan(&transaction.ID, &transaction.CreatedAt); err != nil {
+		if transaction.ReversesID != nil && isUniqueViolation(err) {
+			return entity.Transaction{}, entity.ErrAlreadyReversed
+		}
+		return entity.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// GetTransaction возвращает операцию вместе с ID ее сторно или возврата, если она уже отменена.
+func (r *TransactionPostgres) GetTransaction(ctx context.Context, transactionID int64) (entity.Transaction, error) {
+	var transaction entity.Transaction
+	query := `
+		SELECT t.id, t.type, t.from_user, t.to_user, t.merch_id, t.amount,
+			t.sender_balance, t.recipient_balance, t.reverses_id, t.created_at,
+			(SELECT r.id FROM transactions AS r WHERE r.reverses_id = t.id) AS reversed_by,
+			fu.username AS from_username,
+			COALESCE(tu.username, '') AS to_username,
+			COALESCE(m.item_type, '') AS item
+		FROM transactions AS t
+		JOIN users AS fu ON t.from_user = fu.id
+		LEFT JOIN users AS tu ON t.to_user = tu.id
+		LEFT JOIN merch_items AS m ON t.merch_id = m.id
+		WHERE t.id = $1`
+
+	err := r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &transaction, query, transactionID)
 
-	return err
+	return transaction, err
 }
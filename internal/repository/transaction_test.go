@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/senyabanana/shop-service/internal/entity"
@@ -19,6 +22,7 @@ func TestTransactionPostgres_GetReceivedTransactions(t *testing.T) {
 
 	sqlxDB := sqlx.NewDb(db, testDriverName)
 	repo := NewTransactionPostgres(sqlxDB)
+	createdAt := time.Now()
 
 	tests := []struct {
 		name         string
@@ -31,20 +35,21 @@ func TestTransactionPostgres_GetReceivedTransactions(t *testing.T) {
 			name:   "Success",
 			userID: 1,
 			mockBehavior: func() {
-				rows := sqlmock.NewRows([]string{"from_user", "amount"}).
-					AddRow("user1", int64(100)).
-					AddRow("user2", int64(50))
+				rows := sqlmock.NewRows([]string{"id", "from_user", "amount", "created_at"}).
+					AddRow(int64(2), "user1", int64(100), createdAt).
+					AddRow(int64(1), "user2", int64(50), createdAt)
 
 				mock.ExpectQuery(`
-						SELECT u.username AS from_user, t.amount FROM transactions AS t
-						JOIN users AS u ON t.from_user = u.id WHERE t.to_user = \$1`).
-					WithArgs(int64(1)).
+						SELECT t.id, u.username AS from_user, t.amount, t.created_at FROM transactions AS t
+						JOIN users AS u ON t.from_user = u.id WHERE t.to_user = \$1 AND t.type = 'transfer'
+						ORDER BY t.created_at DESC, t.id DESC LIMIT NULLIF\(\$2, 0\)`).
+					WithArgs(int64(1), 10).
 					WillReturnRows(rows)
 			},
 			wantError: nil,
 			wantData: []entity.TransactionDetail{
-				{FromUser: "user1", Amount: 100},
-				{FromUser: "user2", Amount: 50},
+				{ID: 2, FromUser: "user1", Amount: 100, CreatedAt: createdAt},
+				{ID: 1, FromUser: "user2", Amount: 50, CreatedAt: createdAt},
 			},
 		},
 		{
@@ -52,9 +57,10 @@ func TestTransactionPostgres_GetReceivedTransactions(t *testing.T) {
 			userID: 1,
 			mockBehavior: func() {
 				mock.ExpectQuery(`
-						SELECT u.username AS from_user, t.amount FROM transactions AS t
-						JOIN users AS u ON t.from_user = u.id WHERE t.to_user = \$1`).
-					WithArgs(int64(1)).
+						SELECT t.id, u.username AS from_user, t.amount, t.created_at FROM transactions AS t
+						JOIN users AS u ON t.from_user = u.id WHERE t.to_user = \$1 AND t.type = 'transfer'
+						ORDER BY t.created_at DESC, t.id DESC LIMIT NULLIF\(\$2, 0\)`).
+					WithArgs(int64(1), 10).
 					WillReturnError(errors.New("query error"))
 			},
 			wantError: errors.New("query error"),
@@ -67,7 +73,7 @@ func TestTransactionPostgres_GetReceivedTransactions(t *testing.T) {
 			tt.mockBehavior()
 
 			ctx := context.Background()
-			data, err := repo.GetReceivedTransactions(ctx, tt.userID)
+			data, err := repo.GetReceivedTransactions(ctx, tt.userID, 10)
 
 			assert.Equal(t, tt.wantError, err)
 			assert.Equal(t, tt.wantData, data)
@@ -83,6 +89,7 @@ func TestTransactionPostgres_GetSentTransactions(t *testing.T) {
 
 	sqlxDB := sqlx.NewDb(db, testDriverName)
 	repo := NewTransactionPostgres(sqlxDB)
+	createdAt := time.Now()
 
 	tests := []struct {
 		name         string
@@ -95,20 +102,21 @@ func TestTransactionPostgres_GetSentTransactions(t *testing.T) {
 			name:   "Success",
 			userID: 1,
 			mockBehavior: func() {
-				rows := sqlmock.NewRows([]string{"to_user", "amount"}).
-					AddRow("user2", int64(100)).
-					AddRow("user3", int64(200))
+				rows := sqlmock.NewRows([]string{"id", "to_user", "amount", "created_at"}).
+					AddRow(int64(4), "user2", int64(100), createdAt).
+					AddRow(int64(3), "user3", int64(200), createdAt)
 
 				mock.ExpectQuery(`
-						SELECT u.username AS to_user, t.amount FROM transactions AS t
-						JOIN users AS u ON t.to_user = u.id WHERE t.from_user = \$1`).
-					WithArgs(int64(1)).
+						SELECT t.id, u.username AS to_user, t.amount, t.created_at FROM transactions AS t
+						JOIN users AS u ON t.to_user = u.id WHERE t.from_user = \$1 AND t.type = 'transfer'
+						ORDER BY t.created_at DESC, t.id DESC LIMIT NULLIF\(\$2, 0\)`).
+					WithArgs(int64(1), 10).
 					WillReturnRows(rows)
 			},
 			wantError: nil,
 			wantData: []entity.TransactionDetail{
-				{ToUser: "user2", Amount: 100},
-				{ToUser: "user3", Amount: 200},
+				{ID: 4, ToUser: "user2", Amount: 100, CreatedAt: createdAt},
+				{ID: 3, ToUser: "user3", Amount: 200, CreatedAt: createdAt},
 			},
 		},
 		{
@@ -116,9 +124,10 @@ func TestTransactionPostgres_GetSentTransactions(t *testing.T) {
 			userID: 1,
 			mockBehavior: func() {
 				mock.ExpectQuery(`
-						SELECT u.username AS to_user, t.amount FROM transactions AS t
-						JOIN users AS u ON t.to_user = u.id WHERE t.from_user = \$1`).
-					WithArgs(int64(1)).
+						SELECT t.id, u.username AS to_user, t.amount, t.created_at FROM transactions AS t
+						JOIN users AS u ON t.to_user = u.id WHERE t.from_user = \$1 AND t.type = 'transfer'
+						ORDER BY t.created_at DESC, t.id DESC LIMIT NULLIF\(\$2, 0\)`).
+					WithArgs(int64(1), 10).
 					WillReturnError(errors.New("query error"))
 			},
 			wantError: errors.New("query error"),
@@ -131,7 +140,138 @@ func TestTransactionPostgres_GetSentTransactions(t *testing.T) {
 			tt.mockBehavior()
 
 			ctx := context.Background()
-			data, err := repo.GetSentTransactions(ctx, tt.userID)
+			data, err := repo.GetSentTransactions(ctx, tt.userID, 10)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantData, data)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTransactionPostgres_GetTotals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewTransactionPostgres(sqlxDB)
+
+	t.Run("Received", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT u.username AS from_user, SUM\(t.amount\) AS amount, COUNT\(\*\) AS count
+				FROM transactions AS t JOIN users AS u ON t.from_user = u.id WHERE t.to_user = \$1 AND t.type = 'transfer'
+				GROUP BY u.username`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"from_user", "amount", "count"}).
+				AddRow("user2", int64(300), int64(4)).
+				AddRow("user3", int64(50), int64(1)))
+
+		data, err := repo.GetReceivedTotals(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []entity.CounterpartyTotal{
+			{FromUser: "user2", Amount: 300, Count: 4},
+			{FromUser: "user3", Amount: 50, Count: 1},
+		}, data)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Sent", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT u.username AS to_user, SUM\(t.amount\) AS amount, COUNT\(\*\) AS count
+				FROM transactions AS t JOIN users AS u ON t.to_user = u.id WHERE t.from_user = \$1 AND t.type = 'transfer'
+				GROUP BY u.username`).
+			WithArgs(int64(1)).
+			WillReturnError(errors.New("query error"))
+
+		data, err := repo.GetSentTotals(context.Background(), 1)
+
+		assert.Equal(t, errors.New("query error"), err)
+		assert.Nil(t, data)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTransactionPostgres_GetHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewTransactionPostgres(sqlxDB)
+
+	createdAt := time.Now()
+	minAmount, maxAmount := int64(10), int64(500)
+	from, to := createdAt.Add(-24*time.Hour), createdAt
+	columns := []string{"id", "amount", "created_at", "direction", "from_user", "to_user", "item", "quantity"}
+
+	tests := []struct {
+		name         string
+		filter       entity.HistoryFilter
+		mockBehavior func()
+		wantError    error
+		wantData     []entity.TransactionDetail
+	}{
+		{
+			name:   "No Filters",
+			filter: entity.HistoryFilter{Limit: 21},
+			mockBehavior: func() {
+				mock.ExpectQuery(`WHERE \(t.from_user = \$1 OR t.to_user = \$1\)
+						ORDER BY t.created_at DESC, t.id DESC LIMIT \$2`).
+					WithArgs(int64(1), 21).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(6), int64(80), createdAt, entity.DirectionPurchase, "", "", "t-shirt", 1).
+						AddRow(int64(5), int64(100), createdAt, entity.DirectionSent, "", "user2", "", 0).
+						AddRow(int64(4), int64(50), createdAt, entity.DirectionReceived, "user3", "", "", 0))
+			},
+			wantError: nil,
+			wantData: []entity.TransactionDetail{
+				{ID: 6, Direction: entity.DirectionPurchase, Item: "t-shirt", Quantity: 1, Amount: 80, CreatedAt: createdAt},
+				{ID: 5, Direction: entity.DirectionSent, ToUser: "user2", Amount: 100, CreatedAt: createdAt},
+				{ID: 4, Direction: entity.DirectionReceived, FromUser: "user3", Amount: 50, CreatedAt: createdAt},
+			},
+		},
+		{
+			name: "All Filters",
+			filter: entity.HistoryFilter{
+				Direction:    entity.DirectionSent,
+				Counterparty: "user2",
+				MinAmount:    &minAmount,
+				MaxAmount:    &maxAmount,
+				From:         &from,
+				To:           &to,
+				After:        &entity.HistoryCursor{CreatedAt: createdAt, ID: 9},
+				Limit:        11,
+			},
+			mockBehavior: func() {
+				mock.ExpectQuery(`AND t.type IN \('transfer', 'reversal'\) AND t.from_user = \$1
+						AND t.type IN \('transfer', 'reversal'\) AND CASE WHEN t.from_user = \$1 THEN tu.username ELSE fu.username END = \$2
+						AND t.amount >= \$3 AND t.amount <= \$4
+						AND t.created_at >= \$5 AND t.created_at < \$6
+						AND \(t.created_at, t.id\) < \(\$7, \$8\)
+						ORDER BY t.created_at DESC, t.id DESC LIMIT \$9`).
+					WithArgs(int64(1), "user2", minAmount, maxAmount, from.UTC(), to.UTC(), createdAt.UTC(), int64(9), 11).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			wantError: nil,
+			wantData:  nil,
+		},
+		{
+			name:   "Query Error",
+			filter: entity.HistoryFilter{Limit: 21},
+			mockBehavior: func() {
+				mock.ExpectQuery(`FROM transactions AS t`).
+					WillReturnError(errors.New("query error"))
+			},
+			wantError: errors.New("query error"),
+			wantData:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			data, err := repo.GetHistory(context.Background(), 1, tt.filter)
 
 			assert.Equal(t, tt.wantError, err)
 			assert.Equal(t, tt.wantData, data)
@@ -148,37 +288,51 @@ func TestTransactionPostgres_InsertTransaction(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, testDriverName)
 	repo := NewTransactionPostgres(sqlxDB)
 
+	toUserID := int64(2)
+	senderBalance, recipientBalance := int64(900), int64(1100)
+	createdAt := time.Now()
+	transaction := entity.Transaction{
+		Type:             entity.TransactionTypeTransfer,
+		FromUserID:       1,
+		ToUserID:         &toUserID,
+		Amount:           100,
+		SenderBalance:    &senderBalance,
+		RecipientBalance: &recipientBalance,
+	}
+
 	tests := []struct {
 		name         string
-		fromUserID   int64
-		toUserID     int64
-		amount       int64
 		mockBehavior func()
 		wantError    error
+		wantData     entity.Transaction
 	}{
 		{
-			name:       "Success",
-			fromUserID: 1,
-			toUserID:   2,
-			amount:     100,
+			name: "Success",
 			mockBehavior: func() {
-				mock.ExpectExec(`INSERT INTO transactions \(from_user, to_user, amount\) VALUES \(\$1, \$2, \$3\)`).
-					WithArgs(int64(1), int64(2), int64(100)).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(`INSERT INTO transactions \(type, from_user, to_user, merch_id, amount, sender_balance, recipient_balance, reverses_id\)`).
+					WithArgs(entity.TransactionTypeTransfer, int64(1), &toUserID, nil, int64(100), &senderBalance, &recipientBalance, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(7), createdAt))
 			},
 			wantError: nil,
+			wantData: entity.Transaction{
+				ID:               7,
+				Type:             entity.TransactionTypeTransfer,
+				FromUserID:       1,
+				ToUserID:         &toUserID,
+				Amount:           100,
+				SenderBalance:    &senderBalance,
+				RecipientBalance: &recipientBalance,
+				CreatedAt:        createdAt,
+			},
 		},
 		{
-			name:       "Query Error",
-			fromUserID: 1,
-			toUserID:   2,
-			amount:     100,
+			name: "Query Error",
 			mockBehavior: func() {
-				mock.ExpectExec(`INSERT INTO transactions \(from_user, to_user, amount\) VALUES \(\$1, \$2, \$3\)`).
-					WithArgs(int64(1), int64(2), int64(100)).
+				mock.ExpectQuery(`INSERT INTO transactions`).
 					WillReturnError(errors.New("insert error"))
 			},
 			wantError: errors.New("insert error"),
+			wantData:  entity.Transaction{},
 		},
 	}
 
@@ -187,9 +341,125 @@ func TestTransactionPostgres_InsertTransaction(t *testing.T) {
 			tt.mockBehavior()
 
 			ctx := context.Background()
-			err := repo.InsertTransaction(ctx, tt.fromUserID, tt.toUserID, tt.amount)
+			got, err := repo.InsertTransaction(ctx, transaction)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantData, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTransactionPostgres_InsertTransaction_AlreadyReversed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewTransactionPostgres(sqlxDB)
+
+	toUserID, reversesID := int64(1), int64(7)
+	mock.ExpectQuery(`INSERT INTO transactions`).
+		WithArgs(entity.TransactionTypeReversal, int64(2), &toUserID, nil, int64(100), nil, nil, &reversesID).
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	_, err = repo.InsertTransaction(context.Background(), entity.Transaction{
+		Type:       entity.TransactionTypeReversal,
+		FromUserID: 2,
+		ToUserID:   &toUserID,
+		Amount:     100,
+		ReversesID: &reversesID,
+	})
+
+	assert.Equal(t, entity.ErrAlreadyReversed, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransactionPostgres_GetTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewTransactionPostgres(sqlxDB)
+
+	merchID, balance := int64(3), int64(920)
+	createdAt := time.Now()
+	fromUserID, toUserID, reversedBy := int64(1), int64(2), int64(9)
+	columns := []string{"id", "type", "from_user", "to_user", "merch_id", "amount",
+		"sender_balance", "recipient_balance", "reverses_id", "created_at", "reversed_by",
+		"from_username", "to_username", "item"}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+		wantData     entity.Transaction
+	}{
+		{
+			name: "Purchase",
+			mockBehavior: func() {
+				mock.ExpectQuery(`FROM transactions AS t .* WHERE t.id = \$1`).
+					WithArgs(int64(7)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(7), entity.TransactionTypePurchase, int64(1), nil, merchID, int64(80),
+							balance, nil, nil, createdAt, nil, "user1", "", "t-shirt"))
+			},
+			wantError: nil,
+			wantData: entity.Transaction{
+				ID:            7,
+				Type:          entity.TransactionTypePurchase,
+				FromUserID:    1,
+				MerchID:       &merchID,
+				Amount:        80,
+				SenderBalance: &balance,
+				CreatedAt:     createdAt,
+				FromUsername:  "user1",
+				Item:          "t-shirt",
+			},
+		},
+		{
+			name: "Reversed Transfer",
+			mockBehavior: func() {
+				mock.ExpectQuery(`FROM transactions AS t .* WHERE t.id = \$1`).
+					WithArgs(int64(7)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(7), entity.TransactionTypeTransfer, fromUserID, toUserID, nil, int64(100),
+							nil, nil, nil, createdAt, reversedBy, "user1", "user2", ""))
+			},
+			wantError: nil,
+			wantData: entity.Transaction{
+				ID:           7,
+				Type:         entity.TransactionTypeTransfer,
+				FromUserID:   fromUserID,
+				ToUserID:     &toUserID,
+				Amount:       100,
+				ReversedByID: &reversedBy,
+				CreatedAt:    createdAt,
+				FromUsername: "user1",
+				ToUsername:   "user2",
+			},
+		},
+		{
+			name: "Not Found",
+			mockBehavior: func() {
+				mock.ExpectQuery(`FROM transactions AS t .* WHERE t.id = \$1`).
+					WithArgs(int64(7)).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantError: sql.ErrNoRows,
+			wantData:  entity.Transaction{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			got, err := repo.GetTransaction(context.Background(), 7)
 
 			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantData, got)
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
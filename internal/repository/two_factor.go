@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+type TwoFactorPostgres struct {
+	db     *sqlx.DB
+	getter *trmsqlx.CtxGetter
+}
+
+func NewTwoFactorPostgres(db *sqlx.DB) *TwoFactorPostgres {
+	return &TwoFactorPostgres{
+		db:     db,
+		getter: trmsqlx.DefaultCtxGetter,
+	}
+}
+
+// SaveTOTPSecret сохраняет новый неподтвержденный секрет, заменяя незавершенную попытку подключения.
+func (r *TwoFactorPostgres) SaveTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, confirmed_at = NULL, last_used_step = 0, created_at = CURRENT_TIMESTAMP`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, userID, secret)
+
+	return err
+}
+
+func (r *TwoFactorPostgres) GetTOTP(ctx context.Context, userID int64) (entity.TOTP, error) {
+	var totp entity.TOTP
+	query := `SELECT user_id, secret, confirmed_at, last_used_step FROM user_totp WHERE user_id = $1`
+
+	return totp, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &totp, query, userID)
+}
+
+func (r *TwoFactorPostgres) ConfirmTOTP(ctx context.Context, userID, step int64) error {
+	query := `UPDATE user_totp SET confirmed_at = CURRENT_TIMESTAMP, last_used_step = $1 WHERE user_id = $2`
+
+	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return entity.ErrTwoFactorNotEnrolled
+	}
+
+	return nil
+}
+
+// UseTOTPStep атомарно отмечает временной шаг использованным. Возвращает false, если код
+// этого или более позднего шага уже предъявлялся, — так один код нельзя использовать дважды.
+func (r *TwoFactorPostgres) UseTOTPStep(ctx context.Context, userID, step int64) (bool, error) {
+	query := `UPDATE user_totp SET last_used_step = $1 WHERE user_id = $2 AND last_used_step < $1`
+
+	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+func (r *TwoFactorPostgres) DeleteTOTP(ctx context.Context, userID int64) error {
+	query := `DELETE FROM user_totp WHERE user_id = $1`
+	_, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, userID)
+
+	return err
+}
+
+// ReplaceRecoveryCodes удаляет все коды восстановления пользователя и сохраняет новые.
+// Пустой список просто удаляет коды.
+func (r *TwoFactorPostgres) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	db := r.getter.DefaultTrOrDB(ctx, r.db)
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO recovery_codes (user_id, code_hash) SELECT $1, unnest($2::text[])`
+	_, err := db.ExecContext(ctx, query, userID, pq.Array(codeHashes))
+
+	return err
+}
+
+func (r *TwoFactorPostgres) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	query := `
+		UPDATE recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM recovery_codes
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+			LIMIT 1
+		) AND used_at IS NULL`
+
+	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	return rowsAffected == 1, nil
+}
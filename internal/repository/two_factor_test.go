@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorPostgres_UseTOTPStep(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewTwoFactorPostgres(sqlxDB)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		want         bool
+		wantError    error
+	}{
+		{
+			name: "Fresh Step",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE user_totp SET last_used_step").
+					WithArgs(int64(100), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want:      true,
+			wantError: nil,
+		},
+		{
+			name: "Step Already Used",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE user_totp SET last_used_step").
+					WithArgs(int64(100), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want:      false,
+			wantError: nil,
+		},
+		{
+			name: "Update Error",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE user_totp SET last_used_step").
+					WithArgs(int64(100), int64(1)).
+					WillReturnError(errors.New("update error"))
+			},
+			want:      false,
+			wantError: errors.New("update error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			got, err := repo.UseTOTPStep(context.Background(), 1, 100)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTwoFactorPostgres_ReplaceRecoveryCodes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewTwoFactorPostgres(sqlxDB)
+
+	tests := []struct {
+		name         string
+		hashes       []string
+		mockBehavior func()
+		wantError    error
+	}{
+		{
+			name:   "Replace",
+			hashes: []string{"h1", "h2"},
+			mockBehavior: func() {
+				mock.ExpectExec("DELETE FROM recovery_codes WHERE user_id").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("INSERT INTO recovery_codes").
+					WithArgs(int64(1), pq.Array([]string{"h1", "h2"})).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			wantError: nil,
+		},
+		{
+			name:   "Delete Only",
+			hashes: nil,
+			mockBehavior: func() {
+				mock.ExpectExec("DELETE FROM recovery_codes WHERE user_id").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			wantError: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := repo.ReplaceRecoveryCodes(context.Background(), 1, tt.hashes)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTwoFactorPostgres_UseRecoveryCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewTwoFactorPostgres(sqlxDB)
+
+	mock.ExpectExec("UPDATE recovery_codes SET used_at").
+		WithArgs(int64(1), "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE recovery_codes SET used_at").
+		WithArgs(int64(1), "hash").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	used, err := repo.UseRecoveryCode(context.Background(), 1, "hash")
+	assert.NoError(t, err)
+	assert.True(t, used)
+
+	used, err = repo.UseRecoveryCode(context.Background(), 1, "hash")
+	assert.NoError(t, err)
+	assert.False(t, used, "recovery code must be single-use")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
 	"github.com/jmoiron/sqlx"
@@ -26,6 +28,9 @@ func (r *UserPostgres) CreateUser(ctx context.Context, user entity.User) (int64,
 	query := `INSERT INTO users (username, password_hash, coins) VALUES ($1, $2, $3) RETURNING id`
 	row := r.getter.DefaultTrOrDB(ctx, r.db).QueryRowContext(ctx, query, user.Username, user.Password, user.Coins)
 	if err := row.Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return 0, entity.ErrUserExists
+		}
 		return 0, err
 	}
 
@@ -34,11 +39,18 @@ func (r *UserPostgres) CreateUser(ctx context.Context, user entity.User) (int64,
 
 func (r *UserPostgres) GetUser(ctx context.Context, username string) (entity.User, error) {
 	var user entity.User
-	query := `SELECT id, username, users.password_hash, coins FROM users WHERE username = $1`
+	query := `SELECT id, username, users.password_hash, coins, role, two_factor_enabled FROM users WHERE username = $1`
 
 	return user, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &user, query, username)
 }
 
+func (r *UserPostgres) GetUserByID(ctx context.Context, userID int64) (entity.User, error) {
+	var user entity.User
+	query := `SELECT id, username, users.password_hash, coins, role, two_factor_enabled FROM users WHERE id = $1`
+
+	return user, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &user, query, userID)
+}
+
 func (r *UserPostgres) GetUserBalance(ctx context.Context, userID int64) (int64, error) {
 	var balance int64
 	query := `SELECT coins FROM users WHERE id = $1`
@@ -46,8 +58,16 @@ func (r *UserPostgres) GetUserBalance(ctx context.Context, userID int64) (int64,
 	return balance, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &balance, query, userID)
 }
 
+// GetUserBalanceForUpdate читает баланс и блокирует строку пользователя до конца транзакции.
+func (r *UserPostgres) GetUserBalanceForUpdate(ctx context.Context, userID int64) (int64, error) {
+	var balance int64
+	query := `SELECT coins FROM users WHERE id = $1 FOR UPDATE`
+
+	return balance, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &balance, query, userID)
+}
+
 func (r *UserPostgres) UpdateCoins(ctx context.Context, userID, amount int64) error {
-	query := `UPDATE users SET coins = coins + $1 WHERE id = $2 AND coins >= $1`
+	query := `UPDATE users SET coins = coins + $1 WHERE id = $2 AND coins + $1 >= 0`
 
 	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, amount, userID)
 	if err != nil {
@@ -61,3 +81,93 @@ func (r *UserPostgres) UpdateCoins(ctx context.Context, userID, amount int64) er
 
 	return nil
 }
+
+// AdjustCoins меняет баланс на amount без проверки остатка: баланс может уйти в минус.
+// Нужен для административных операций, которые явно это разрешают.
+func (r *UserPostgres) AdjustCoins(ctx context.Context, userID, amount int64) error {
+	query := `UPDATE users SET coins = coins + $1 WHERE id = $2`
+
+	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, amount, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return entity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SetCoins перезаписывает баланс, только если он по-прежнему равен from.
+// Возвращает false, если баланс успел измениться.
+func (r *UserPostgres) SetCoins(ctx context.Context, userID, from, to int64) (bool, error) {
+	query := `UPDATE users SET coins = $1 WHERE id = $2 AND coins = $3`
+
+	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, to, userID, from)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *UserPostgres) CountUsers(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM users`
+
+	return count, r.getter.DefaultTrOrDB(ctx, r.db).GetContext(ctx, &count, query)
+}
+
+func (r *UserPostgres) UpdatePasswordHash(ctx context.Context, userID int64, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2`
+
+	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, passwordHash, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return entity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *UserPostgres) SetTwoFactorEnabled(ctx context.Context, userID int64, enabled bool) error {
+	query := `UPDATE users SET two_factor_enabled = $1 WHERE id = $2`
+
+	res, err := r.getter.DefaultTrOrDB(ctx, r.db).ExecContext(ctx, query, enabled, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return entity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *UserPostgres) SetUserRole(ctx context.Context, username, role string) (int64, error) {
+	var id int64
+	query := `UPDATE users SET role = $1 WHERE username = $2 RETURNING id`
+
+	err := r.getter.DefaultTrOrDB(ctx, r.db).QueryRowContext(ctx, query, role, username).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, entity.ErrUserNotFound
+	}
+
+	return id, err
+}
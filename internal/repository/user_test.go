@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/senyabanana/shop-service/internal/entity"
@@ -59,6 +61,21 @@ func TestUserPostgres_CreateUser(t *testing.T) {
 			wantID:    0,
 			wantError: errors.New("pq: duplicate key value violates unique constraint"),
 		},
+		{
+			name: "Unique Violation Mapped",
+			inputUser: entity.User{
+				Username: "existuser",
+				Password: "testpass",
+				Coins:    1000,
+			},
+			mockBehavior: func() {
+				mock.ExpectQuery("INSERT INTO users").
+					WithArgs("existuser", "testpass", int64(1000)).
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			wantID:    0,
+			wantError: entity.ErrUserExists,
+		},
 	}
 
 	for _, tt := range tests {
@@ -94,16 +111,17 @@ func TestUserPostgres_GetUser(t *testing.T) {
 			name:     "Success",
 			username: "testuser",
 			mockBehavior: func() {
-				mock.ExpectQuery("SELECT id, username, users.password_hash, coins FROM users WHERE username").
+				mock.ExpectQuery("SELECT id, username, users.password_hash, coins, role, two_factor_enabled FROM users WHERE username").
 					WithArgs("testuser").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "coins"}).
-						AddRow(int64(1), "testuser", "testpass", int64(1000)))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "coins", "role"}).
+						AddRow(int64(1), "testuser", "testpass", int64(1000), "user"))
 			},
 			wantUser: entity.User{
 				ID:       1,
 				Username: "testuser",
 				Password: "testpass",
 				Coins:    1000,
+				Role:     entity.RoleUser,
 			},
 			wantError: nil,
 		},
@@ -111,7 +129,7 @@ func TestUserPostgres_GetUser(t *testing.T) {
 			name:     "User Not Found",
 			username: "unknown_user",
 			mockBehavior: func() {
-				mock.ExpectQuery("SELECT id, username, users.password_hash, coins FROM users WHERE username").
+				mock.ExpectQuery("SELECT id, username, users.password_hash, coins, role, two_factor_enabled FROM users WHERE username").
 					WithArgs("unknown_user").
 					WillReturnError(errors.New("sql: no rows in result set"))
 			},
@@ -187,6 +205,25 @@ func TestUserPostgres_GetUserBalance(t *testing.T) {
 	}
 }
 
+func TestUserPostgres_GetUserBalanceForUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewUserPostgres(sqlxDB)
+
+	mock.ExpectQuery(`SELECT coins FROM users WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"coins"}).AddRow(int64(500)))
+
+	coins, err := repo.GetUserBalanceForUpdate(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(500), coins)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserPostgres_UpdateCoins(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -207,7 +244,7 @@ func TestUserPostgres_UpdateCoins(t *testing.T) {
 			userID: 1,
 			amount: 50,
 			mockBehavior: func() {
-				mock.ExpectExec("UPDATE users SET coins = coins [+-]").
+				mock.ExpectExec(`UPDATE users SET coins = coins \+ \$1 WHERE id = \$2 AND coins \+ \$1 >= 0`).
 					WithArgs(int64(50), int64(1)).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
@@ -218,7 +255,7 @@ func TestUserPostgres_UpdateCoins(t *testing.T) {
 			userID: 1,
 			amount: -50,
 			mockBehavior: func() {
-				mock.ExpectExec("UPDATE users SET coins = coins [+-]").
+				mock.ExpectExec(`UPDATE users SET coins = coins \+ \$1 WHERE id = \$2 AND coins \+ \$1 >= 0`).
 					WithArgs(int64(-50), int64(1)).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
@@ -229,7 +266,7 @@ func TestUserPostgres_UpdateCoins(t *testing.T) {
 			userID: 2,
 			amount: 1000,
 			mockBehavior: func() {
-				mock.ExpectExec("UPDATE users SET coins = coins [+-]").
+				mock.ExpectExec(`UPDATE users SET coins = coins \+ \$1 WHERE id = \$2 AND coins \+ \$1 >= 0`).
 					WithArgs(int64(1000), int64(2)).
 					WillReturnResult(sqlmock.NewResult(1, 0))
 			},
@@ -249,3 +286,256 @@ func TestUserPostgres_UpdateCoins(t *testing.T) {
 		})
 	}
 }
+
+func TestUserPostgres_AdjustCoins(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewUserPostgres(sqlxDB)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantError    error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectExec(`UPDATE users SET coins = coins \+ \$1 WHERE id = \$2$`).
+					WithArgs(int64(-500), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantError: nil,
+		},
+		{
+			name: "User Not Found",
+			mockBehavior: func() {
+				mock.ExpectExec(`UPDATE users SET coins = coins \+ \$1 WHERE id = \$2$`).
+					WithArgs(int64(-500), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantError: entity.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := repo.AdjustCoins(context.Background(), 1, -500)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserPostgres_SetCoins(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewUserPostgres(sqlxDB)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantUpdated  bool
+		wantError    error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectExec(`UPDATE users SET coins = \$1 WHERE id = \$2 AND coins = \$3`).
+					WithArgs(int64(900), int64(1), int64(950)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantUpdated: true,
+			wantError:   nil,
+		},
+		{
+			name: "Balance Changed",
+			mockBehavior: func() {
+				mock.ExpectExec(`UPDATE users SET coins = \$1 WHERE id = \$2 AND coins = \$3`).
+					WithArgs(int64(900), int64(1), int64(950)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantUpdated: false,
+			wantError:   nil,
+		},
+		{
+			name: "Exec Error",
+			mockBehavior: func() {
+				mock.ExpectExec(`UPDATE users SET coins`).
+					WillReturnError(errors.New("db error"))
+			},
+			wantUpdated: false,
+			wantError:   errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			updated, err := repo.SetCoins(context.Background(), 1, 950, 900)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.Equal(t, tt.wantUpdated, updated)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserPostgres_CountUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewUserPostgres(sqlxDB)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+	count, err := repo.CountUsers(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserPostgres_UpdatePasswordHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewUserPostgres(sqlxDB)
+
+	tests := []struct {
+		name         string
+		userID       int64
+		passwordHash string
+		mockBehavior func()
+		wantError    error
+	}{
+		{
+			name:         "Success",
+			userID:       1,
+			passwordHash: "$argon2id$hash",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE users SET password_hash").
+					WithArgs("$argon2id$hash", int64(1)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantError: nil,
+		},
+		{
+			name:         "User Not Found",
+			userID:       2,
+			passwordHash: "$argon2id$hash",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE users SET password_hash").
+					WithArgs("$argon2id$hash", int64(2)).
+					WillReturnResult(sqlmock.NewResult(1, 0))
+			},
+			wantError: entity.ErrUserNotFound,
+		},
+		{
+			name:         "Query Error",
+			userID:       3,
+			passwordHash: "$argon2id$hash",
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE users SET password_hash").
+					WithArgs("$argon2id$hash", int64(3)).
+					WillReturnError(errors.New("database error"))
+			},
+			wantError: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			ctx := context.Background()
+			err := repo.UpdatePasswordHash(ctx, tt.userID, tt.passwordHash)
+
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserPostgres_GetUserByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewUserPostgres(sqlxDB)
+
+	mock.ExpectQuery("SELECT id, username, users.password_hash, coins, role, two_factor_enabled FROM users WHERE id").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "coins", "role"}).
+			AddRow(int64(1), "admin", "hash", int64(1000), "admin"))
+
+	user, err := repo.GetUserByID(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.User{ID: 1, Username: "admin", Password: "hash", Coins: 1000, Role: entity.RoleAdmin}, user)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserPostgres_SetUserRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, testDriverName)
+	repo := NewUserPostgres(sqlxDB)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantID       int64
+		wantError    error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectQuery("UPDATE users SET role").
+					WithArgs("admin", "testuser").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+			},
+			wantID:    1,
+			wantError: nil,
+		},
+		{
+			name: "User Not Found",
+			mockBehavior: func() {
+				mock.ExpectQuery("UPDATE users SET role").
+					WithArgs("admin", "testuser").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantID:    0,
+			wantError: entity.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			id, err := repo.SetUserRole(context.Background(), "testuser", entity.RoleAdmin)
+
+			assert.Equal(t, tt.wantID, id)
+			assert.Equal(t, tt.wantError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
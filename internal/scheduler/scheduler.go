@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// jobFailures — число неудачных запусков фоновых задач по именам.
+var jobFailures = expvar.NewMap("scheduled_job_failures")
+
+// Job — фоновая задача, которая выполняется раз в Interval. Задачи, которые должны выполняться
+// один раз на весь кластер, сами отвечают за координацию реплик через базу данных.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler запускает фоновые задачи сервиса рядом с HTTP-сервером. Каждая задача работает
+// в своей горутине; следующий запуск начинается не раньше, чем закончится предыдущий.
+type Scheduler struct {
+	jobs []Job
+	log  *logrus.Logger
+	wg   sync.WaitGroup
+}
+
+func New(log *logrus.Logger) *Scheduler {
+	return &Scheduler{log: log}
+}
+
+// Add регистрирует задачу. Задачи с неположительным интервалом отключены и не запускаются.
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		s.log.Infof("Scheduled job %s is disabled", job.Name)
+		return
+	}
+
+	s.jobs = append(s.jobs, job)
+}
+
+// Start запускает задачи. Они останавливаются при отмене ctx; дождаться текущих запусков можно через Wait.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}()
+		s.log.Infof("Scheduled job %s runs every %s", job.Name, job.Interval)
+	}
+}
+
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+				jobFailures.Add(job.Name, 1)
+				s.log.Errorf("scheduled job %s failed: %s", job.Name, err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs, failures, disabled atomic.Int32
+
+	s := New(logrus.New())
+	s.Add(Job{Name: "counter", Interval: time.Millisecond, Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	s.Add(Job{Name: "failing", Interval: time.Millisecond, Run: func(context.Context) error {
+		failures.Add(1)
+		return errors.New("job error")
+	}})
+	s.Add(Job{Name: "disabled", Interval: 0, Run: func(context.Context) error {
+		disabled.Add(1)
+		return nil
+	}})
+
+	s.Start(ctx)
+
+	assert.Eventually(t, func() bool {
+		return runs.Load() >= 3 && failures.Load() >= 3
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	s.Wait()
+
+	stopped := runs.Load()
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, stopped, runs.Load())
+	assert.Zero(t, disabled.Load())
+	assert.NotNil(t, jobFailures.Get("failing"))
+	assert.Nil(t, jobFailures.Get("counter"))
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/sirupsen/logrus"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/repository"
+)
+
+// allowanceBatchSize — сколько получателей начисления обрабатывается в одной транзакции.
+const allowanceBatchSize = 100
+
+// AllowanceService управляет регулярными начислениями и выплачивает их. Выплата периода закрепляется
+// записью allowance_runs и идет пачками по allowanceBatchSize получателей, поэтому при нескольких
+// репликах каждый получатель получает выплату за период ровно один раз, а пропущенные периоды
+// (сервис не работал) не наверстываются.
+type AllowanceService struct {
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
+	allowanceRepo   repository.AllowanceRepository
+	coinLotRepo     repository.CoinLotRepository
+	ledger          ledgerWriter
+	trManager       *manager.Manager
+	tx              txRunner
+	expirationCfg   ExpirationConfig
+	log             *logrus.Logger
+}
+
+func NewAllowanceService(
+	userRepo repository.UserRepository,
+	transactionRepo repository.TransactionRepository,
+	allowanceRepo repository.AllowanceRepository,
+	coinLotRepo repository.CoinLotRepository,
+	ledgerRepo repository.LedgerRepository,
+	trManager *manager.Manager,
+	txCfg TxConfig,
+	expirationCfg ExpirationConfig,
+	log *logrus.Logger) *AllowanceService {
+	return &AllowanceService{
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		allowanceRepo:   allowanceRepo,
+		coinLotRepo:     coinLotRepo,
+		ledger:          ledgerWriter{repo: ledgerRepo},
+		trManager:       trManager,
+		tx:              txRunner{trManager: trManager, cfg: txCfg, log: log},
+		expirationCfg:   expirationCfg,
+		log:             log,
+	}
+}
+
+// CreateAllowance создает регулярное начисление. Первая выплата — за текущий период,
+// при ближайшем запуске планировщика.
+func (s *AllowanceService) CreateAllowance(ctx context.Context, adminID int64, input entity.CreateAllowanceRequest) (entity.Allowance, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return entity.Allowance{}, entity.ErrAllowanceNameRequired
+	}
+
+	allowance := entity.Allowance{
+		Name:      name,
+		Amount:    input.Amount,
+		Cadence:   input.Cadence,
+		AllUsers:  len(input.Usernames) == 0,
+		CreatedBy: adminID,
+	}
+
+	err := s.trManager.Do(ctx, func(ctx context.Context) error {
+		userIDs := make([]int64, 0, len(input.Usernames))
+		for _, username := range input.Usernames {
+			user, err := getUserByName(ctx, s.userRepo, s.log, username)
+			if err != nil {
+				return err
+			}
+			userIDs = append(userIDs, user.ID)
+		}
+
+		created, err := s.allowanceRepo.CreateAllowance(ctx, allowance)
+		if err != nil {
+			s.log.Errorf("CreateAllowance failed: failed to insert allowance %q: %v", name, err)
+			return err
+		}
+
+		if !allowance.AllUsers {
+			if err = s.allowanceRepo.AddAllowanceRecipients(ctx, created.ID, userIDs); err != nil {
+				s.log.Errorf("CreateAllowance failed: failed to add recipients of allowance %d: %v", created.ID, err)
+				return err
+			}
+		}
+
+		allowance = created
+		return nil
+	})
+	if err != nil {
+		return entity.Allowance{}, err
+	}
+
+	s.log.Infof("Admin %d created %s allowance %d of %d coins", adminID, allowance.Cadence, allowance.ID, allowance.Amount)
+	return allowance, nil
+}
+
+func (s *AllowanceService) ListAllowances(ctx context.Context) ([]entity.Allowance, error) {
+	allowances, err := s.allowanceRepo.ListAllowances(ctx)
+	if err != nil {
+		s.log.Errorf("ListAllowances failed: %v", err)
+		return nil, err
+	}
+
+	return allowances, nil
+}
+
+// DisableAllowance прекращает выплаты. Уже выплаченные монеты остаются у пользователей.
+func (s *AllowanceService) DisableAllowance(ctx context.Context, adminID, allowanceID int64) error {
+	if err := s.allowanceRepo.DisableAllowance(ctx, allowanceID); err != nil {
+		if errors.Is(err, entity.ErrAllowanceNotFound) {
+			s.log.Warnf("DisableAllowance failed: allowance %d not found", allowanceID)
+			return err
+		}
+		s.log.Errorf("DisableAllowance failed: failed to disable allowance %d: %v", allowanceID, err)
+		return err
+	}
+
+	s.log.Infof("Admin %d disabled allowance %d", adminID, allowanceID)
+	return nil
+}
+
+// ApplyDueAllowances выплачивает начисления, текущий период которых еще не выплачен, и возвращает
+// число выплаченных периодов. Ошибка одного начисления не мешает выплатить остальные.
+func (s *AllowanceService) ApplyDueAllowances(ctx context.Context, now time.Time) (int, error) {
+	allowances, err := s.allowanceRepo.ListAllowances(ctx)
+	if err != nil {
+		s.log.Errorf("ApplyDueAllowances failed: failed to list allowances: %v", err)
+		return 0, err
+	}
+
+	var applied int
+	var errs []error
+
+	for _, allowance := range allowances {
+		period := periodStart(allowance.Cadence, now)
+		if allowance.LastPeriod != nil && !allowance.LastPeriod.Before(period) {
+			continue
+		}
+
+		paid, err := s.apply(ctx, allowance, period, now)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if paid {
+			applied++
+		}
+	}
+
+	return applied, errors.Join(errs...)
+}
+
+// apply выплачивает период начисления. Возвращает false, если период уже выплатила другая реплика.
+// Каждая пачка получателей выплачивается отдельной транзакцией и блокирует только их балансы;
+// если выплата прервалась, следующий запуск продолжит ее с тех, кому она еще не досталась.
+func (s *AllowanceService) apply(ctx context.Context, allowance entity.Allowance, period, now time.Time) (bool, error) {
+	runID, claimed, err := s.allowanceRepo.ClaimAllowanceRun(ctx, allowance.ID, period)
+	if err != nil {
+		s.log.Errorf("ApplyAllowance failed: failed to claim period %s of allowance %d: %v", period.Format(time.DateOnly), allowance.ID, err)
+		return false, err
+	}
+	if !claimed {
+		s.log.Debugf("Allowance %d for period %s is already paid", allowance.ID, period.Format(time.DateOnly))
+		return false, nil
+	}
+
+	var paidUsers int
+
+	for {
+		var paid int
+		var open, completed bool
+
+		err = s.tx.do(ctx, opApplyAllowance, func(ctx context.Context) error {
+			paid, open, completed = 0, false, false
+
+			locked, err := s.allowanceRepo.LockAllowanceRun(ctx, runID)
+			if err != nil {
+				s.log.Errorf("ApplyAllowance failed: failed to lock run %d: %v", runID, err)
+				return err
+			}
+			if !locked {
+				return nil
+			}
+			open = true
+
+			userIDs, err := s.allowanceRepo.GetPendingAllowanceRecipients(ctx, allowance.ID, runID, allowanceBatchSize)
+			if err != nil {
+				s.log.Errorf("ApplyAllowance failed: failed to fetch recipients of allowance %d: %v", allowance.ID, err)
+				return err
+			}
+
+			if err = s.payBatch(ctx, allowance, runID, userIDs, now); err != nil {
+				return err
+			}
+
+			if len(userIDs) < allowanceBatchSize {
+				if err = s.allowanceRepo.CompleteAllowanceRun(ctx, runID); err != nil {
+					s.log.Errorf("ApplyAllowance failed: failed to complete run %d: %v", runID, err)
+					return err
+				}
+				completed = true
+			}
+
+			paid = len(userIDs)
+			return nil
+		})
+		if err != nil {
+			return false, err
+		}
+
+		paidUsers += paid
+
+		if !open {
+			s.log.Debugf("Allowance %d for period %s is already paid", allowance.ID, period.Format(time.DateOnly))
+			return false, nil
+		}
+		if completed {
+			break
+		}
+	}
+
+	s.log.Infof("Allowance %d paid %d coins to %d users for period %s", allowance.ID, allowance.Amount, paidUsers, period.Format(time.DateOnly))
+	return true, nil
+}
+
+// payBatch выплачивает начисление пачке получателей в текущей транзакции. Если задан AllowanceCoinTTL,
+// каждая выплата становится партией монет, которые сгорят через TTL.
+func (s *AllowanceService) payBatch(ctx context.Context, allowance entity.Allowance, runID int64, userIDs []int64, now time.Time) error {
+	balances, err := lockBalances(ctx, s.userRepo, userIDs...)
+	if err != nil {
+		s.log.Errorf("ApplyAllowance failed: failed to lock balances: %v", err)
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if err = s.userRepo.AdjustCoins(ctx, userID, allowance.Amount); err != nil {
+			s.log.Errorf("ApplyAllowance failed: failed to increase balance for user %d: %v", userID, err)
+			return err
+		}
+
+		balance := balances[userID] + allowance.Amount
+
+		transaction, err := s.transactionRepo.InsertTransaction(ctx, entity.Transaction{
+			Type:          entity.TransactionTypeAllowance,
+			FromUserID:    userID,
+			Amount:        allowance.Amount,
+			SenderBalance: &balance,
+		})
+		if err != nil {
+			s.log.Errorf("ApplyAllowance failed: failed to insert payout for user %d: %v", userID, err)
+			return err
+		}
+
+		if err = s.allowanceRepo.InsertAllowancePayout(ctx, runID, userID, transaction.ID); err != nil {
+			s.log.Errorf("ApplyAllowance failed: failed to link transaction %d to run %d: %v", transaction.ID, runID, err)
+			return err
+		}
+
+		if ttl := s.expirationCfg.AllowanceCoinTTL; ttl > 0 {
+			_, err = s.coinLotRepo.InsertCoinLot(ctx, entity.CoinLot{
+				UserID:        userID,
+				TransactionID: transaction.ID,
+				Amount:        allowance.Amount,
+				GrantedAt:     now,
+				ExpiresAt:     now.Add(ttl),
+			})
+			if err != nil {
+				s.log.Errorf("ApplyAllowance failed: failed to record coin lot of transaction %d: %v", transaction.ID, err)
+				return err
+			}
+		}
+
+		if err = s.ledger.allowance(ctx, transaction.ID, userID, allowance.Amount); err != nil {
+			s.log.Errorf("ApplyAllowance failed: failed to post transaction %d to ledger: %v", transaction.ID, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// periodStart возвращает начало периода, в который попадает now: полночь UTC для daily,
+// понедельник для weekly и первое число месяца для monthly.
+func periodStart(cadence string, now time.Time) time.Time {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch cadence {
+	case entity.CadenceWeekly:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case entity.CadenceMonthly:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
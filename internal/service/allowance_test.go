@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func TestPeriodStart(t *testing.T) {
+	// Четверг, 13 февраля 2025.
+	now := time.Date(2025, 2, 13, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		cadence string
+		now     time.Time
+		want    time.Time
+	}{
+		{cadence: entity.CadenceDaily, now: now, want: time.Date(2025, 2, 13, 0, 0, 0, 0, time.UTC)},
+		{cadence: entity.CadenceWeekly, now: now, want: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)},
+		{cadence: entity.CadenceWeekly, now: time.Date(2025, 2, 16, 23, 0, 0, 0, time.UTC), want: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)},
+		{cadence: entity.CadenceWeekly, now: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC), want: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)},
+		{cadence: entity.CadenceMonthly, now: now, want: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{cadence: entity.CadenceMonthly, now: now.In(time.FixedZone("UTC+3", 3*60*60)), want: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.cadence, tt.now.Format(time.DateOnly)), func(t *testing.T) {
+			assert.Equal(t, tt.want, periodStart(tt.cadence, tt.now))
+		})
+	}
+}
+
+func TestAllowanceService_CreateAllowance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockAllowanceRepo := mocks.NewMockAllowanceRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+
+	service := NewAllowanceService(mockUserRepo, nil, mockAllowanceRepo, nil, nil, mockTrManager, TxConfig{MaxAttempts: 1}, ExpirationConfig{}, logrus.New())
+
+	tests := []struct {
+		name          string
+		input         entity.CreateAllowanceRequest
+		mockBehavior  func()
+		wantAllowance entity.Allowance
+		wantErr       error
+	}{
+		{
+			name:  "Success",
+			input: entity.CreateAllowanceRequest{Name: " team ", Amount: 50, Cadence: entity.CadenceWeekly, Usernames: []string{"alice", "bob"}},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), "alice").Return(entity.User{ID: 2, Username: "alice"}, nil)
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), "bob").Return(entity.User{ID: 1, Username: "bob"}, nil)
+				mockAllowanceRepo.EXPECT().CreateAllowance(gomock.Any(), entity.Allowance{
+					Name: "team", Amount: 50, Cadence: entity.CadenceWeekly, CreatedBy: 100,
+				}).Return(entity.Allowance{ID: 3, Name: "team", Amount: 50, Cadence: entity.CadenceWeekly, CreatedBy: 100, CreatedAt: testCreatedAt}, nil)
+				mockAllowanceRepo.EXPECT().AddAllowanceRecipients(gomock.Any(), int64(3), []int64{2, 1}).Return(nil)
+				mock.ExpectCommit()
+			},
+			wantAllowance: entity.Allowance{ID: 3, Name: "team", Amount: 50, Cadence: entity.CadenceWeekly, CreatedBy: 100, CreatedAt: testCreatedAt},
+		},
+		{
+			name:  "All Users",
+			input: entity.CreateAllowanceRequest{Name: "monthly", Amount: 500, Cadence: entity.CadenceMonthly},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockAllowanceRepo.EXPECT().CreateAllowance(gomock.Any(), entity.Allowance{
+					Name: "monthly", Amount: 500, Cadence: entity.CadenceMonthly, AllUsers: true, CreatedBy: 100,
+				}).Return(entity.Allowance{ID: 4, Name: "monthly", Amount: 500, Cadence: entity.CadenceMonthly, AllUsers: true, CreatedBy: 100}, nil)
+				mock.ExpectCommit()
+			},
+			wantAllowance: entity.Allowance{ID: 4, Name: "monthly", Amount: 500, Cadence: entity.CadenceMonthly, AllUsers: true, CreatedBy: 100},
+		},
+		{
+			name:  "User Not Found",
+			input: entity.CreateAllowanceRequest{Name: "team", Amount: 50, Cadence: entity.CadenceWeekly, Usernames: []string{"ghost"}},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), "ghost").Return(entity.User{}, sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: fmt.Errorf("%w: %s", entity.ErrUserNotFound, "ghost"),
+		},
+		{
+			name:         "Blank Name",
+			input:        entity.CreateAllowanceRequest{Name: "  ", Amount: 50, Cadence: entity.CadenceWeekly},
+			mockBehavior: func() {},
+			wantErr:      entity.ErrAllowanceNameRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			allowance, err := service.CreateAllowance(context.Background(), 100, tt.input)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantAllowance, allowance)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAllowanceService_ApplyDueAllowances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockAllowanceRepo := mocks.NewMockAllowanceRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+
+	service := NewAllowanceService(mockUserRepo, mockTransactionRepo, mockAllowanceRepo, nil, mockLedgerRepo, mockTrManager, TxConfig{MaxAttempts: 1}, ExpirationConfig{}, logrus.New())
+
+	now := time.Date(2025, 2, 13, 9, 0, 0, 0, time.UTC)
+	february := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	january := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	monthly := entity.Allowance{ID: 3, Amount: 500, Cadence: entity.CadenceMonthly, AllUsers: true, LastPeriod: &january}
+
+	expectPayout := func(userID, transactionID, balance int64) {
+		mockUserRepo.EXPECT().AdjustCoins(gomock.Any(), userID, int64(500)).Return(nil)
+		mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), entity.Transaction{
+			Type:          entity.TransactionTypeAllowance,
+			FromUserID:    userID,
+			Amount:        500,
+			SenderBalance: int64Ptr(balance),
+		}).Return(entity.Transaction{ID: transactionID}, nil)
+		mockAllowanceRepo.EXPECT().InsertAllowancePayout(gomock.Any(), int64(9), userID, transactionID).Return(nil)
+		mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), userID).Return(userID+10, nil)
+		mockLedgerRepo.EXPECT().GetSystemAccountID(gomock.Any(), entity.LedgerAccountIssuance).Return(int64(2), nil)
+		mockLedgerRepo.EXPECT().CreateJournalEntry(gomock.Any(), entity.JournalEntry{
+			Kind:          entity.JournalAllowance,
+			TransactionID: int64Ptr(transactionID),
+			Postings: []entity.LedgerPosting{
+				{AccountID: 2, Amount: -500},
+				{AccountID: userID + 10, Amount: 500},
+			},
+		}).Return(transactionID, nil)
+	}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantApplied  int
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mockAllowanceRepo.EXPECT().ListAllowances(gomock.Any()).Return([]entity.Allowance{monthly}, nil)
+				mockAllowanceRepo.EXPECT().ClaimAllowanceRun(gomock.Any(), int64(3), february).Return(int64(9), true, nil)
+				mock.ExpectBegin()
+				mockAllowanceRepo.EXPECT().LockAllowanceRun(gomock.Any(), int64(9)).Return(true, nil)
+				mockAllowanceRepo.EXPECT().GetPendingAllowanceRecipients(gomock.Any(), int64(3), int64(9), allowanceBatchSize).Return([]int64{1, 2}, nil)
+				gomock.InOrder(
+					mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil),
+					mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(-20), nil),
+				)
+				expectPayout(1, 20, 600)
+				expectPayout(2, 21, 480)
+				mockAllowanceRepo.EXPECT().CompleteAllowanceRun(gomock.Any(), int64(9)).Return(nil)
+				mock.ExpectCommit()
+			},
+			wantApplied: 1,
+		},
+		{
+			name: "Paid In Batches",
+			mockBehavior: func() {
+				mockAllowanceRepo.EXPECT().ListAllowances(gomock.Any()).Return([]entity.Allowance{monthly}, nil)
+				mockAllowanceRepo.EXPECT().ClaimAllowanceRun(gomock.Any(), int64(3), february).Return(int64(9), true, nil)
+
+				batch := make([]int64, allowanceBatchSize)
+				for i := range batch {
+					batch[i] = int64(i + 1)
+				}
+				mock.ExpectBegin()
+				mockAllowanceRepo.EXPECT().LockAllowanceRun(gomock.Any(), int64(9)).Return(true, nil)
+				mockAllowanceRepo.EXPECT().GetPendingAllowanceRecipients(gomock.Any(), int64(3), int64(9), allowanceBatchSize).Return(batch, nil)
+				for _, userID := range batch {
+					mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), userID).Return(int64(0), nil)
+					expectPayout(userID, userID+1000, 500)
+				}
+				mock.ExpectCommit()
+
+				mock.ExpectBegin()
+				mockAllowanceRepo.EXPECT().LockAllowanceRun(gomock.Any(), int64(9)).Return(true, nil)
+				mockAllowanceRepo.EXPECT().GetPendingAllowanceRecipients(gomock.Any(), int64(3), int64(9), allowanceBatchSize).Return([]int64{101}, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(101)).Return(int64(0), nil)
+				expectPayout(101, 1101, 500)
+				mockAllowanceRepo.EXPECT().CompleteAllowanceRun(gomock.Any(), int64(9)).Return(nil)
+				mock.ExpectCommit()
+			},
+			wantApplied: 1,
+		},
+		{
+			name: "Already Paid By Another Replica",
+			mockBehavior: func() {
+				mockAllowanceRepo.EXPECT().ListAllowances(gomock.Any()).Return([]entity.Allowance{monthly}, nil)
+				mockAllowanceRepo.EXPECT().ClaimAllowanceRun(gomock.Any(), int64(3), february).Return(int64(0), false, nil)
+			},
+			wantApplied: 0,
+		},
+		{
+			name: "Completed By Another Replica",
+			mockBehavior: func() {
+				mockAllowanceRepo.EXPECT().ListAllowances(gomock.Any()).Return([]entity.Allowance{monthly}, nil)
+				mockAllowanceRepo.EXPECT().ClaimAllowanceRun(gomock.Any(), int64(3), february).Return(int64(9), true, nil)
+				mock.ExpectBegin()
+				mockAllowanceRepo.EXPECT().LockAllowanceRun(gomock.Any(), int64(9)).Return(false, nil)
+				mock.ExpectCommit()
+			},
+			wantApplied: 0,
+		},
+		{
+			name: "Batch Fails",
+			mockBehavior: func() {
+				mockAllowanceRepo.EXPECT().ListAllowances(gomock.Any()).Return([]entity.Allowance{monthly}, nil)
+				mockAllowanceRepo.EXPECT().ClaimAllowanceRun(gomock.Any(), int64(3), february).Return(int64(9), true, nil)
+				mock.ExpectBegin()
+				mockAllowanceRepo.EXPECT().LockAllowanceRun(gomock.Any(), int64(9)).Return(true, nil)
+				mockAllowanceRepo.EXPECT().GetPendingAllowanceRecipients(gomock.Any(), int64(3), int64(9), allowanceBatchSize).Return(nil, errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantApplied: 0,
+			wantErr:     errors.Join(errors.New("db error")),
+		},
+		{
+			name: "Current Period Paid",
+			mockBehavior: func() {
+				paid := monthly
+				paid.LastPeriod = &february
+				mockAllowanceRepo.EXPECT().ListAllowances(gomock.Any()).Return([]entity.Allowance{paid}, nil)
+			},
+			wantApplied: 0,
+		},
+		{
+			name: "One Allowance Fails",
+			mockBehavior: func() {
+				daily := entity.Allowance{ID: 4, Amount: 500, Cadence: entity.CadenceDaily}
+				mockAllowanceRepo.EXPECT().ListAllowances(gomock.Any()).Return([]entity.Allowance{daily, monthly}, nil)
+				mockAllowanceRepo.EXPECT().
+					ClaimAllowanceRun(gomock.Any(), int64(4), time.Date(2025, 2, 13, 0, 0, 0, 0, time.UTC)).
+					Return(int64(0), false, errors.New("db error"))
+				mockAllowanceRepo.EXPECT().ClaimAllowanceRun(gomock.Any(), int64(3), february).Return(int64(9), true, nil)
+				mock.ExpectBegin()
+				mockAllowanceRepo.EXPECT().LockAllowanceRun(gomock.Any(), int64(9)).Return(true, nil)
+				mockAllowanceRepo.EXPECT().GetPendingAllowanceRecipients(gomock.Any(), int64(3), int64(9), allowanceBatchSize).Return([]int64{1}, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
+				expectPayout(1, 20, 600)
+				mockAllowanceRepo.EXPECT().CompleteAllowanceRun(gomock.Any(), int64(9)).Return(nil)
+				mock.ExpectCommit()
+			},
+			wantApplied: 1,
+			wantErr:     errors.Join(errors.New("db error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			applied, err := service.ApplyDueAllowances(context.Background(), now)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantApplied, applied)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAllowanceService_ApplyDueAllowances_ExpiringCoins(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockAllowanceRepo := mocks.NewMockAllowanceRepository(ctrl)
+	mockCoinLotRepo := mocks.NewMockCoinLotRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+
+	ttl := 90 * 24 * time.Hour
+	service := NewAllowanceService(mockUserRepo, mockTransactionRepo, mockAllowanceRepo, mockCoinLotRepo, mockLedgerRepo, mockTrManager,
+		TxConfig{MaxAttempts: 1}, ExpirationConfig{AllowanceCoinTTL: ttl}, logrus.New())
+
+	now := time.Date(2025, 2, 13, 9, 0, 0, 0, time.UTC)
+	february := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	monthly := entity.Allowance{ID: 3, Amount: 500, Cadence: entity.CadenceMonthly, AllUsers: true}
+
+	mockAllowanceRepo.EXPECT().ListAllowances(gomock.Any()).Return([]entity.Allowance{monthly}, nil)
+	mockAllowanceRepo.EXPECT().ClaimAllowanceRun(gomock.Any(), int64(3), february).Return(int64(9), true, nil)
+	mock.ExpectBegin()
+	mockAllowanceRepo.EXPECT().LockAllowanceRun(gomock.Any(), int64(9)).Return(true, nil)
+	mockAllowanceRepo.EXPECT().GetPendingAllowanceRecipients(gomock.Any(), int64(3), int64(9), allowanceBatchSize).Return([]int64{1}, nil)
+	mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
+	mockUserRepo.EXPECT().AdjustCoins(gomock.Any(), int64(1), int64(500)).Return(nil)
+	mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), gomock.Any()).Return(entity.Transaction{ID: 20}, nil)
+	mockAllowanceRepo.EXPECT().InsertAllowancePayout(gomock.Any(), int64(9), int64(1), int64(20)).Return(nil)
+	mockCoinLotRepo.EXPECT().InsertCoinLot(gomock.Any(), entity.CoinLot{
+		UserID:        1,
+		TransactionID: 20,
+		Amount:        500,
+		GrantedAt:     now,
+		ExpiresAt:     now.Add(ttl),
+	}).Return(entity.CoinLot{ID: 4}, nil)
+	mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), int64(1)).Return(int64(11), nil)
+	mockLedgerRepo.EXPECT().GetSystemAccountID(gomock.Any(), entity.LedgerAccountIssuance).Return(int64(2), nil)
+	mockLedgerRepo.EXPECT().CreateJournalEntry(gomock.Any(), gomock.Any()).Return(int64(20), nil)
+	mockAllowanceRepo.EXPECT().CompleteAllowanceRun(gomock.Any(), int64(9)).Return(nil)
+	mock.ExpectCommit()
+
+	applied, err := service.ApplyDueAllowances(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/repository"
+)
+
+const (
+	// Ключ имеет вид shop_<prefix>_<secret>. Префикс хранится открыто и позволяет опознать ключ
+	// в списке и логах, секрет хранится только в виде хеша.
+	apiKeyPrefix = "shop_"
+
+	apiKeyPrefixSize = 4
+	apiKeySecretSize = 32
+
+	apiKeyTouchInterval = time.Minute
+
+	// apiKeyCreateAttempts — сколько раз генерируется новый ключ, если случайный префикс уже занят.
+	apiKeyCreateAttempts = 3
+)
+
+type APIKeyService struct {
+	repo       repository.APIKeyRepository
+	defaultTTL time.Duration
+	log        *logrus.Logger
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository, defaultTTL time.Duration, log *logrus.Logger) *APIKeyService {
+	return &APIKeyService{
+		repo:       repo,
+		defaultTTL: defaultTTL,
+		log:        log,
+	}
+}
+
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, userID int64, input entity.CreateAPIKeyRequest) (entity.CreatedAPIKey, error) {
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		s.log.Warnf("CreateAPIKey: user %d requested invalid scopes %v", userID, input.Scopes)
+		return entity.CreatedAPIKey{}, err
+	}
+
+	expiresAt := time.Now().Add(s.defaultTTL)
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(time.Now()) {
+			return entity.CreatedAPIKey{}, entity.ErrInvalidExpiration
+		}
+		expiresAt = *input.ExpiresAt
+	}
+
+	for attempt := 1; ; attempt++ {
+		prefixBytes := make([]byte, apiKeyPrefixSize)
+		if _, err := rand.Read(prefixBytes); err != nil {
+			return entity.CreatedAPIKey{}, err
+		}
+		prefix := hex.EncodeToString(prefixBytes)
+
+		secret, err := generateRandomToken(apiKeySecretSize)
+		if err != nil {
+			return entity.CreatedAPIKey{}, err
+		}
+		rawKey := apiKeyPrefix + prefix + "_" + secret
+
+		key, err := s.repo.CreateAPIKey(ctx, entity.APIKey{
+			UserID:    userID,
+			Name:      input.Name,
+			Prefix:    prefix,
+			KeyHash:   hashToken(rawKey),
+			Scopes:    scopes,
+			ExpiresAt: expiresAt,
+		})
+		if errors.Is(err, entity.ErrAPIKeyPrefixTaken) && attempt < apiKeyCreateAttempts {
+			s.log.Warnf("CreateAPIKey: prefix %s is already taken, generating a new key", prefix)
+			continue
+		}
+		if err != nil {
+			s.log.Errorf("CreateAPIKey: failed to create api key for user %d: %v", userID, err)
+			return entity.CreatedAPIKey{}, err
+		}
+
+		s.log.Infof("API key %s created for user %d with scopes %v", prefix, userID, scopes)
+		return entity.CreatedAPIKey{APIKey: key, Key: rawKey}, nil
+	}
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, userID int64) ([]entity.APIKey, error) {
+	keys, err := s.repo.ListAPIKeys(ctx, userID)
+	if err != nil {
+		s.log.Errorf("ListAPIKeys: failed to list api keys for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, userID, keyID int64) error {
+	if err := s.repo.RevokeAPIKey(ctx, userID, keyID); err != nil {
+		if !errors.Is(err, entity.ErrAPIKeyNotFound) {
+			s.log.Errorf("RevokeAPIKey: failed to revoke api key %d of user %d: %v", keyID, userID, err)
+		}
+		return err
+	}
+
+	s.log.Infof("API key %d of user %d revoked", keyID, userID)
+	return nil
+}
+
+// AuthenticateAPIKey находит ключ по префиксу и сверяет хеш секрета.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, rawKey string) (entity.APIKey, error) {
+	prefix, ok := parseAPIKeyPrefix(rawKey)
+	if !ok {
+		s.log.Warn("AuthenticateAPIKey: malformed api key")
+		return entity.APIKey{}, entity.ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.log.Warnf("AuthenticateAPIKey: unknown api key %s", prefix)
+			return entity.APIKey{}, entity.ErrInvalidAPIKey
+		}
+		s.log.Errorf("AuthenticateAPIKey: failed to fetch api key %s: %v", prefix, err)
+		return entity.APIKey{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(rawKey)), []byte(key.KeyHash)) != 1 {
+		s.log.Warnf("AuthenticateAPIKey: invalid secret for api key %s", prefix)
+		return entity.APIKey{}, entity.ErrInvalidAPIKey
+	}
+	if key.RevokedAt != nil || time.Now().After(key.ExpiresAt) {
+		s.log.Warnf("AuthenticateAPIKey: revoked or expired api key %s", prefix)
+		return entity.APIKey{}, entity.ErrInvalidAPIKey
+	}
+
+	if err := s.repo.TouchAPIKey(ctx, key.ID, time.Now(), apiKeyTouchInterval); err != nil {
+		s.log.Warnf("AuthenticateAPIKey: failed to update last use of api key %s: %v", prefix, err)
+	}
+
+	return key, nil
+}
+
+func parseAPIKeyPrefix(rawKey string) (string, bool) {
+	rest, ok := strings.CutPrefix(rawKey, apiKeyPrefix)
+	if !ok {
+		return "", false
+	}
+
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != apiKeyPrefixSize*2 || secret == "" {
+		return "", false
+	}
+
+	return prefix, true
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", entity.ErrInvalidScope)
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !isKnownScope(scope) {
+			return nil, fmt.Errorf("%w: %s", entity.ErrInvalidScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+
+	return result, nil
+}
+
+func isKnownScope(scope string) bool {
+	for _, known := range entity.APIKeyScopes {
+		if scope == known {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAPIKeyRepository(ctrl)
+	apiKeyService := NewAPIKeyService(mockRepo, time.Hour, logrus.New())
+
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name         string
+		input        entity.CreateAPIKeyRequest
+		mockBehavior func()
+		wantErr      error
+		wantScopes   []string
+	}{
+		{
+			name:  "Success",
+			input: entity.CreateAPIKeyRequest{Name: "bot", Scopes: []string{entity.ScopeInfo, entity.ScopeBuy, entity.ScopeInfo}},
+			mockBehavior: func() {
+				mockRepo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, key entity.APIKey) (entity.APIKey, error) {
+						assert.Equal(t, testUserID, key.UserID)
+						assert.Len(t, key.Prefix, apiKeyPrefixSize*2)
+						assert.NotEmpty(t, key.KeyHash)
+						assert.WithinDuration(t, time.Now().Add(time.Hour), key.ExpiresAt, time.Minute)
+						key.ID = 7
+						return key, nil
+					})
+			},
+			wantScopes: []string{entity.ScopeInfo, entity.ScopeBuy},
+		},
+		{
+			name:  "Explicit Expiration",
+			input: entity.CreateAPIKeyRequest{Name: "bot", Scopes: []string{entity.ScopeSendCoin}, ExpiresAt: &future},
+			mockBehavior: func() {
+				mockRepo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, key entity.APIKey) (entity.APIKey, error) {
+						assert.True(t, future.Equal(key.ExpiresAt))
+						return key, nil
+					})
+			},
+			wantScopes: []string{entity.ScopeSendCoin},
+		},
+		{
+			name:  "Prefix Collision",
+			input: entity.CreateAPIKeyRequest{Name: "bot", Scopes: []string{entity.ScopeInfo}},
+			mockBehavior: func() {
+				var takenPrefix string
+				gomock.InOrder(
+					mockRepo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, key entity.APIKey) (entity.APIKey, error) {
+							takenPrefix = key.Prefix
+							return entity.APIKey{}, entity.ErrAPIKeyPrefixTaken
+						}),
+					mockRepo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, key entity.APIKey) (entity.APIKey, error) {
+							assert.NotEqual(t, takenPrefix, key.Prefix)
+							return key, nil
+						}),
+				)
+			},
+			wantScopes: []string{entity.ScopeInfo},
+		},
+		{
+			name:  "Prefix Collisions Exhausted",
+			input: entity.CreateAPIKeyRequest{Name: "bot", Scopes: []string{entity.ScopeInfo}},
+			mockBehavior: func() {
+				mockRepo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).
+					Return(entity.APIKey{}, entity.ErrAPIKeyPrefixTaken).
+					Times(apiKeyCreateAttempts)
+			},
+			wantErr: entity.ErrAPIKeyPrefixTaken,
+		},
+		{
+			name:         "Unknown Scope",
+			input:        entity.CreateAPIKeyRequest{Name: "bot", Scopes: []string{"admin"}},
+			mockBehavior: func() {},
+			wantErr:      entity.ErrInvalidScope,
+		},
+		{
+			name:         "Empty Scopes",
+			input:        entity.CreateAPIKeyRequest{Name: "bot", Scopes: []string{}},
+			mockBehavior: func() {},
+			wantErr:      entity.ErrInvalidScope,
+		},
+		{
+			name:         "Expiration In The Past",
+			input:        entity.CreateAPIKeyRequest{Name: "bot", Scopes: []string{entity.ScopeInfo}, ExpiresAt: &past},
+			mockBehavior: func() {},
+			wantErr:      entity.ErrInvalidExpiration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			created, err := apiKeyService.CreateAPIKey(context.Background(), testUserID, tt.input)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantScopes, created.Scopes)
+			assert.True(t, strings.HasPrefix(created.Key, apiKeyPrefix+created.Prefix+"_"))
+			assert.Equal(t, hashToken(created.Key), created.KeyHash)
+		})
+	}
+}
+
+func TestAPIKeyService_AuthenticateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAPIKeyRepository(ctrl)
+	apiKeyService := NewAPIKeyService(mockRepo, time.Hour, logrus.New())
+
+	const (
+		prefix = "0a1b2c3d"
+		rawKey = apiKeyPrefix + prefix + "_secret"
+	)
+	revokedAt := time.Now().Add(-time.Minute)
+
+	validKey := entity.APIKey{
+		ID:        7,
+		UserID:    testUserID,
+		Prefix:    prefix,
+		KeyHash:   hashToken(rawKey),
+		Scopes:    []string{entity.ScopeInfo},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	expiredKey := validKey
+	expiredKey.ExpiresAt = time.Now().Add(-time.Minute)
+	revokedKey := validKey
+	revokedKey.RevokedAt = &revokedAt
+
+	tests := []struct {
+		name         string
+		rawKey       string
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name:   "Success",
+			rawKey: rawKey,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), prefix).Return(validKey, nil)
+				mockRepo.EXPECT().TouchAPIKey(gomock.Any(), int64(7), gomock.Any(), apiKeyTouchInterval).Return(nil)
+			},
+		},
+		{
+			name:   "Touch Failure Is Ignored",
+			rawKey: rawKey,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), prefix).Return(validKey, nil)
+				mockRepo.EXPECT().TouchAPIKey(gomock.Any(), int64(7), gomock.Any(), apiKeyTouchInterval).Return(errors.New("db error"))
+			},
+		},
+		{
+			name:         "Malformed Key",
+			rawKey:       "not-a-key",
+			mockBehavior: func() {},
+			wantErr:      entity.ErrInvalidAPIKey,
+		},
+		{
+			name:   "Unknown Prefix",
+			rawKey: rawKey,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), prefix).Return(entity.APIKey{}, sql.ErrNoRows)
+			},
+			wantErr: entity.ErrInvalidAPIKey,
+		},
+		{
+			name:   "Wrong Secret",
+			rawKey: apiKeyPrefix + prefix + "_other",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), prefix).Return(validKey, nil)
+			},
+			wantErr: entity.ErrInvalidAPIKey,
+		},
+		{
+			name:   "Expired Key",
+			rawKey: rawKey,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), prefix).Return(expiredKey, nil)
+			},
+			wantErr: entity.ErrInvalidAPIKey,
+		},
+		{
+			name:   "Revoked Key",
+			rawKey: rawKey,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), prefix).Return(revokedKey, nil)
+			},
+			wantErr: entity.ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			key, err := apiKeyService.AuthenticateAPIKey(context.Background(), tt.rawKey)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testUserID, key.UserID)
+		})
+	}
+}
+
+func TestAPIKeyService_RevokeAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAPIKeyRepository(ctrl)
+	apiKeyService := NewAPIKeyService(mockRepo, time.Hour, logrus.New())
+
+	mockRepo.EXPECT().RevokeAPIKey(gomock.Any(), testUserID, int64(7)).Return(nil)
+	assert.NoError(t, apiKeyService.RevokeAPIKey(context.Background(), testUserID, 7))
+
+	mockRepo.EXPECT().RevokeAPIKey(gomock.Any(), testUserID, int64(8)).Return(entity.ErrAPIKeyNotFound)
+	assert.ErrorIs(t, apiKeyService.RevokeAPIKey(context.Background(), testUserID, 8), entity.ErrAPIKeyNotFound)
+}
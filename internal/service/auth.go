@@ -2,41 +2,69 @@ package service
 
 import (
 	"context"
-	"crypto/sha256"
-	"fmt"
+	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/sirupsen/logrus"
 
 	"github.com/senyabanana/shop-service/internal/entity"
 	"github.com/senyabanana/shop-service/internal/repository"
 )
 
-const (
-	salt     = "random_salt_string"
-	tokenTTL = 12 * time.Hour
-)
-
-type tokenClaims struct {
-	jwt.StandardClaims
-	UserID int64 `json:"user_id"`
+type AuthConfig struct {
+	Keyring            *Keyring
+	Policy             CredentialsPolicy
+	Throttle           LoginThrottleConfig
+	TwoFactor          TwoFactorConfig
+	AccessTokenTTL     time.Duration
+	RefreshTokenTTL    time.Duration
+	RevocationCacheTTL time.Duration
+	APIKeyTTL          time.Duration
+	SignupGrant        SignupGrantPolicy
 }
 
 type AuthService struct {
-	userRepo     repository.UserRepository
-	trManager    *manager.Manager
-	jwtSecretKey string
-	log          *logrus.Logger
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	revocationRepo   repository.TokenRevocationRepository
+	loginAttemptRepo repository.LoginAttemptRepository
+	twoFactorRepo    repository.TwoFactorRepository
+	transactionRepo  repository.TransactionRepository
+	ledger           ledgerWriter
+	trManager        *manager.Manager
+	hasher           PasswordHasher
+	revocations      *revocationCache
+	cfg              AuthConfig
+	log              *logrus.Logger
 }
 
-func NewAuthService(repo repository.UserRepository, trManager *manager.Manager, jwtSecretKey string, log *logrus.Logger) *AuthService {
+func NewAuthService(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	revocationRepo repository.TokenRevocationRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	transactionRepo repository.TransactionRepository,
+	ledgerRepo repository.LedgerRepository,
+	trManager *manager.Manager,
+	hasher PasswordHasher,
+	cfg AuthConfig,
+	log *logrus.Logger) *AuthService {
 	return &AuthService{
-		userRepo:     repo,
-		trManager:    trManager,
-		jwtSecretKey: jwtSecretKey,
-		log:          log,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revocationRepo:   revocationRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		twoFactorRepo:    twoFactorRepo,
+		transactionRepo:  transactionRepo,
+		ledger:           ledgerWriter{repo: ledgerRepo},
+		trManager:        trManager,
+		hasher:           hasher,
+		revocations:      newRevocationCache(cfg.RevocationCacheTTL, cfg.AccessTokenTTL),
+		cfg:              cfg,
+		log:              log,
 	}
 }
 
@@ -51,73 +79,190 @@ func (s *AuthService) GetUser(ctx context.Context, username string) (entity.User
 }
 
 func (s *AuthService) CreateUser(ctx context.Context, username, password string) error {
-	hashedPassword := generatePasswordHash(password)
+	_, err := s.createUser(ctx, username, password)
+	return err
+}
+
+func (s *AuthService) Register(ctx context.Context, username, password string) (entity.AuthResponse, error) {
+	userID, err := s.createUser(ctx, username, password)
+	if err != nil {
+		return entity.AuthResponse{}, err
+	}
+
+	tokens, err := s.startSession(ctx, userID, entity.RoleUser)
+	if err != nil {
+		s.log.Errorf("Register: failed to issue tokens for user %s: %v", username, err)
+		return entity.AuthResponse{}, err
+	}
 
+	return tokens, nil
+}
+
+func (s *AuthService) createUser(ctx context.Context, username, password string) (int64, error) {
+	if err := s.cfg.Policy.Validate(username, password); err != nil {
+		s.log.Warnf("Failed to create user %s: %v", username, err)
+		return 0, err
+	}
+
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		s.log.Errorf("Failed to hash password for user %s: %v", username, err)
+		return 0, err
+	}
+
+	grant := s.cfg.SignupGrant.Grant(username)
 	newUser := entity.User{
 		Username: username,
 		Password: hashedPassword,
-		Coins:    1000,
+		Coins:    grant,
 	}
 
-	_, err := s.userRepo.CreateUser(ctx, newUser)
+	var userID int64
+	err = s.trManager.Do(ctx, func(ctx context.Context) error {
+		userID, err = s.userRepo.CreateUser(ctx, newUser)
+		if err != nil {
+			s.log.Errorf("Failed to create user %s: %v", username, err)
+			return err
+		}
+
+		if err = s.ledger.openAccount(ctx, userID); err != nil {
+			s.log.Errorf("Failed to open ledger account for user %s: %v", username, err)
+			return err
+		}
+
+		if grant == 0 {
+			return nil
+		}
+
+		transaction, err := s.transactionRepo.InsertTransaction(ctx, entity.Transaction{
+			Type:          entity.TransactionTypeSignupGrant,
+			FromUserID:    userID,
+			Amount:        grant,
+			SenderBalance: &grant,
+		})
+		if err != nil {
+			s.log.Errorf("Failed to record signup grant for user %s: %v", username, err)
+			return err
+		}
+
+		if err = s.ledger.signupGrant(ctx, transaction.ID, userID, grant); err != nil {
+			s.log.Errorf("Failed to post signup grant for user %s to ledger: %v", username, err)
+			return err
+		}
+
+		return nil
+	})
 	if err != nil {
-		s.log.Errorf("Failed to create user %s: %v", username, err)
-		return err
+		return 0, err
 	}
 
-	s.log.Infof("User %s created successfully", username)
-	return nil
+	s.log.Infof("User %s created successfully with %d coins", username, grant)
+	return userID, nil
 }
 
-func (s *AuthService) GenerateToken(ctx context.Context, username, password string) (string, error) {
+func (s *AuthService) GenerateToken(ctx context.Context, username, password, clientIP string) (entity.AuthResponse, error) {
+	if s.cfg.Throttle.enabled() {
+		if err := s.checkLoginLockout(ctx, username, clientIP); err != nil {
+			return entity.AuthResponse{}, err
+		}
+	}
+
 	user, err := s.userRepo.GetUser(ctx, username)
 	if err != nil {
 		s.log.Warnf("GenerateToken: User %s not found", username)
-		return "", err
+		if errors.Is(err, sql.ErrNoRows) {
+			s.registerLoginFailure(ctx, username, clientIP)
+			return entity.AuthResponse{}, entity.ErrUserNotFound
+		}
+		return entity.AuthResponse{}, err
 	}
 
-	hashedPassword := generatePasswordHash(password)
-	if user.Password != hashedPassword {
+	ok, err := s.hasher.Verify(password, user.Password)
+	if err != nil {
+		s.log.Errorf("GenerateToken: failed to verify password for user %s: %v", username, err)
+		return entity.AuthResponse{}, err
+	}
+	if !ok {
 		s.log.Warnf("GenerateToken: Invalid password for user %s", username)
-		return "", entity.ErrIncorrectPassword
+		s.registerLoginFailure(ctx, username, clientIP)
+		return entity.AuthResponse{}, entity.ErrIncorrectPassword
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
-		user.ID,
-	})
+	s.rehashPassword(ctx, user, password)
+
+	// Счетчик неудач сбрасывается только после второго фактора, иначе знание пароля
+	// позволяло бы перебирать коды без блокировки.
+	if user.TwoFactorEnabled {
+		challengeToken, err := s.generateChallengeToken(user.ID)
+		if err != nil {
+			s.log.Errorf("GenerateToken: failed to issue challenge token for user %s: %v", username, err)
+			return entity.AuthResponse{}, err
+		}
+
+		s.log.Infof("GenerateToken: user %s must pass two-factor authentication", username)
+		return entity.AuthResponse{}, &entity.TwoFactorRequiredError{ChallengeToken: challengeToken}
+	}
+
+	s.resetLoginFailures(ctx, username)
+
+	tokens, err := s.startSession(ctx, user.ID, user.Role)
+	if err != nil {
+		s.log.Errorf("GenerateToken: failed to issue tokens for user %s: %v", username, err)
+		return entity.AuthResponse{}, err
+	}
 
 	s.log.Infof("Generated token for user %s", username)
-	return token.SignedString([]byte(s.jwtSecretKey))
+	return tokens, nil
 }
 
-func (s *AuthService) ParseToken(accessToken string) (int64, error) {
-	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			s.log.Warn("ParseToken: invalid signing method")
-			return nil, entity.ErrInvalidSigningMethod
-		}
+// rehashPassword переводит хеш пароля на текущий алгоритм после успешного входа.
+// Ошибка обновления не должна мешать входу, поэтому она только логируется.
+func (s *AuthService) rehashPassword(ctx context.Context, user entity.User, password string) {
+	if !s.hasher.NeedsRehash(user.Password) {
+		return
+	}
 
-		return []byte(s.jwtSecretKey), nil
-	})
+	hashedPassword, err := s.hasher.Hash(password)
 	if err != nil {
-		s.log.Warnf("ParseToken: failed to parse token: %s", err.Error())
-		return 0, err
+		s.log.Errorf("Failed to rehash password for user %s: %v", user.Username, err)
+		return
 	}
 
-	claims, ok := token.Claims.(*tokenClaims)
-	if !ok {
-		s.log.Warn("ParseToken: token claims are invalid")
-		return 0, entity.ErrInvalidTokenClaimsType
+	if err := s.userRepo.UpdatePasswordHash(ctx, user.ID, hashedPassword); err != nil {
+		s.log.Errorf("Failed to update password hash for user %s: %v", user.Username, err)
+		return
 	}
 
-	return claims.UserID, nil
+	s.log.Infof("Password hash for user %s upgraded", user.Username)
 }
 
-func generatePasswordHash(password string) string {
-	hash := sha256.Sum256([]byte(password + salt))
-	return fmt.Sprintf("%x", hash)
+// SetUserRole меняет роль пользователя и отзывает его сессии, чтобы токены со старой ролью перестали действовать.
+func (s *AuthService) SetUserRole(ctx context.Context, username, role string) error {
+	if !entity.IsValidRole(role) {
+		s.log.Warnf("SetUserRole: invalid role %q", role)
+		return entity.ErrInvalidRole
+	}
+
+	var userID int64
+	var revokedBefore time.Time
+
+	err := s.trManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		userID, err = s.userRepo.SetUserRole(ctx, username, role)
+		if err != nil {
+			s.log.Errorf("SetUserRole: failed to set role for user %s: %v", username, err)
+			return err
+		}
+
+		revokedBefore, err = s.revokeAllSessions(ctx, userID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.revokeUser(userID, revokedBefore)
+
+	s.log.Infof("User %s now has role %s", username, role)
+	return nil
 }
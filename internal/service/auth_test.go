@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
@@ -25,15 +26,27 @@ const (
 	testPassword   = "testpassword"
 	testUserID     = int64(1)
 	testJWTSecret  = "supersecret"
+
+	testSignupGrant = int64(1000)
 )
 
+var testAuthConfig = AuthConfig{
+	Keyring:            mustNewKeyring(KeyringConfig{LegacySecret: testJWTSecret}),
+	Policy:             CredentialsPolicy{MinPasswordLength: 8},
+	AccessTokenTTL:     15 * time.Minute,
+	RefreshTokenTTL:    24 * time.Hour,
+	RevocationCacheTTL: time.Minute,
+	TwoFactor:          TwoFactorConfig{Issuer: "Shop Service", ChallengeTTL: 5 * time.Minute},
+	SignupGrant:        SignupGrantPolicy{Default: testSignupGrant},
+}
+
 func TestAuthService_GetUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
 	mockLog := logrus.New()
-	authService := NewAuthService(mockRepo, nil, testJWTSecret, mockLog)
+	authService := NewAuthService(mockRepo, nil, nil, nil, nil, nil, nil, nil, newTestHasher(t), testAuthConfig, mockLog)
 
 	tests := []struct {
 		name     string
@@ -80,47 +93,102 @@ func TestAuthService_CreateUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
 	db, mock, _ := sqlmock.New()
 	mockDB := sqlx.NewDb(db, testDriverName)
 	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
 	mockLog := logrus.New()
-	authService := NewAuthService(mockRepo, mockTrManager, testJWTSecret, mockLog)
+	authService := NewAuthService(mockRepo, nil, nil, nil, nil, mockTransactionRepo, mockLedgerRepo, mockTrManager, newTestHasher(t), testAuthConfig, mockLog)
 
 	tests := []struct {
-		name       string
-		username   string
-		password   string
-		mockErr    error
-		wantErr    error
-		wantCommit bool
+		name         string
+		username     string
+		password     string
+		mockBehavior func()
+		wantErr      error
+		wantCommit   bool
 	}{
 		{
-			name:       "Success",
-			username:   testUsername,
-			password:   testPassword,
-			mockErr:    nil,
+			name:     "Success",
+			username: testUsername,
+			password: testPassword,
+			mockBehavior: func() {
+				mockRepo.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, user entity.User) (int64, error) {
+						assert.Equal(t, testSignupGrant, user.Coins)
+						return testUserID, nil
+					})
+				mockLedgerRepo.EXPECT().CreateUserAccount(gomock.Any(), testUserID).Return(int64(10), nil)
+				mockTransactionRepo.EXPECT().
+					InsertTransaction(gomock.Any(), entity.Transaction{
+						Type:          entity.TransactionTypeSignupGrant,
+						FromUserID:    testUserID,
+						Amount:        testSignupGrant,
+						SenderBalance: int64Ptr(testSignupGrant),
+					}).
+					Return(entity.Transaction{ID: 5}, nil)
+				mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), testUserID).Return(int64(10), nil)
+				mockLedgerRepo.EXPECT().GetSystemAccountID(gomock.Any(), entity.LedgerAccountIssuance).Return(int64(2), nil)
+				mockLedgerRepo.EXPECT().
+					CreateJournalEntry(gomock.Any(), entity.JournalEntry{
+						Kind:          entity.JournalSignupGrant,
+						TransactionID: int64Ptr(5),
+						Postings: []entity.LedgerPosting{
+							{AccountID: 2, Amount: -testSignupGrant},
+							{AccountID: 10, Amount: testSignupGrant},
+						},
+					}).
+					Return(int64(1), nil)
+			},
 			wantErr:    nil,
 			wantCommit: true,
 		},
 		{
-			name:       "Username Already Exists",
-			username:   testUsername,
-			password:   testPassword,
-			mockErr:    errors.New("user already exists"),
-			wantErr:    errors.New("user already exists"),
+			name:     "Username Already Exists",
+			username: testUsername,
+			password: testPassword,
+			mockBehavior: func() {
+				mockRepo.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Return(int64(0), entity.ErrUserExists)
+			},
+			wantErr:    entity.ErrUserExists,
+			wantCommit: false,
+		},
+		{
+			name:     "Ledger Account Failure",
+			username: testUsername,
+			password: testPassword,
+			mockBehavior: func() {
+				mockRepo.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Return(testUserID, nil)
+				mockLedgerRepo.EXPECT().CreateUserAccount(gomock.Any(), testUserID).Return(int64(0), errors.New("db error"))
+			},
+			wantErr:    errors.New("db error"),
 			wantCommit: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockRepo.EXPECT().
-				CreateUser(gomock.Any(), gomock.Any()).
-				Return(testUserID, tt.mockErr)
+			mock.ExpectBegin()
+			tt.mockBehavior()
+			if tt.wantCommit {
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
 
 			err := authService.CreateUser(context.Background(), tt.username, tt.password)
 
-			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				assert.ErrorContains(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
 
 			err = mock.ExpectationsWereMet()
 			assert.NoError(t, err)
@@ -128,22 +196,174 @@ func TestAuthService_CreateUser(t *testing.T) {
 	}
 }
 
+func TestAuthService_CreateUserPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	mockLog := logrus.New()
+	authService := NewAuthService(mockRepo, nil, nil, nil, nil, nil, nil, nil, newTestHasher(t), testAuthConfig, mockLog)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{name: "Short Password", username: testUsername, password: "short", wantErr: entity.ErrWeakPassword},
+		{name: "Password Contains Username", username: testUsername, password: "my-testuser-pass", wantErr: entity.ErrWeakPassword},
+		{name: "Invalid Username", username: "a b", password: testPassword, wantErr: entity.ErrInvalidUsername},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+
+			err := authService.CreateUser(context.Background(), tt.username, tt.password)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAuthService_CreateUser_ZeroGrant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+
+	cfg := testAuthConfig
+	cfg.SignupGrant = SignupGrantPolicy{}
+	authService := NewAuthService(mockRepo, nil, nil, nil, nil, nil, mockLedgerRepo, mockTrManager, newTestHasher(t), cfg, logrus.New())
+
+	mock.ExpectBegin()
+	mockRepo.EXPECT().
+		CreateUser(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, user entity.User) (int64, error) {
+			assert.Zero(t, user.Coins)
+			return testUserID, nil
+		})
+	mockLedgerRepo.EXPECT().CreateUserAccount(gomock.Any(), testUserID).Return(int64(10), nil)
+	mock.ExpectCommit()
+
+	err := authService.CreateUser(context.Background(), testUsername, testPassword)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthService_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	mockRefreshRepo := mocks.NewMockRefreshTokenRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	mockLog := logrus.New()
+	authService := NewAuthService(mockRepo, mockRefreshRepo, nil, nil, nil, mockTransactionRepo, mockLedgerRepo, mockTrManager, newTestHasher(t), testAuthConfig, mockLog)
+
+	tests := []struct {
+		name         string
+		username     string
+		password     string
+		mockBehavior func()
+		wantErr      error
+		wantTokens   bool
+	}{
+		{
+			name:     "Success",
+			username: testUsername,
+			password: testPassword,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRepo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(testUserID, nil)
+				mockLedgerRepo.EXPECT().CreateUserAccount(gomock.Any(), testUserID).Return(int64(10), nil)
+				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), gomock.Any()).Return(entity.Transaction{ID: 5}, nil)
+				mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), testUserID).Return(int64(10), nil)
+				mockLedgerRepo.EXPECT().GetSystemAccountID(gomock.Any(), entity.LedgerAccountIssuance).Return(int64(2), nil)
+				mockLedgerRepo.EXPECT().CreateJournalEntry(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				mock.ExpectCommit()
+				mockRefreshRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, token entity.RefreshToken) error {
+						assert.Equal(t, testUserID, token.UserID)
+						return nil
+					})
+			},
+			wantErr:    nil,
+			wantTokens: true,
+		},
+		{
+			name:     "User Exists",
+			username: testUsername,
+			password: testPassword,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRepo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(int64(0), entity.ErrUserExists)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrUserExists,
+		},
+		{
+			name:         "Weak Password",
+			username:     testUsername,
+			password:     "short",
+			mockBehavior: func() {},
+			wantErr:      entity.ErrWeakPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			tokens, err := authService.Register(context.Background(), tt.username, tt.password)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tt.wantTokens {
+				assert.NotEmpty(t, tokens.Token)
+				assert.NotEmpty(t, tokens.RefreshToken)
+			} else {
+				assert.Empty(t, tokens)
+			}
+		})
+	}
+}
+
 func TestAuthService_GenerateToken(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
+	mockRefreshRepo := mocks.NewMockRefreshTokenRepository(ctrl)
 	mockLog := logrus.New()
-	authService := NewAuthService(mockRepo, nil, testJWTSecret, mockLog)
+	hasher := newTestHasher(t)
+	authService := NewAuthService(mockRepo, mockRefreshRepo, nil, nil, nil, nil, nil, nil, hasher, testAuthConfig, mockLog)
+
+	currentHash, err := hasher.Hash("validPass")
+	assert.NoError(t, err)
 
 	tests := []struct {
-		name      string
-		username  string
-		password  string
-		mockUser  entity.User
-		mockErr   error
-		wantErr   error
-		wantToken bool
+		name         string
+		username     string
+		password     string
+		mockUser     entity.User
+		mockErr      error
+		mockBehavior func()
+		wantErr      error
+		wantToken    bool
 	}{
 		{
 			name:     "Success",
@@ -152,20 +372,84 @@ func TestAuthService_GenerateToken(t *testing.T) {
 			mockUser: entity.User{
 				ID:       1,
 				Username: "validUser",
+				Password: currentHash,
+			},
+			mockErr: nil,
+			mockBehavior: func() {
+				mockRefreshRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr:   nil,
+			wantToken: true,
+		},
+		{
+			name:     "Legacy Hash Upgraded",
+			username: "legacyUser",
+			password: "validPass",
+			mockUser: entity.User{
+				ID:       2,
+				Username: "legacyUser",
 				Password: generatePasswordHash("validPass"),
 			},
-			mockErr:   nil,
+			mockErr: nil,
+			mockBehavior: func() {
+				mockRepo.EXPECT().
+					UpdatePasswordHash(gomock.Any(), int64(2), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int64, hash string) error {
+						ok, err := hasher.Verify("validPass", hash)
+						assert.NoError(t, err)
+						assert.True(t, ok)
+						assert.False(t, hasher.NeedsRehash(hash))
+						return nil
+					})
+				mockRefreshRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
 			wantErr:   nil,
 			wantToken: true,
 		},
 		{
-			name:      "User Not Found",
-			username:  "unknownUser",
-			password:  "anyPass",
-			mockErr:   entity.ErrUserNotFound,
-			wantErr:   entity.ErrUserNotFound,
+			name:     "Rehash Failure Does Not Block Login",
+			username: "legacyUser",
+			password: "validPass",
+			mockUser: entity.User{
+				ID:       2,
+				Username: "legacyUser",
+				Password: generatePasswordHash("validPass"),
+			},
+			mockErr: nil,
+			mockBehavior: func() {
+				mockRepo.EXPECT().
+					UpdatePasswordHash(gomock.Any(), int64(2), gomock.Any()).
+					Return(errors.New("db error"))
+				mockRefreshRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr:   nil,
+			wantToken: true,
+		},
+		{
+			name:     "Refresh Token Store Failure",
+			username: "validUser",
+			password: "validPass",
+			mockUser: entity.User{
+				ID:       1,
+				Username: "validUser",
+				Password: currentHash,
+			},
+			mockErr: nil,
+			mockBehavior: func() {
+				mockRefreshRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+			},
+			wantErr:   errors.New("db error"),
 			wantToken: false,
 		},
+		{
+			name:         "User Not Found",
+			username:     "unknownUser",
+			password:     "anyPass",
+			mockErr:      entity.ErrUserNotFound,
+			mockBehavior: func() {},
+			wantErr:      entity.ErrUserNotFound,
+			wantToken:    false,
+		},
 		{
 			name:     "Incorrect Password",
 			username: "validUser",
@@ -173,11 +457,26 @@ func TestAuthService_GenerateToken(t *testing.T) {
 			mockUser: entity.User{
 				ID:       1,
 				Username: "validUser",
+				Password: currentHash,
+			},
+			mockErr:      nil,
+			mockBehavior: func() {},
+			wantErr:      entity.ErrIncorrectPassword,
+			wantToken:    false,
+		},
+		{
+			name:     "Incorrect Password With Legacy Hash",
+			username: "legacyUser",
+			password: "wrongPass",
+			mockUser: entity.User{
+				ID:       2,
+				Username: "legacyUser",
 				Password: generatePasswordHash("validPass"),
 			},
-			mockErr:   nil,
-			wantErr:   entity.ErrIncorrectPassword,
-			wantToken: false,
+			mockErr:      nil,
+			mockBehavior: func() {},
+			wantErr:      entity.ErrIncorrectPassword,
+			wantToken:    false,
 		},
 	}
 
@@ -186,58 +485,368 @@ func TestAuthService_GenerateToken(t *testing.T) {
 			mockRepo.EXPECT().
 				GetUser(gomock.Any(), tt.username).
 				Return(tt.mockUser, tt.mockErr)
+			tt.mockBehavior()
 
-			token, err := authService.GenerateToken(context.Background(), tt.username, tt.password)
+			tokens, err := authService.GenerateToken(context.Background(), tt.username, tt.password, "127.0.0.1")
 
 			assert.Equal(t, tt.wantErr, err)
 
 			if tt.wantToken {
-				assert.NotEmpty(t, token)
+				assert.NotEmpty(t, tokens.Token)
+				assert.NotEmpty(t, tokens.RefreshToken)
 			} else {
-				assert.Empty(t, token)
+				assert.Empty(t, tokens)
 			}
 		})
 	}
 }
 
 func TestAuthService_ParseToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRevocationRepo := mocks.NewMockTokenRevocationRepository(ctrl)
 	mockLog := logrus.New()
-	authService := NewAuthService(nil, nil, testJWTSecret, mockLog)
+	authService := NewAuthService(nil, nil, mockRevocationRepo, nil, nil, nil, nil, nil, newTestHasher(t), testAuthConfig, mockLog)
 
-	validToken := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(1 * time.Hour).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
-		1,
-	})
-	validTokenString, _ := validToken.SignedString([]byte(testJWTSecret))
+	signToken := func(id, secret string, expiresAt time.Time) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
+			StandardClaims: jwt.StandardClaims{
+				Id:        id,
+				ExpiresAt: expiresAt.Unix(),
+				IssuedAt:  time.Now().Unix(),
+			},
+			UserID: 1,
+		})
+		tokenString, _ := token.SignedString([]byte(secret))
+		return tokenString
+	}
 
 	tests := []struct {
-		name       string
-		token      string
-		wantUserID int64
-		wantErr    error
+		name         string
+		token        string
+		mockBehavior func()
+		wantUserID   int64
+		wantErr      error
 	}{
 		{
-			name:       "Valid Token",
-			token:      validTokenString,
+			name:  "Valid Token",
+			token: signToken("valid", testJWTSecret, time.Now().Add(time.Hour)),
+			mockBehavior: func() {
+				mockRevocationRepo.EXPECT().IsTokenRevoked(gomock.Any(), "valid", int64(1), gomock.Any()).Return(false, nil)
+			},
 			wantUserID: 1,
 			wantErr:    nil,
 		},
+		{
+			name:         "Valid Token From Cache",
+			token:        signToken("valid", testJWTSecret, time.Now().Add(time.Hour)),
+			mockBehavior: func() {},
+			wantUserID:   1,
+			wantErr:      nil,
+		},
+		{
+			name:  "Revoked Token",
+			token: signToken("revoked", testJWTSecret, time.Now().Add(time.Hour)),
+			mockBehavior: func() {
+				mockRevocationRepo.EXPECT().IsTokenRevoked(gomock.Any(), "revoked", int64(1), gomock.Any()).Return(true, nil)
+			},
+			wantErr: entity.ErrTokenRevoked,
+		},
+		{
+			name:         "Revoked Token From Cache",
+			token:        signToken("revoked", testJWTSecret, time.Now().Add(time.Hour)),
+			mockBehavior: func() {},
+			wantErr:      entity.ErrTokenRevoked,
+		},
+		{
+			name:  "Revocation Check Failure",
+			token: signToken("unknown", testJWTSecret, time.Now().Add(time.Hour)),
+			mockBehavior: func() {
+				mockRevocationRepo.EXPECT().IsTokenRevoked(gomock.Any(), "unknown", int64(1), gomock.Any()).Return(false, errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name:         "Token Without ID",
+			token:        signToken("", testJWTSecret, time.Now().Add(time.Hour)),
+			mockBehavior: func() {},
+			wantErr:      entity.ErrInvalidToken,
+		},
+		{
+			name:         "Expired Token",
+			token:        signToken("expired", testJWTSecret, time.Now().Add(-time.Hour)),
+			mockBehavior: func() {},
+			wantErr:      entity.ErrInvalidToken,
+		},
+		{
+			name:         "Wrong Signature",
+			token:        signToken("forged", "another-secret", time.Now().Add(time.Hour)),
+			mockBehavior: func() {},
+			wantErr:      entity.ErrInvalidToken,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			userID, err := authService.ParseToken(tt.token)
+			tt.mockBehavior()
+
+			claims, err := authService.ParseToken(context.Background(), tt.token)
 
 			assert.Equal(t, tt.wantErr, err)
 
 			if err == nil {
-				assert.Equal(t, tt.wantUserID, userID)
+				assert.Equal(t, tt.wantUserID, claims.UserID)
+			} else {
+				assert.Zero(t, claims.UserID)
+			}
+		})
+	}
+}
+
+func TestAuthService_RefreshToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockRefreshRepo := mocks.NewMockRefreshTokenRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	mockLog := logrus.New()
+	authService := NewAuthService(mockUserRepo, mockRefreshRepo, nil, nil, nil, nil, nil, mockTrManager, newTestHasher(t), testAuthConfig, mockLog)
+
+	const refreshToken = "refresh-token"
+	usedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantErr      error
+		wantTokens   bool
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRefreshRepo.EXPECT().GetRefreshTokenForUpdate(gomock.Any(), hashToken(refreshToken)).
+					Return(entity.RefreshToken{ID: 1, UserID: testUserID, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				mockRefreshRepo.EXPECT().MarkRefreshTokenUsed(gomock.Any(), int64(1)).Return(nil)
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).
+					Return(entity.User{ID: testUserID, Username: testUsername, Role: entity.RoleUser}, nil)
+				mockRefreshRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, token entity.RefreshToken) error {
+						assert.Equal(t, testUserID, token.UserID)
+						assert.Equal(t, "family", token.FamilyID)
+						return nil
+					})
+				mock.ExpectCommit()
+			},
+			wantErr:    nil,
+			wantTokens: true,
+		},
+		{
+			name: "User Lookup Failure",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRefreshRepo.EXPECT().GetRefreshTokenForUpdate(gomock.Any(), hashToken(refreshToken)).
+					Return(entity.RefreshToken{ID: 1, UserID: testUserID, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				mockRefreshRepo.EXPECT().MarkRefreshTokenUsed(gomock.Any(), int64(1)).Return(nil)
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(entity.User{}, errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name: "Unknown Token",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRefreshRepo.EXPECT().GetRefreshTokenForUpdate(gomock.Any(), hashToken(refreshToken)).
+					Return(entity.RefreshToken{}, sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrInvalidRefreshToken,
+		},
+		{
+			name: "Expired Token",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRefreshRepo.EXPECT().GetRefreshTokenForUpdate(gomock.Any(), hashToken(refreshToken)).
+					Return(entity.RefreshToken{ID: 1, UserID: testUserID, FamilyID: "family", ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrInvalidRefreshToken,
+		},
+		{
+			name: "Revoked Token",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRefreshRepo.EXPECT().GetRefreshTokenForUpdate(gomock.Any(), hashToken(refreshToken)).
+					Return(entity.RefreshToken{ID: 1, UserID: testUserID, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &usedAt}, nil)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrInvalidRefreshToken,
+		},
+		{
+			name: "Reused Token Revokes Family",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRefreshRepo.EXPECT().GetRefreshTokenForUpdate(gomock.Any(), hashToken(refreshToken)).
+					Return(entity.RefreshToken{ID: 1, UserID: testUserID, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}, nil)
+				mockRefreshRepo.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), "family").Return(nil)
+				mock.ExpectCommit()
+			},
+			wantErr: entity.ErrRefreshTokenReused,
+		},
+		{
+			name: "Database Error",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRefreshRepo.EXPECT().GetRefreshTokenForUpdate(gomock.Any(), hashToken(refreshToken)).
+					Return(entity.RefreshToken{}, errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			tokens, err := authService.RefreshToken(context.Background(), refreshToken)
+
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantTokens {
+				assert.NotEmpty(t, tokens.Token)
+				assert.NotEmpty(t, tokens.RefreshToken)
+				assert.NotEqual(t, refreshToken, tokens.RefreshToken)
 			} else {
-				assert.Zero(t, userID)
+				assert.Empty(t, tokens)
 			}
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
+
+func TestAuthService_SetUserRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockRefreshRepo := mocks.NewMockRefreshTokenRepository(ctrl)
+	mockRevocationRepo := mocks.NewMockTokenRevocationRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	mockLog := logrus.New()
+	authService := NewAuthService(mockUserRepo, mockRefreshRepo, mockRevocationRepo, nil, nil, nil, nil, mockTrManager, newTestHasher(t), testAuthConfig, mockLog)
+
+	tests := []struct {
+		name         string
+		role         string
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			role: entity.RoleAdmin,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().SetUserRole(gomock.Any(), testUsername, entity.RoleAdmin).Return(testUserID, nil)
+				mockRevocationRepo.EXPECT().RevokeUserTokens(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockRefreshRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), testUserID).Return(nil)
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name:         "Invalid Role",
+			role:         "root",
+			mockBehavior: func() {},
+			wantErr:      entity.ErrInvalidRole,
+		},
+		{
+			name: "User Not Found",
+			role: entity.RoleAdmin,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().SetUserRole(gomock.Any(), testUsername, entity.RoleAdmin).Return(int64(0), entity.ErrUserNotFound)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := authService.SetUserRole(context.Background(), testUsername, tt.role)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuthService_SetUserRole_RollbackKeepsCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockRefreshRepo := mocks.NewMockRefreshTokenRepository(ctrl)
+	mockRevocationRepo := mocks.NewMockTokenRevocationRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	authService := NewAuthService(mockUserRepo, mockRefreshRepo, mockRevocationRepo, nil, nil, nil, nil, mockTrManager, newTestHasher(t), testAuthConfig, logrus.New())
+
+	mock.ExpectBegin()
+	mockUserRepo.EXPECT().SetUserRole(gomock.Any(), testUsername, entity.RoleAdmin).Return(testUserID, nil)
+	mockRevocationRepo.EXPECT().RevokeUserTokens(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+	mockRefreshRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), testUserID).Return(nil)
+	mock.ExpectCommit().WillReturnError(errors.New("commit error"))
+
+	err := authService.SetUserRole(context.Background(), testUsername, entity.RoleAdmin)
+	assert.Error(t, err)
+
+	claims := entity.TokenClaims{
+		UserID:    testUserID,
+		TokenID:   "old",
+		IssuedAt:  time.Now().Add(-time.Minute),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	mockRevocationRepo.EXPECT().IsTokenRevoked(gomock.Any(), "old", testUserID, claims.IssuedAt).Return(false, nil)
+
+	revoked, err := authService.isTokenRevoked(context.Background(), claims)
+
+	assert.NoError(t, err)
+	assert.False(t, revoked, "sessions must stay valid when the role change is rolled back")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthService_TokenRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRevocationRepo := mocks.NewMockTokenRevocationRepository(ctrl)
+	mockLog := logrus.New()
+	authService := NewAuthService(nil, nil, mockRevocationRepo, nil, nil, nil, nil, nil, newTestHasher(t), testAuthConfig, mockLog)
+
+	mockRevocationRepo.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any(), testUserID, gomock.Any()).Return(false, nil).Times(2)
+
+	adminToken, err := authService.generateAccessToken(testUserID, entity.RoleAdmin)
+	assert.NoError(t, err)
+
+	claims, err := authService.ParseToken(context.Background(), adminToken)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.RoleAdmin, claims.Role)
+
+	legacyToken, err := authService.generateAccessToken(testUserID, "")
+	assert.NoError(t, err)
+
+	claims, err = authService.ParseToken(context.Background(), legacyToken)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.RoleUser, claims.Role, "tokens without a role are treated as regular users")
+}
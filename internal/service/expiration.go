@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/sirupsen/logrus"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/repository"
+)
+
+// expireBatchSize — сколько пользователей обрабатывается за один запуск ExpireCoins.
+// Остальные дождутся следующего запуска.
+const expireBatchSize = 100
+
+// ExpirationConfig задает сгорание монет.
+type ExpirationConfig struct {
+	// AllowanceCoinTTL — сколько живут монеты регулярных начислений. Ноль — монеты не сгорают.
+	AllowanceCoinTTL time.Duration
+}
+
+// ExpirationService списывает сгоревшие монеты. Списание пользователя выполняется под блокировкой
+// его строки, поэтому при нескольких репликах одна и та же партия не списывается дважды.
+type ExpirationService struct {
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
+	coinLotRepo     repository.CoinLotRepository
+	ledger          ledgerWriter
+	tx              txRunner
+	log             *logrus.Logger
+}
+
+func NewExpirationService(
+	userRepo repository.UserRepository,
+	transactionRepo repository.TransactionRepository,
+	coinLotRepo repository.CoinLotRepository,
+	ledgerRepo repository.LedgerRepository,
+	trManager *manager.Manager,
+	txCfg TxConfig,
+	log *logrus.Logger) *ExpirationService {
+	return &ExpirationService{
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		coinLotRepo:     coinLotRepo,
+		ledger:          ledgerWriter{repo: ledgerRepo},
+		tx:              txRunner{trManager: trManager, cfg: txCfg, log: log},
+		log:             log,
+	}
+}
+
+// ExpireCoins списывает остатки партий, сгоревших к now, и возвращает общую сумму списания.
+// Ошибка одного пользователя не мешает обработать остальных.
+func (s *ExpirationService) ExpireCoins(ctx context.Context, now time.Time) (int64, error) {
+	userIDs, err := s.coinLotRepo.GetUsersWithExpiredLots(ctx, now, expireBatchSize)
+	if err != nil {
+		s.log.Errorf("ExpireCoins failed: failed to find expired coin lots: %v", err)
+		return 0, err
+	}
+
+	var total int64
+	var errs []error
+
+	for _, userID := range userIDs {
+		expired, err := s.expire(ctx, userID, now)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		total += expired
+	}
+
+	return total, errors.Join(errs...)
+}
+
+func (s *ExpirationService) expire(ctx context.Context, userID int64, now time.Time) (int64, error) {
+	var expired int64
+
+	err := s.tx.do(ctx, opExpireCoins, func(ctx context.Context) error {
+		expired = 0
+
+		balance, err := s.userRepo.GetUserBalanceForUpdate(ctx, userID)
+		if err != nil {
+			s.log.Errorf("ExpireCoins failed: failed to lock balance of user %d: %v", userID, err)
+			return err
+		}
+
+		lots, err := s.coinLotRepo.ExpireCoinLots(ctx, userID, now)
+		if err != nil {
+			s.log.Errorf("ExpireCoins failed: failed to expire coin lots of user %d: %v", userID, err)
+			return err
+		}
+
+		// Баланс может быть меньше остатка партий, если он ушел в минус при принудительном сторно.
+		amount := min(lots, max(balance, 0))
+		if amount == 0 {
+			return nil
+		}
+
+		if err = s.userRepo.UpdateCoins(ctx, userID, -amount); err != nil {
+			s.log.Errorf("ExpireCoins failed: failed to decrease balance for user %d: %v", userID, err)
+			return err
+		}
+
+		newBalance := balance - amount
+
+		transaction, err := s.transactionRepo.InsertTransaction(ctx, entity.Transaction{
+			Type:          entity.TransactionTypeExpiration,
+			FromUserID:    userID,
+			Amount:        amount,
+			SenderBalance: &newBalance,
+		})
+		if err != nil {
+			s.log.Errorf("ExpireCoins failed: failed to insert expiration for user %d: %v", userID, err)
+			return err
+		}
+
+		if err = s.ledger.expire(ctx, transaction.ID, userID, amount); err != nil {
+			s.log.Errorf("ExpireCoins failed: failed to post transaction %d to ledger: %v", transaction.ID, err)
+			return err
+		}
+
+		expired = amount
+		s.log.Infof("Expired %d coins of user %d (transaction %d)", amount, userID, transaction.ID)
+		return nil
+	})
+
+	return expired, err
+}
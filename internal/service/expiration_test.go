@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func TestExpirationService_ExpireCoins(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockCoinLotRepo := mocks.NewMockCoinLotRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+
+	service := NewExpirationService(mockUserRepo, mockTransactionRepo, mockCoinLotRepo, mockLedgerRepo, mockTrManager, TxConfig{MaxAttempts: 1}, logrus.New())
+
+	now := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	expectExpiration := func(userID, transactionID, amount, balance int64) {
+		mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), userID, -amount).Return(nil)
+		mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), entity.Transaction{
+			Type:          entity.TransactionTypeExpiration,
+			FromUserID:    userID,
+			Amount:        amount,
+			SenderBalance: int64Ptr(balance),
+		}).Return(entity.Transaction{ID: transactionID}, nil)
+		mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), userID).Return(userID+10, nil)
+		mockLedgerRepo.EXPECT().GetSystemAccountID(gomock.Any(), entity.LedgerAccountIssuance).Return(int64(2), nil)
+		mockLedgerRepo.EXPECT().CreateJournalEntry(gomock.Any(), entity.JournalEntry{
+			Kind:          entity.JournalExpiration,
+			TransactionID: int64Ptr(transactionID),
+			Postings: []entity.LedgerPosting{
+				{AccountID: userID + 10, Amount: -amount},
+				{AccountID: 2, Amount: amount},
+			},
+		}).Return(transactionID, nil)
+	}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantExpired  int64
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mockCoinLotRepo.EXPECT().GetUsersWithExpiredLots(gomock.Any(), now, expireBatchSize).Return([]int64{1, 2}, nil)
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(800), nil)
+				mockCoinLotRepo.EXPECT().ExpireCoinLots(gomock.Any(), int64(1), now).Return(int64(300), nil)
+				expectExpiration(1, 30, 300, 500)
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(120), nil)
+				mockCoinLotRepo.EXPECT().ExpireCoinLots(gomock.Any(), int64(2), now).Return(int64(200), nil)
+				expectExpiration(2, 31, 120, 0)
+				mock.ExpectCommit()
+			},
+			wantExpired: 420,
+		},
+		{
+			name: "Negative Balance Burns Nothing",
+			mockBehavior: func() {
+				mockCoinLotRepo.EXPECT().GetUsersWithExpiredLots(gomock.Any(), now, expireBatchSize).Return([]int64{3}, nil)
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(3)).Return(int64(-50), nil)
+				mockCoinLotRepo.EXPECT().ExpireCoinLots(gomock.Any(), int64(3), now).Return(int64(100), nil)
+				mock.ExpectCommit()
+			},
+			wantExpired: 0,
+		},
+		{
+			name: "One User Fails",
+			mockBehavior: func() {
+				mockCoinLotRepo.EXPECT().GetUsersWithExpiredLots(gomock.Any(), now, expireBatchSize).Return([]int64{1, 2}, nil)
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(0), errors.New("db error"))
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(1000), nil)
+				mockCoinLotRepo.EXPECT().ExpireCoinLots(gomock.Any(), int64(2), now).Return(int64(200), nil)
+				expectExpiration(2, 31, 200, 800)
+				mock.ExpectCommit()
+			},
+			wantExpired: 200,
+			wantErr:     errors.Join(errors.New("db error")),
+		},
+		{
+			name: "Error Finding Users",
+			mockBehavior: func() {
+				mockCoinLotRepo.EXPECT().GetUsersWithExpiredLots(gomock.Any(), now, expireBatchSize).Return(nil, errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			expired, err := service.ExpireCoins(context.Background(), now)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantExpired, expired)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+const (
+	defaultHistoryPageSize = 20
+	maxHistoryPageSize     = 100
+)
+
+// HistoryConfig задает ограничения на историю операций.
+type HistoryConfig struct {
+	// InfoLimit — сколько последних записей каждого списка истории попадает в /api/info; 0 — все.
+	InfoLimit int
+}
+
+// GetHistory возвращает страницу истории переводов и покупок. Курсор следующей страницы пуст, если страница последняя.
+func (s *TransactionService) GetHistory(ctx context.Context, userID int64, filter entity.HistoryFilter) (entity.HistoryPage, error) {
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return entity.HistoryPage{}, fmt.Errorf("%w: minAmount is greater than maxAmount", entity.ErrInvalidHistoryFilter)
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return entity.HistoryPage{}, fmt.Errorf("%w: from must be before to", entity.ErrInvalidHistoryFilter)
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeHistoryCursor(filter.Cursor)
+		if err != nil {
+			s.log.Warnf("GetHistory failed: invalid cursor for user %d", userID)
+			return entity.HistoryPage{}, err
+		}
+		filter.After = &cursor
+	}
+
+	pageSize := filter.Limit
+	if pageSize <= 0 {
+		pageSize = defaultHistoryPageSize
+	}
+	if pageSize > maxHistoryPageSize {
+		pageSize = maxHistoryPageSize
+	}
+	// Лишняя запись показывает, есть ли следующая страница.
+	filter.Limit = pageSize + 1
+
+	items, err := s.transactionRepo.GetHistory(ctx, userID, filter)
+	if err != nil {
+		s.log.Errorf("GetHistory failed: failed to fetch history for user %d: %v", userID, err)
+		return entity.HistoryPage{}, err
+	}
+
+	page := entity.HistoryPage{Items: items}
+	if len(items) > pageSize {
+		page.Items = items[:pageSize]
+		last := page.Items[pageSize-1]
+		page.NextCursor = encodeHistoryCursor(entity.HistoryCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	if page.Items == nil {
+		page.Items = make([]entity.TransactionDetail, 0)
+	}
+
+	return page, nil
+}
+
+func encodeHistoryCursor(cursor entity.HistoryCursor) string {
+	raw := fmt.Sprintf("%d:%d", cursor.CreatedAt.UnixMicro(), cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeHistoryCursor(value string) (entity.HistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return entity.HistoryCursor{}, entity.ErrInvalidCursor
+	}
+
+	micros, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return entity.HistoryCursor{}, entity.ErrInvalidCursor
+	}
+
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return entity.HistoryCursor{}, entity.ErrInvalidCursor
+	}
+
+	transactionID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || transactionID <= 0 {
+		return entity.HistoryCursor{}, entity.ErrInvalidCursor
+	}
+
+	return entity.HistoryCursor{CreatedAt: time.UnixMicro(createdAt).UTC(), ID: transactionID}, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func TestHistoryCursor_RoundTrip(t *testing.T) {
+	cursor := entity.HistoryCursor{CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 123456000, time.UTC), ID: 42}
+
+	decoded, err := decodeHistoryCursor(encodeHistoryCursor(cursor))
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	for _, value := range []string{"???", "bm90LWEtY3Vyc29y", "MTIzOmFiYw", "MTIzOjA"} {
+		_, err := decodeHistoryCursor(value)
+		assert.ErrorIs(t, err, entity.ErrInvalidCursor, value)
+	}
+}
+
+func TestTransactionService_GetHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	service := NewTransactionService(nil, mockTransactionRepo, nil, nil, nil, nil, nil, HistoryConfig{}, TxConfig{}, logrus.New())
+
+	items := []entity.TransactionDetail{
+		{ID: 3, Direction: entity.DirectionSent, ToUser: "bob", Amount: 10, CreatedAt: testCreatedAt},
+		{ID: 2, Direction: entity.DirectionReceived, FromUser: "alice", Amount: 20, CreatedAt: testCreatedAt},
+		{ID: 1, Direction: entity.DirectionSent, ToUser: "bob", Amount: 30, CreatedAt: testCreatedAt},
+	}
+	cursor := entity.HistoryCursor{CreatedAt: testCreatedAt, ID: 2}
+	minAmount, maxAmount := int64(100), int64(10)
+
+	tests := []struct {
+		name         string
+		filter       entity.HistoryFilter
+		mockBehavior func()
+		wantPage     entity.HistoryPage
+		wantErr      error
+	}{
+		{
+			name:   "Default Page Size",
+			filter: entity.HistoryFilter{},
+			mockBehavior: func() {
+				mockTransactionRepo.EXPECT().GetHistory(gomock.Any(), testUserID, entity.HistoryFilter{Limit: 21}).
+					Return(items, nil)
+			},
+			wantPage: entity.HistoryPage{Items: items},
+		},
+		{
+			name:   "Has Next Page",
+			filter: entity.HistoryFilter{Limit: 2},
+			mockBehavior: func() {
+				mockTransactionRepo.EXPECT().GetHistory(gomock.Any(), testUserID, entity.HistoryFilter{Limit: 3}).
+					Return(items, nil)
+			},
+			wantPage: entity.HistoryPage{Items: items[:2], NextCursor: encodeHistoryCursor(cursor)},
+		},
+		{
+			name:   "With Cursor",
+			filter: entity.HistoryFilter{Cursor: encodeHistoryCursor(cursor), Limit: 2},
+			mockBehavior: func() {
+				mockTransactionRepo.EXPECT().GetHistory(gomock.Any(), testUserID, entity.HistoryFilter{
+					Cursor: encodeHistoryCursor(cursor), After: &cursor, Limit: 3,
+				}).Return(items[2:], nil)
+			},
+			wantPage: entity.HistoryPage{Items: items[2:]},
+		},
+		{
+			name:   "Empty",
+			filter: entity.HistoryFilter{Direction: entity.DirectionReceived},
+			mockBehavior: func() {
+				mockTransactionRepo.EXPECT().GetHistory(gomock.Any(), testUserID, entity.HistoryFilter{
+					Direction: entity.DirectionReceived, Limit: 21,
+				}).Return(nil, nil)
+			},
+			wantPage: entity.HistoryPage{Items: []entity.TransactionDetail{}},
+		},
+		{
+			name:         "Invalid Cursor",
+			filter:       entity.HistoryFilter{Cursor: "???"},
+			mockBehavior: func() {},
+			wantErr:      entity.ErrInvalidCursor,
+		},
+		{
+			name:         "Invalid Amount Range",
+			filter:       entity.HistoryFilter{MinAmount: &minAmount, MaxAmount: &maxAmount},
+			mockBehavior: func() {},
+			wantErr:      entity.ErrInvalidHistoryFilter,
+		},
+		{
+			name:   "Repository Error",
+			filter: entity.HistoryFilter{},
+			mockBehavior: func() {
+				mockTransactionRepo.EXPECT().GetHistory(gomock.Any(), testUserID, gomock.Any()).
+					Return(nil, errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			page, err := service.GetHistory(context.Background(), testUserID, tt.filter)
+
+			if tt.wantErr != nil {
+				assert.ErrorContains(t, err, tt.wantErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPage, page)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/settings"
+	"github.com/sirupsen/logrus"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/repository"
+)
+
+var (
+	// errOperationFailed откатывает точку сохранения операции, завершившейся ответом 4xx.
+	errOperationFailed = errors.New("idempotent operation failed")
+	// errResponseNotStored откатывает всю транзакцию, чтобы ответ 5xx не сохранялся и запрос можно было повторить.
+	errResponseNotStored = errors.New("idempotent response not stored")
+)
+
+type IdempotencyService struct {
+	repo      repository.IdempotencyRepository
+	trManager *manager.Manager
+	tx        txRunner
+	isolation trm.Settings
+	ttl       time.Duration
+	log       *logrus.Logger
+}
+
+func NewIdempotencyService(
+	repo repository.IdempotencyRepository,
+	trManager *manager.Manager,
+	txCfg TxConfig,
+	ttl time.Duration,
+	log *logrus.Logger) *IdempotencyService {
+	return &IdempotencyService{
+		repo:      repo,
+		trManager: trManager,
+		tx:        txRunner{trManager: trManager, cfg: txCfg, log: log},
+		isolation: isolationSettings(txCfg.strictestIsolation()),
+		ttl:       ttl,
+		log:       log,
+	}
+}
+
+// Execute выполняет операцию не более одного раза для пары пользователь+ключ. Операция и сохранение
+// ее ответа идут в одной транзакции, поэтому повтор после таймаута получает тот же ответ, а не второй перевод.
+// Ответ 4xx сохраняется, но изменения операции откатываются до точки сохранения; ответ 5xx не сохраняется.
+// Транзакция открывается на самом строгом из уровней изоляции денежных операций. При serialization failure
+// или deadlock (в операции или при коммите) транзакция повторяется целиком, вместе с резервированием ключа,
+// поэтому операция должна допускать повторный вызов.
+func (s *IdempotencyService) Execute(
+	ctx context.Context,
+	userID int64,
+	key, requestHash string,
+	operation func(ctx context.Context) entity.IdempotentResponse) (entity.IdempotentResponse, error) {
+	var resp entity.IdempotentResponse
+
+	err := s.tx.retry(ctx, opIdempotent, s.isolation, func(ctx context.Context) error {
+		resp = entity.IdempotentResponse{}
+
+		if err := s.repo.DeleteExpiredIdempotencyKeys(ctx, userID, time.Now().Add(-s.ttl)); err != nil {
+			s.log.Errorf("Idempotency: failed to delete expired keys of user %d: %v", userID, err)
+			return err
+		}
+
+		created, err := s.repo.CreateIdempotencyKey(ctx, userID, key, requestHash)
+		if err != nil {
+			s.log.Errorf("Idempotency: failed to reserve key %q of user %d: %v", key, userID, err)
+			return err
+		}
+
+		if !created {
+			record, err := s.repo.GetIdempotencyKey(ctx, userID, key)
+			if err != nil {
+				s.log.Errorf("Idempotency: failed to fetch key %q of user %d: %v", key, userID, err)
+				return err
+			}
+			if record.RequestHash != requestHash {
+				s.log.Warnf("Idempotency: key %q of user %d reused with a different request", key, userID)
+				return entity.ErrIdempotencyKeyConflict
+			}
+
+			s.log.Infof("Idempotency: replaying stored response for key %q of user %d", key, userID)
+			resp = entity.IdempotentResponse{StatusCode: record.StatusCode, Body: record.ResponseBody, Replayed: true}
+			return nil
+		}
+
+		operationCtx, conflict := withConflictReport(ctx)
+		nested := settings.Must(settings.WithPropagation(trm.PropagationNested))
+		err = s.trManager.DoWithSettings(operationCtx, nested, func(ctx context.Context) error {
+			resp = operation(ctx)
+			if resp.StatusCode >= http.StatusBadRequest {
+				return errOperationFailed
+			}
+
+			return nil
+		})
+		if err != nil && !errors.Is(err, errOperationFailed) {
+			return err
+		}
+
+		// Конфликт внутри операции обработчик превратил в ответ; откатываем транзакцию, чтобы повторить ее.
+		if *conflict != nil {
+			return *conflict
+		}
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return errResponseNotStored
+		}
+
+		if err := s.repo.SaveIdempotentResponse(ctx, userID, key, resp.StatusCode, resp.Body); err != nil {
+			s.log.Errorf("Idempotency: failed to save response for key %q of user %d: %v", key, userID, err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errResponseNotStored) {
+		return entity.IdempotentResponse{}, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func TestIdempotencyService_Execute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	idempotencyService := NewIdempotencyService(mockRepo, mockTrManager, TxConfig{}, time.Hour, logrus.New())
+
+	const (
+		key  = "key-1"
+		hash = "request-hash"
+	)
+	okBody := []byte(`{"status":"ok"}`)
+
+	tests := []struct {
+		name          string
+		operationResp entity.IdempotentResponse
+		mockBehavior  func()
+		wantResp      entity.IdempotentResponse
+		wantExecuted  bool
+		wantErr       error
+	}{
+		{
+			name:          "First Request Is Executed And Stored",
+			operationResp: entity.IdempotentResponse{StatusCode: http.StatusOK, Body: okBody},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRepo.EXPECT().DeleteExpiredIdempotencyKeys(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateIdempotencyKey(gomock.Any(), testUserID, key, hash).Return(true, nil)
+				mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("RELEASE SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mockRepo.EXPECT().SaveIdempotentResponse(gomock.Any(), testUserID, key, http.StatusOK, okBody).Return(nil)
+				mock.ExpectCommit()
+			},
+			wantResp:     entity.IdempotentResponse{StatusCode: http.StatusOK, Body: okBody},
+			wantExecuted: true,
+		},
+		{
+			name: "Replay Returns Stored Response",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRepo.EXPECT().DeleteExpiredIdempotencyKeys(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateIdempotencyKey(gomock.Any(), testUserID, key, hash).Return(false, nil)
+				mockRepo.EXPECT().GetIdempotencyKey(gomock.Any(), testUserID, key).
+					Return(entity.IdempotencyRecord{RequestHash: hash, StatusCode: http.StatusOK, ResponseBody: okBody}, nil)
+				mock.ExpectCommit()
+			},
+			wantResp: entity.IdempotentResponse{StatusCode: http.StatusOK, Body: okBody, Replayed: true},
+		},
+		{
+			name: "Conflicting Payload",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRepo.EXPECT().DeleteExpiredIdempotencyKeys(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateIdempotencyKey(gomock.Any(), testUserID, key, hash).Return(false, nil)
+				mockRepo.EXPECT().GetIdempotencyKey(gomock.Any(), testUserID, key).
+					Return(entity.IdempotencyRecord{RequestHash: "other-hash", StatusCode: http.StatusOK}, nil)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrIdempotencyKeyConflict,
+		},
+		{
+			name:          "Client Error Is Stored But Operation Rolled Back",
+			operationResp: entity.IdempotentResponse{StatusCode: http.StatusBadRequest, Body: []byte(`{"errors":"insufficient balance"}`)},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRepo.EXPECT().DeleteExpiredIdempotencyKeys(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateIdempotencyKey(gomock.Any(), testUserID, key, hash).Return(true, nil)
+				mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("ROLLBACK TO SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mockRepo.EXPECT().SaveIdempotentResponse(gomock.Any(), testUserID, key, http.StatusBadRequest, gomock.Any()).Return(nil)
+				mock.ExpectCommit()
+			},
+			wantResp:     entity.IdempotentResponse{StatusCode: http.StatusBadRequest, Body: []byte(`{"errors":"insufficient balance"}`)},
+			wantExecuted: true,
+		},
+		{
+			name:          "Server Error Is Not Stored",
+			operationResp: entity.IdempotentResponse{StatusCode: http.StatusInternalServerError, Body: []byte(`{"errors":"internal server error"}`)},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRepo.EXPECT().DeleteExpiredIdempotencyKeys(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateIdempotencyKey(gomock.Any(), testUserID, key, hash).Return(true, nil)
+				mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("ROLLBACK TO SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantResp:     entity.IdempotentResponse{StatusCode: http.StatusInternalServerError, Body: []byte(`{"errors":"internal server error"}`)},
+			wantExecuted: true,
+		},
+		{
+			name: "Reserve Error",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRepo.EXPECT().DeleteExpiredIdempotencyKeys(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateIdempotencyKey(gomock.Any(), testUserID, key, hash).Return(false, errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			executed := false
+			resp, err := idempotencyService.Execute(context.Background(), testUserID, key, hash, func(ctx context.Context) entity.IdempotentResponse {
+				executed = true
+				return tt.operationResp
+			})
+
+			if tt.wantErr != nil {
+				assert.ErrorContains(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantResp, resp)
+			}
+			assert.Equal(t, tt.wantExecuted, executed)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestIdempotencyService_Execute_RetriesConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	txCfg := TxConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	idempotencyService := NewIdempotencyService(mockRepo, mockTrManager, txCfg, time.Hour, logrus.New())
+	sendCoin := txRunner{trManager: mockTrManager, cfg: txCfg, log: logrus.New()}
+
+	const (
+		key  = "key-1"
+		hash = "request-hash"
+	)
+	okBody := []byte(`{"status":"ok"}`)
+	serializationFailure := &pq.Error{Code: "40001"}
+
+	expectReserve := func() {
+		mock.ExpectBegin()
+		mockRepo.EXPECT().DeleteExpiredIdempotencyKeys(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+		mockRepo.EXPECT().CreateIdempotencyKey(gomock.Any(), testUserID, key, hash).Return(true, nil)
+		mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	t.Run("Conflict In Operation Then Success", func(t *testing.T) {
+		expectReserve()
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+		expectReserve()
+		mock.ExpectExec("RELEASE SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+		mockRepo.EXPECT().SaveIdempotentResponse(gomock.Any(), testUserID, key, http.StatusOK, okBody).Return(nil)
+		mock.ExpectCommit()
+
+		attempts := 0
+		resp, err := idempotencyService.Execute(context.Background(), testUserID, key, hash, func(ctx context.Context) entity.IdempotentResponse {
+			attempts++
+			err := sendCoin.do(ctx, opSendCoin, func(ctx context.Context) error {
+				if attempts == 1 {
+					return serializationFailure
+				}
+				return nil
+			})
+			if errors.Is(err, entity.ErrTransactionConflict) {
+				return entity.IdempotentResponse{StatusCode: http.StatusServiceUnavailable}
+			}
+			return entity.IdempotentResponse{StatusCode: http.StatusOK, Body: okBody}
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.IdempotentResponse{StatusCode: http.StatusOK, Body: okBody}, resp)
+		assert.Equal(t, 2, attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Conflict On Commit Persisted", func(t *testing.T) {
+		for range txCfg.MaxAttempts {
+			expectReserve()
+			mock.ExpectExec("RELEASE SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+			mockRepo.EXPECT().SaveIdempotentResponse(gomock.Any(), testUserID, key, http.StatusOK, okBody).Return(nil)
+			mock.ExpectCommit().WillReturnError(serializationFailure)
+		}
+
+		_, err := idempotencyService.Execute(context.Background(), testUserID, key, hash, func(ctx context.Context) entity.IdempotentResponse {
+			return entity.IdempotentResponse{StatusCode: http.StatusOK, Body: okBody}
+		})
+
+		assert.ErrorIs(t, err, entity.ErrTransactionConflict)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
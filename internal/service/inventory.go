@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
 	"github.com/sirupsen/logrus"
@@ -11,36 +12,53 @@ import (
 )
 
 type InventoryService struct {
-	userRepo      repository.UserRepository
-	inventoryRepo repository.InventoryRepository
-	trManager     *manager.Manager
-	log           *logrus.Logger
+	userRepo        repository.UserRepository
+	inventoryRepo   repository.InventoryRepository
+	transactionRepo repository.TransactionRepository
+	purchaseRepo    repository.PurchaseRepository
+	coinLotRepo     repository.CoinLotRepository
+	ledger          ledgerWriter
+	trManager       *manager.Manager
+	tx              txRunner
+	log             *logrus.Logger
 }
 
 func NewInventoryService(
 	userRepo repository.UserRepository,
 	inventoryRepo repository.InventoryRepository,
+	transactionRepo repository.TransactionRepository,
+	purchaseRepo repository.PurchaseRepository,
+	coinLotRepo repository.CoinLotRepository,
+	ledgerRepo repository.LedgerRepository,
 	trManager *manager.Manager,
+	txCfg TxConfig,
 	log *logrus.Logger) *InventoryService {
 	return &InventoryService{
-		userRepo:      userRepo,
-		inventoryRepo: inventoryRepo,
-		trManager:     trManager,
-		log:           log,
+		userRepo:        userRepo,
+		inventoryRepo:   inventoryRepo,
+		transactionRepo: transactionRepo,
+		purchaseRepo:    purchaseRepo,
+		coinLotRepo:     coinLotRepo,
+		ledger:          ledgerWriter{repo: ledgerRepo},
+		trManager:       trManager,
+		tx:              txRunner{trManager: trManager, cfg: txCfg, log: log},
+		log:             log,
 	}
 }
 
-func (s *InventoryService) BuyItem(ctx context.Context, userID int64, itemName string) error {
+func (s *InventoryService) BuyItem(ctx context.Context, userID int64, itemName string) (entity.Receipt, error) {
 	s.log.Infof("User %d is attempting to buy item: %s", userID, itemName)
 
-	return s.trManager.Do(ctx, func(ctx context.Context) error {
+	var transaction entity.Transaction
+
+	err := s.tx.do(ctx, opBuyItem, func(ctx context.Context) error {
 		item, err := s.inventoryRepo.GetItem(ctx, itemName)
 		if err != nil {
 			s.log.Warnf("BuyItem failed: item %s not found", itemName)
 			return entity.ErrItemNotFound
 		}
 
-		balance, err := s.userRepo.GetUserBalance(ctx, userID)
+		balance, err := s.userRepo.GetUserBalanceForUpdate(ctx, userID)
 		if err != nil {
 			s.log.Errorf("BuyItem failed: failed to fetch balance for user %d: %v", userID, err)
 			return err
@@ -56,6 +74,11 @@ func (s *InventoryService) BuyItem(ctx context.Context, userID int64, itemName s
 			return err
 		}
 
+		if err = s.coinLotRepo.ConsumeCoinLots(ctx, userID, item.Price, time.Now()); err != nil {
+			s.log.Errorf("BuyItem failed: failed to consume coin lots of user %d: %v", userID, err)
+			return err
+		}
+
 		_, err = s.inventoryRepo.GetInventoryItem(ctx, userID, item.ID)
 		if err != nil {
 			s.log.Warnf("BuyItem: item %s not found in inventory for user %d, creating new entry", itemName, userID)
@@ -73,7 +96,48 @@ func (s *InventoryService) BuyItem(ctx context.Context, userID int64, itemName s
 			}
 		}
 
-		s.log.Infof("User %d successfully purchased item: %s", userID, itemName)
+		newBalance, err := s.userRepo.GetUserBalance(ctx, userID)
+		if err != nil {
+			s.log.Errorf("BuyItem failed: failed to fetch new balance for user %d: %v", userID, err)
+			return err
+		}
+
+		transaction, err = s.transactionRepo.InsertTransaction(ctx, entity.Transaction{
+			Type:          entity.TransactionTypePurchase,
+			FromUserID:    userID,
+			MerchID:       &item.ID,
+			Amount:        item.Price,
+			SenderBalance: &newBalance,
+		})
+		if err != nil {
+			s.log.Errorf("BuyItem failed: failed to insert purchase record: %v", err)
+			return err
+		}
+		transaction.Item = item.ItemType
+
+		_, err = s.purchaseRepo.InsertPurchase(ctx, entity.Purchase{
+			UserID:        userID,
+			MerchID:       item.ID,
+			UnitPrice:     item.Price,
+			Quantity:      1,
+			TransactionID: transaction.ID,
+		})
+		if err != nil {
+			s.log.Errorf("BuyItem failed: failed to record purchase of %s for user %d: %v", itemName, userID, err)
+			return err
+		}
+
+		if err = s.ledger.purchase(ctx, transaction.ID, userID, item.Price); err != nil {
+			s.log.Errorf("BuyItem failed: failed to post transaction %d to ledger: %v", transaction.ID, err)
+			return err
+		}
+
+		s.log.Infof("User %d successfully purchased item: %s (transaction %d)", userID, itemName, transaction.ID)
 		return nil
 	})
+	if err != nil {
+		return entity.Receipt{}, err
+	}
+
+	return newReceipt(transaction, userID), nil
 }
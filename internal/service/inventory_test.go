@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 
@@ -23,18 +24,23 @@ func TestInventoryService_BuyItem(t *testing.T) {
 
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	mockInventoryRepo := mocks.NewMockInventoryRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockPurchaseRepo := mocks.NewMockPurchaseRepository(ctrl)
+	mockCoinLotRepo := mocks.NewMockCoinLotRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
 	db, mock, _ := sqlmock.New()
 	mockDB := sqlx.NewDb(db, testDriverName)
 	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
 	mockLog := logrus.New()
 
-	service := NewInventoryService(mockUserRepo, mockInventoryRepo, mockTrManager, mockLog)
+	service := NewInventoryService(mockUserRepo, mockInventoryRepo, mockTransactionRepo, mockPurchaseRepo, mockCoinLotRepo, mockLedgerRepo, mockTrManager, TxConfig{}, mockLog)
 
 	tests := []struct {
 		name         string
 		userID       int64
 		itemName     string
 		mockBehavior func()
+		wantReceipt  entity.Receipt
 		wantErr      error
 	}{
 		{
@@ -44,12 +50,45 @@ func TestInventoryService_BuyItem(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockInventoryRepo.EXPECT().GetItem(gomock.Any(), "cup").Return(entity.MerchItems{ID: 10, ItemType: "cup", Price: 50}, nil)
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
 				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(1), int64(-50)).Return(nil)
+				mockCoinLotRepo.EXPECT().ConsumeCoinLots(gomock.Any(), int64(1), int64(50), gomock.Any()).Return(nil)
 				mockInventoryRepo.EXPECT().GetInventoryItem(gomock.Any(), int64(1), int64(10)).Return(1, nil)
 				mockInventoryRepo.EXPECT().UpdateInventoryItem(gomock.Any(), int64(1), int64(10)).Return(nil)
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(50), nil)
+				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, tr entity.Transaction) (entity.Transaction, error) {
+						assert.Equal(t, entity.TransactionTypePurchase, tr.Type)
+						assert.Nil(t, tr.ToUserID)
+						assert.Equal(t, int64(10), *tr.MerchID)
+						assert.Equal(t, int64(50), tr.Amount)
+						tr.ID = 8
+						tr.CreatedAt = testCreatedAt
+						return tr, nil
+					})
+				mockPurchaseRepo.EXPECT().InsertPurchase(gomock.Any(), entity.Purchase{
+					UserID: 1, MerchID: 10, UnitPrice: 50, Quantity: 1, TransactionID: 8,
+				}).Return(entity.Purchase{ID: 3}, nil)
+				mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), int64(1)).Return(int64(11), nil)
+				mockLedgerRepo.EXPECT().GetSystemAccountID(gomock.Any(), entity.LedgerAccountShop).Return(int64(1), nil)
+				mockLedgerRepo.EXPECT().CreateJournalEntry(gomock.Any(), entity.JournalEntry{
+					Kind:          entity.JournalPurchase,
+					TransactionID: int64Ptr(8),
+					Postings: []entity.LedgerPosting{
+						{AccountID: 11, Amount: -50},
+						{AccountID: 1, Amount: 50},
+					},
+				}).Return(int64(5), nil)
 				mock.ExpectCommit()
 			},
+			wantReceipt: entity.Receipt{
+				ID:        8,
+				Type:      entity.TransactionTypePurchase,
+				Amount:    50,
+				Item:      "cup",
+				Balance:   int64Ptr(50),
+				CreatedAt: testCreatedAt,
+			},
 			wantErr: nil,
 		},
 		{
@@ -70,7 +109,7 @@ func TestInventoryService_BuyItem(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockInventoryRepo.EXPECT().GetItem(gomock.Any(), "cup").Return(entity.MerchItems{ID: 10, ItemType: "cup", Price: 50}, nil)
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(0), errors.New("db error"))
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(0), errors.New("db error"))
 				mock.ExpectRollback()
 			},
 			wantErr: errors.New("db error"),
@@ -82,7 +121,7 @@ func TestInventoryService_BuyItem(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockInventoryRepo.EXPECT().GetItem(gomock.Any(), "cup").Return(entity.MerchItems{ID: 10, ItemType: "cup", Price: 100}, nil)
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(50), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(50), nil)
 				mock.ExpectRollback()
 			},
 			wantErr: entity.ErrInsufficientBalance,
@@ -94,7 +133,7 @@ func TestInventoryService_BuyItem(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockInventoryRepo.EXPECT().GetItem(gomock.Any(), "cup").Return(entity.MerchItems{ID: 10, ItemType: "cup", Price: 50}, nil)
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
 				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(1), int64(-50)).Return(errors.New("db error"))
 				mock.ExpectRollback()
 			},
@@ -107,21 +146,80 @@ func TestInventoryService_BuyItem(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockInventoryRepo.EXPECT().GetItem(gomock.Any(), "cup").Return(entity.MerchItems{ID: 10, ItemType: "cup", Price: 50}, nil)
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
 				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(1), int64(-50)).Return(nil)
+				mockCoinLotRepo.EXPECT().ConsumeCoinLots(gomock.Any(), int64(1), int64(50), gomock.Any()).Return(nil)
 				mockInventoryRepo.EXPECT().GetInventoryItem(gomock.Any(), int64(1), int64(10)).Return(1, nil)
 				mockInventoryRepo.EXPECT().UpdateInventoryItem(gomock.Any(), int64(1), int64(10)).Return(errors.New("db error"))
 				mock.ExpectRollback()
 			},
 			wantErr: errors.New("db error"),
 		},
+		{
+			name:     "Error inserting purchase record",
+			userID:   1,
+			itemName: "cup",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockInventoryRepo.EXPECT().GetItem(gomock.Any(), "cup").Return(entity.MerchItems{ID: 10, ItemType: "cup", Price: 50}, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(1), int64(-50)).Return(nil)
+				mockCoinLotRepo.EXPECT().ConsumeCoinLots(gomock.Any(), int64(1), int64(50), gomock.Any()).Return(nil)
+				mockInventoryRepo.EXPECT().GetInventoryItem(gomock.Any(), int64(1), int64(10)).Return(0, errors.New("not found"))
+				mockInventoryRepo.EXPECT().InsertInventoryItem(gomock.Any(), int64(1), int64(10)).Return(nil)
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(50), nil)
+				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), gomock.Any()).Return(entity.Transaction{}, errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name:     "Error recording purchase",
+			userID:   1,
+			itemName: "cup",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockInventoryRepo.EXPECT().GetItem(gomock.Any(), "cup").Return(entity.MerchItems{ID: 10, ItemType: "cup", Price: 50}, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(1), int64(-50)).Return(nil)
+				mockCoinLotRepo.EXPECT().ConsumeCoinLots(gomock.Any(), int64(1), int64(50), gomock.Any()).Return(nil)
+				mockInventoryRepo.EXPECT().GetInventoryItem(gomock.Any(), int64(1), int64(10)).Return(1, nil)
+				mockInventoryRepo.EXPECT().UpdateInventoryItem(gomock.Any(), int64(1), int64(10)).Return(nil)
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(50), nil)
+				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), gomock.Any()).Return(entity.Transaction{ID: 8}, nil)
+				mockPurchaseRepo.EXPECT().InsertPurchase(gomock.Any(), gomock.Any()).Return(entity.Purchase{}, errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name:     "Error posting to ledger",
+			userID:   1,
+			itemName: "cup",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockInventoryRepo.EXPECT().GetItem(gomock.Any(), "cup").Return(entity.MerchItems{ID: 10, ItemType: "cup", Price: 50}, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(1), int64(-50)).Return(nil)
+				mockCoinLotRepo.EXPECT().ConsumeCoinLots(gomock.Any(), int64(1), int64(50), gomock.Any()).Return(nil)
+				mockInventoryRepo.EXPECT().GetInventoryItem(gomock.Any(), int64(1), int64(10)).Return(1, nil)
+				mockInventoryRepo.EXPECT().UpdateInventoryItem(gomock.Any(), int64(1), int64(10)).Return(nil)
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(50), nil)
+				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), gomock.Any()).Return(entity.Transaction{ID: 8}, nil)
+				mockPurchaseRepo.EXPECT().InsertPurchase(gomock.Any(), gomock.Any()).Return(entity.Purchase{ID: 3}, nil)
+				mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), int64(1)).Return(int64(0), sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: sql.ErrNoRows,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockBehavior()
-			err := service.BuyItem(context.Background(), tt.userID, tt.itemName)
+			receipt, err := service.BuyItem(context.Background(), tt.userID, tt.itemName)
 			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantReceipt, receipt)
 		})
 	}
 }
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/sirupsen/logrus"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/repository"
+)
+
+// IssuanceService начисляет и списывает монеты по решению администратора. Каждая операция записывается
+// в историю пользователя, в журнал двойной записи и в аудит с ID администратора и причиной.
+type IssuanceService struct {
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
+	adjustmentRepo  repository.CoinAdjustmentRepository
+	coinLotRepo     repository.CoinLotRepository
+	ledger          ledgerWriter
+	tx              txRunner
+	log             *logrus.Logger
+}
+
+func NewIssuanceService(
+	userRepo repository.UserRepository,
+	transactionRepo repository.TransactionRepository,
+	adjustmentRepo repository.CoinAdjustmentRepository,
+	coinLotRepo repository.CoinLotRepository,
+	ledgerRepo repository.LedgerRepository,
+	trManager *manager.Manager,
+	txCfg TxConfig,
+	log *logrus.Logger) *IssuanceService {
+	return &IssuanceService{
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		adjustmentRepo:  adjustmentRepo,
+		coinLotRepo:     coinLotRepo,
+		ledger:          ledgerWriter{repo: ledgerRepo},
+		tx:              txRunner{trManager: trManager, cfg: txCfg, log: log},
+		log:             log,
+	}
+}
+
+// MintCoins начисляет amount монет каждому пользователю из списка. Операция атомарна:
+// если хотя бы один пользователь не найден, не начисляется никому.
+func (s *IssuanceService) MintCoins(ctx context.Context, adminID int64, input entity.MintRequest) (entity.MintResponse, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return entity.MintResponse{}, entity.ErrReasonRequired
+	}
+
+	usernames := slices.Clone(input.Usernames)
+	slices.Sort(usernames)
+	usernames = slices.Compact(usernames)
+
+	s.log.Infof("Admin %d is minting %d coins for %d users: %s", adminID, input.Amount, len(usernames), reason)
+
+	var receipts []entity.Receipt
+
+	err := s.tx.do(ctx, opMintCoins, func(ctx context.Context) error {
+		receipts = make([]entity.Receipt, 0, len(usernames))
+
+		users := make([]entity.User, 0, len(usernames))
+		userIDs := make([]int64, 0, len(usernames))
+		for _, username := range usernames {
+			user, err := getUserByName(ctx, s.userRepo, s.log, username)
+			if err != nil {
+				return err
+			}
+			users = append(users, user)
+			userIDs = append(userIDs, user.ID)
+		}
+
+		balances, err := lockBalances(ctx, s.userRepo, userIDs...)
+		if err != nil {
+			s.log.Errorf("MintCoins failed: failed to lock balances: %v", err)
+			return err
+		}
+
+		for _, user := range users {
+			if err = s.userRepo.AdjustCoins(ctx, user.ID, input.Amount); err != nil {
+				s.log.Errorf("MintCoins failed: failed to increase balance for user %d: %v", user.ID, err)
+				return err
+			}
+
+			// Строка пользователя заблокирована, поэтому новый баланс известен без повторного чтения.
+			balance := balances[user.ID] + input.Amount
+
+			receipt, err := s.record(ctx, entity.TransactionTypeMint, adminID, user.ID, input.Amount, balance, reason)
+			if err != nil {
+				return err
+			}
+			receipt.ToUser = user.Username
+			receipts = append(receipts, receipt)
+		}
+
+		s.log.Infof("Admin %d minted %d coins for %d users", adminID, input.Amount, len(users))
+		return nil
+	})
+	if err != nil {
+		return entity.MintResponse{}, err
+	}
+
+	return entity.MintResponse{Receipts: receipts}, nil
+}
+
+// BurnCoins списывает монеты пользователя. Баланс не может уйти в минус.
+func (s *IssuanceService) BurnCoins(ctx context.Context, adminID int64, input entity.BurnRequest) (entity.Receipt, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return entity.Receipt{}, entity.ErrReasonRequired
+	}
+
+	s.log.Infof("Admin %d is burning %d coins of user %s: %s", adminID, input.Amount, input.Username, reason)
+
+	var receipt entity.Receipt
+
+	err := s.tx.do(ctx, opBurnCoins, func(ctx context.Context) error {
+		user, err := getUserByName(ctx, s.userRepo, s.log, input.Username)
+		if err != nil {
+			return err
+		}
+
+		balance, err := s.userRepo.GetUserBalanceForUpdate(ctx, user.ID)
+		if err != nil {
+			s.log.Errorf("BurnCoins failed: failed to lock balance of user %d: %v", user.ID, err)
+			return err
+		}
+		if balance < input.Amount {
+			s.log.Warnf("BurnCoins failed: user %d has %d coins, %d requested", user.ID, balance, input.Amount)
+			return entity.ErrInsufficientBalance
+		}
+
+		if err = s.userRepo.UpdateCoins(ctx, user.ID, -input.Amount); err != nil {
+			s.log.Errorf("BurnCoins failed: failed to decrease balance for user %d: %v", user.ID, err)
+			return err
+		}
+
+		if err = s.coinLotRepo.ConsumeCoinLots(ctx, user.ID, input.Amount, time.Now()); err != nil {
+			s.log.Errorf("BurnCoins failed: failed to consume coin lots of user %d: %v", user.ID, err)
+			return err
+		}
+
+		receipt, err = s.record(ctx, entity.TransactionTypeBurn, adminID, user.ID, input.Amount, balance-input.Amount, reason)
+		if err != nil {
+			return err
+		}
+		receipt.FromUser = user.Username
+
+		s.log.Infof("Admin %d burned %d coins of user %d (transaction %d)", adminID, input.Amount, user.ID, receipt.ID)
+		return nil
+	})
+	if err != nil {
+		return entity.Receipt{}, err
+	}
+
+	return receipt, nil
+}
+
+// getUserByName ищет пользователя из запроса администратора. Если его нет, имя добавляется
+// к entity.ErrUserNotFound, чтобы в списке из многих пользователей было видно, какого не нашлось.
+func getUserByName(ctx context.Context, userRepo repository.UserRepository, log *logrus.Logger, username string) (entity.User, error) {
+	user, err := userRepo.GetUser(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Warnf("User %s not found", username)
+		return entity.User{}, fmt.Errorf("%w: %s", entity.ErrUserNotFound, username)
+	}
+	if err != nil {
+		log.Errorf("Failed to fetch user %s: %v", username, err)
+		return entity.User{}, err
+	}
+
+	return user, nil
+}
+
+// record сохраняет операцию начисления или списания, запись аудита и проводку в журнале.
+func (s *IssuanceService) record(ctx context.Context, transactionType string, adminID, userID, amount, balance int64, reason string) (entity.Receipt, error) {
+	transaction, err := s.transactionRepo.InsertTransaction(ctx, entity.Transaction{
+		Type:          transactionType,
+		FromUserID:    userID,
+		Amount:        amount,
+		SenderBalance: &balance,
+	})
+	if err != nil {
+		s.log.Errorf("Issuance failed: failed to insert %s for user %d: %v", transactionType, userID, err)
+		return entity.Receipt{}, err
+	}
+
+	_, err = s.adjustmentRepo.InsertCoinAdjustment(ctx, entity.CoinAdjustment{
+		TransactionID: transaction.ID,
+		AdminID:       adminID,
+		Reason:        reason,
+	})
+	if err != nil {
+		s.log.Errorf("Issuance failed: failed to record audit of transaction %d: %v", transaction.ID, err)
+		return entity.Receipt{}, err
+	}
+
+	if transactionType == entity.TransactionTypeMint {
+		err = s.ledger.mint(ctx, transaction.ID, userID, amount)
+	} else {
+		err = s.ledger.burn(ctx, transaction.ID, userID, amount)
+	}
+	if err != nil {
+		s.log.Errorf("Issuance failed: failed to post transaction %d to ledger: %v", transaction.ID, err)
+		return entity.Receipt{}, err
+	}
+
+	return entity.Receipt{
+		ID:        transaction.ID,
+		Type:      transaction.Type,
+		Amount:    transaction.Amount,
+		Balance:   &balance,
+		Reason:    reason,
+		CreatedAt: transaction.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func TestIssuanceService_MintCoins(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockAdjustmentRepo := mocks.NewMockCoinAdjustmentRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+
+	service := NewIssuanceService(mockUserRepo, mockTransactionRepo, mockAdjustmentRepo, nil, mockLedgerRepo, mockTrManager, TxConfig{MaxAttempts: 1}, logrus.New())
+
+	expectMint := func(userID, transactionID, balance int64) {
+		mockUserRepo.EXPECT().AdjustCoins(gomock.Any(), userID, int64(500)).Return(nil)
+		mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), entity.Transaction{
+			Type:          entity.TransactionTypeMint,
+			FromUserID:    userID,
+			Amount:        500,
+			SenderBalance: int64Ptr(balance),
+		}).DoAndReturn(func(_ context.Context, tr entity.Transaction) (entity.Transaction, error) {
+			tr.ID = transactionID
+			tr.CreatedAt = testCreatedAt
+			return tr, nil
+		})
+		mockAdjustmentRepo.EXPECT().InsertCoinAdjustment(gomock.Any(), entity.CoinAdjustment{
+			TransactionID: transactionID,
+			AdminID:       100,
+			Reason:        "Q1 bonus",
+		}).Return(entity.CoinAdjustment{ID: transactionID}, nil)
+		mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), userID).Return(userID+10, nil)
+		mockLedgerRepo.EXPECT().GetSystemAccountID(gomock.Any(), entity.LedgerAccountIssuance).Return(int64(2), nil)
+		mockLedgerRepo.EXPECT().CreateJournalEntry(gomock.Any(), entity.JournalEntry{
+			Kind:          entity.JournalMint,
+			TransactionID: int64Ptr(transactionID),
+			Postings: []entity.LedgerPosting{
+				{AccountID: 2, Amount: -500},
+				{AccountID: userID + 10, Amount: 500},
+			},
+		}).Return(transactionID, nil)
+	}
+
+	tests := []struct {
+		name         string
+		input        entity.MintRequest
+		mockBehavior func()
+		wantResponse entity.MintResponse
+		wantErr      error
+	}{
+		{
+			name:  "Success",
+			input: entity.MintRequest{Usernames: []string{"bob", "alice", "bob"}, Amount: 500, Reason: " Q1 bonus "},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), "alice").Return(entity.User{ID: 2, Username: "alice"}, nil)
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), "bob").Return(entity.User{ID: 1, Username: "bob"}, nil)
+				gomock.InOrder(
+					mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil),
+					mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(-20), nil),
+				)
+				expectMint(2, 8, 480)
+				expectMint(1, 9, 600)
+				mock.ExpectCommit()
+			},
+			wantResponse: entity.MintResponse{Receipts: []entity.Receipt{
+				{ID: 8, Type: entity.TransactionTypeMint, Amount: 500, ToUser: "alice", Balance: int64Ptr(480), Reason: "Q1 bonus", CreatedAt: testCreatedAt},
+				{ID: 9, Type: entity.TransactionTypeMint, Amount: 500, ToUser: "bob", Balance: int64Ptr(600), Reason: "Q1 bonus", CreatedAt: testCreatedAt},
+			}},
+		},
+		{
+			name:  "User Not Found",
+			input: entity.MintRequest{Usernames: []string{"alice", "ghost"}, Amount: 500, Reason: "Q1 bonus"},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), "alice").Return(entity.User{ID: 2, Username: "alice"}, nil)
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), "ghost").Return(entity.User{}, sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: fmt.Errorf("%w: %s", entity.ErrUserNotFound, "ghost"),
+		},
+		{
+			name:         "Blank Reason",
+			input:        entity.MintRequest{Usernames: []string{"alice"}, Amount: 500, Reason: "   "},
+			mockBehavior: func() {},
+			wantErr:      entity.ErrReasonRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			response, err := service.MintCoins(context.Background(), 100, tt.input)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantResponse, response)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestIssuanceService_BurnCoins(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockAdjustmentRepo := mocks.NewMockCoinAdjustmentRepository(ctrl)
+	mockCoinLotRepo := mocks.NewMockCoinLotRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+
+	service := NewIssuanceService(mockUserRepo, mockTransactionRepo, mockAdjustmentRepo, mockCoinLotRepo, mockLedgerRepo, mockTrManager, TxConfig{MaxAttempts: 1}, logrus.New())
+
+	input := entity.BurnRequest{Username: "alice", Amount: 300, Reason: "duplicate bonus"}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantReceipt  entity.Receipt
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), "alice").Return(entity.User{ID: 2, Username: "alice"}, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(1000), nil)
+				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(2), int64(-300)).Return(nil)
+				mockCoinLotRepo.EXPECT().ConsumeCoinLots(gomock.Any(), int64(2), int64(300), gomock.Any()).Return(nil)
+				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), entity.Transaction{
+					Type:          entity.TransactionTypeBurn,
+					FromUserID:    2,
+					Amount:        300,
+					SenderBalance: int64Ptr(700),
+				}).DoAndReturn(func(_ context.Context, tr entity.Transaction) (entity.Transaction, error) {
+					tr.ID = 12
+					tr.CreatedAt = testCreatedAt
+					return tr, nil
+				})
+				mockAdjustmentRepo.EXPECT().InsertCoinAdjustment(gomock.Any(), entity.CoinAdjustment{
+					TransactionID: 12,
+					AdminID:       100,
+					Reason:        "duplicate bonus",
+				}).Return(entity.CoinAdjustment{ID: 4}, nil)
+				mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), int64(2)).Return(int64(12), nil)
+				mockLedgerRepo.EXPECT().GetSystemAccountID(gomock.Any(), entity.LedgerAccountIssuance).Return(int64(2), nil)
+				mockLedgerRepo.EXPECT().CreateJournalEntry(gomock.Any(), entity.JournalEntry{
+					Kind:          entity.JournalBurn,
+					TransactionID: int64Ptr(12),
+					Postings: []entity.LedgerPosting{
+						{AccountID: 12, Amount: -300},
+						{AccountID: 2, Amount: 300},
+					},
+				}).Return(int64(7), nil)
+				mock.ExpectCommit()
+			},
+			wantReceipt: entity.Receipt{
+				ID:        12,
+				Type:      entity.TransactionTypeBurn,
+				Amount:    300,
+				FromUser:  "alice",
+				Balance:   int64Ptr(700),
+				Reason:    "duplicate bonus",
+				CreatedAt: testCreatedAt,
+			},
+		},
+		{
+			name: "Insufficient Balance",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), "alice").Return(entity.User{ID: 2, Username: "alice"}, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(200), nil)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrInsufficientBalance,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			receipt, err := service.BurnCoins(context.Background(), 100, input)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantReceipt, receipt)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+const (
+	// legacyKeyID — идентификатор ключа из JWTKEY. Им же проверяются токены без kid,
+	// выпущенные до появления ротации ключей.
+	legacyKeyID = "default"
+
+	secretKeyFileExt  = ".key"
+	privateKeyFileExt = ".pem"
+
+	minRSAKeyBits = 2048
+)
+
+type KeyringConfig struct {
+	LegacySecret string
+	// Keys — список ключей в формате "kid1:secret1,kid2:secret2".
+	Keys string
+	// Dir — каталог ключей: файл <kid>.key содержит секрет HS256,
+	// файл <kid>.pem — закрытый ключ RSA (RS256) или Ed25519 (EdDSA) в формате PEM.
+	Dir         string
+	ActiveKeyID string
+}
+
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// Keyring хранит ключи подписи JWT. Новые токены подписываются активным ключом,
+// а проверка принимает любой ключ из набора, пока он не будет удален из конфигурации.
+type Keyring struct {
+	mu     sync.RWMutex
+	cfg    KeyringConfig
+	keys   map[string]signingKey
+	active string
+}
+
+func NewKeyring(cfg KeyringConfig) (*Keyring, error) {
+	k := &Keyring{cfg: cfg}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Reload перечитывает каталог ключей. При ошибке остается прежний набор ключей.
+func (k *Keyring) Reload() error {
+	keys, err := loadSigningKeys(k.cfg)
+	if err != nil {
+		return err
+	}
+
+	active, err := resolveActiveKey(k.cfg.ActiveKeyID, keys)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys = keys
+	k.active = active
+	return nil
+}
+
+func (k *Keyring) ActiveKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.active
+}
+
+func (k *Keyring) sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	key := k.keys[k.active]
+	k.mu.RUnlock()
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+
+	return token.SignedString(key.signKey)
+}
+
+func (k *Keyring) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = legacyKeyID
+	}
+
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	k.mu.RUnlock()
+
+	if !ok {
+		return nil, entity.ErrUnknownSigningKey
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, entity.ErrInvalidSigningMethod
+	}
+
+	return key.verifyKey, nil
+}
+
+func loadSigningKeys(cfg KeyringConfig) (map[string]signingKey, error) {
+	keys := make(map[string]signingKey)
+
+	add := func(key signingKey) error {
+		if _, ok := keys[key.id]; ok {
+			return fmt.Errorf("duplicate jwt key id: %s", key.id)
+		}
+		keys[key.id] = key
+		return nil
+	}
+
+	if cfg.LegacySecret != "" {
+		if err := add(newHMACKey(legacyKeyID, cfg.LegacySecret)); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, entry := range strings.Split(cfg.Keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("invalid jwt key entry #%d: expected kid:secret", i+1)
+		}
+		if err := add(newHMACKey(id, secret)); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Dir != "" {
+		dirKeys, err := loadSigningKeysFromDir(cfg.Dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range dirKeys {
+			if err := add(key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no jwt signing keys configured")
+	}
+
+	return keys, nil
+}
+
+func loadSigningKeysFromDir(dir string) ([]signingKey, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwt keys dir: %w", err)
+	}
+
+	var keys []signingKey
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if entry.IsDir() || (ext != secretKeyFileExt && ext != privateKeyFileExt) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt key %s: %w", name, err)
+		}
+
+		id := strings.TrimSuffix(name, ext)
+
+		if ext == privateKeyFileExt {
+			key, err := newAsymmetricKey(id, data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid jwt key %s: %w", name, err)
+			}
+			keys = append(keys, key)
+			continue
+		}
+
+		secret := strings.TrimSpace(string(data))
+		if secret == "" {
+			return nil, fmt.Errorf("jwt key %s is empty", name)
+		}
+
+		keys = append(keys, newHMACKey(id, secret))
+	}
+
+	return keys, nil
+}
+
+func resolveActiveKey(activeKeyID string, keys map[string]signingKey) (string, error) {
+	if activeKeyID != "" {
+		if _, ok := keys[activeKeyID]; !ok {
+			return "", fmt.Errorf("active jwt key %s not found", activeKeyID)
+		}
+		return activeKeyID, nil
+	}
+
+	if len(keys) == 1 {
+		for id := range keys {
+			return id, nil
+		}
+	}
+
+	if _, ok := keys[legacyKeyID]; ok {
+		return legacyKeyID, nil
+	}
+
+	return "", fmt.Errorf("active jwt key id must be set when several keys are configured")
+}
+
+func newHMACKey(id, secret string) signingKey {
+	return signingKey{
+		id:        id,
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+}
+
+// newAsymmetricKey разбирает закрытый ключ в формате PEM (PKCS#1 или PKCS#8)
+// и выбирает алгоритм подписи по типу ключа.
+func newAsymmetricKey(id string, data []byte) (signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return signingKey{}, fmt.Errorf("no PEM data found")
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return signingKey{}, fmt.Errorf("unsupported private key format")
+		}
+	}
+
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return signingKey{}, fmt.Errorf("rsa key must be at least %d bits", minRSAKeyBits)
+		}
+		return signingKey{
+			id:        id,
+			method:    jwt.SigningMethodRS256,
+			signKey:   key,
+			verifyKey: &key.PublicKey,
+		}, nil
+	case ed25519.PrivateKey:
+		return signingKey{
+			id:        id,
+			method:    signingMethodEd25519,
+			signKey:   key,
+			verifyKey: key.Public(),
+		}, nil
+	default:
+		return signingKey{}, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+}
+
+// JWKS возвращает открытые ключи набора. Симметричные ключи HS256 не публикуются.
+func (k *Keyring) JWKS() entity.JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	jwks := entity.JWKS{Keys: make([]entity.JWK, 0, len(k.keys))}
+	for _, key := range k.keys {
+		jwk, ok := key.jwk()
+		if ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID
+	})
+
+	return jwks
+}
+
+func (key signingKey) jwk() (entity.JWK, bool) {
+	jwk := entity.JWK{
+		KeyID:     key.id,
+		Use:       "sig",
+		Algorithm: key.method.Alg(),
+	}
+
+	switch publicKey := key.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	default:
+		return entity.JWK{}, false
+	}
+
+	return jwk, true
+}
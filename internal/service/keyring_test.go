@@ -0,0 +1,312 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func mustNewKeyring(cfg KeyringConfig) *Keyring {
+	keyring, err := NewKeyring(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	return keyring
+}
+
+func writeKeyFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func writePrivateKeyFile(t *testing.T, dir, name string, key interface{}) {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	writeKeyFile(t, dir, name, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+}
+
+func parseWithKeyring(keyring *Keyring, tokenString string) error {
+	_, err := jwt.ParseWithClaims(tokenString, &tokenClaims{}, keyring.verificationKey)
+	return err
+}
+
+func testClaims() *tokenClaims {
+	return &tokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        "jti",
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		},
+		UserID: 1,
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyFile(t, dir, "2025-02.key", "dir-secret\n")
+	writeKeyFile(t, dir, "README.md", "ignored")
+
+	tests := []struct {
+		name       string
+		cfg        KeyringConfig
+		wantActive string
+		wantErr    bool
+	}{
+		{
+			name:       "Legacy Secret Only",
+			cfg:        KeyringConfig{LegacySecret: "secret"},
+			wantActive: legacyKeyID,
+		},
+		{
+			name:       "Single Configured Key",
+			cfg:        KeyringConfig{Keys: "k1:secret1"},
+			wantActive: "k1",
+		},
+		{
+			name:       "Legacy Key Stays Active By Default",
+			cfg:        KeyringConfig{LegacySecret: "secret", Keys: "k1:secret1"},
+			wantActive: legacyKeyID,
+		},
+		{
+			name:       "Explicit Active Key",
+			cfg:        KeyringConfig{LegacySecret: "secret", Keys: "k1:secret1, k2:secret2", ActiveKeyID: "k2"},
+			wantActive: "k2",
+		},
+		{
+			name:       "Keys From Dir",
+			cfg:        KeyringConfig{Keys: "k1:secret1", Dir: dir, ActiveKeyID: "2025-02"},
+			wantActive: "2025-02",
+		},
+		{
+			name:    "No Keys",
+			cfg:     KeyringConfig{},
+			wantErr: true,
+		},
+		{
+			name:    "Malformed Entry",
+			cfg:     KeyringConfig{Keys: "k1"},
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate Key ID",
+			cfg:     KeyringConfig{Keys: "k1:a,k1:b"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown Active Key",
+			cfg:     KeyringConfig{Keys: "k1:secret1", ActiveKeyID: "k2"},
+			wantErr: true,
+		},
+		{
+			name:    "Ambiguous Active Key",
+			cfg:     KeyringConfig{Keys: "k1:secret1,k2:secret2"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing Dir",
+			cfg:     KeyringConfig{Dir: filepath.Join(dir, "missing")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := NewKeyring(tt.cfg)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantActive, keyring.ActiveKeyID())
+		})
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyFile(t, dir, "old.key", "old-secret")
+
+	keyring := mustNewKeyring(KeyringConfig{Dir: dir, LegacySecret: "legacy-secret", ActiveKeyID: "old"})
+
+	oldToken, err := keyring.sign(testClaims())
+	require.NoError(t, err)
+
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString([]byte("legacy-secret"))
+	require.NoError(t, err)
+
+	// Новый ключ появляется в каталоге и становится активным после перезагрузки.
+	writeKeyFile(t, dir, "new.key", "new-secret")
+	keyring.cfg.ActiveKeyID = "new"
+	require.NoError(t, keyring.Reload())
+
+	newToken, err := keyring.sign(testClaims())
+	require.NoError(t, err)
+
+	token, _, err := new(jwt.Parser).ParseUnverified(newToken, &tokenClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "new", token.Header["kid"])
+
+	assert.NoError(t, parseWithKeyring(keyring, newToken))
+	assert.NoError(t, parseWithKeyring(keyring, oldToken), "tokens of the previous key must stay valid")
+	assert.NoError(t, parseWithKeyring(keyring, legacyToken), "tokens without kid are verified by the legacy key")
+
+	// Старый ключ выводится из оборота.
+	require.NoError(t, os.Remove(filepath.Join(dir, "old.key")))
+	require.NoError(t, keyring.Reload())
+
+	err = parseWithKeyring(keyring, oldToken)
+	require.Error(t, err)
+	assert.ErrorIs(t, err.(*jwt.ValidationError).Inner, entity.ErrUnknownSigningKey)
+	assert.NoError(t, parseWithKeyring(keyring, newToken))
+}
+
+func TestKeyring_ReloadKeepsKeysOnError(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyFile(t, dir, "k1.key", "secret1")
+
+	keyring := mustNewKeyring(KeyringConfig{Dir: dir})
+
+	tokenString, err := keyring.sign(testClaims())
+	require.NoError(t, err)
+
+	writeKeyFile(t, dir, "broken.key", "  ")
+	assert.Error(t, keyring.Reload())
+
+	assert.Equal(t, "k1", keyring.ActiveKeyID())
+	assert.NoError(t, parseWithKeyring(keyring, tokenString))
+}
+
+func TestKeyring_RejectsAlgorithmMismatch(t *testing.T) {
+	keyring := mustNewKeyring(KeyringConfig{Keys: "k1:secret1"})
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, testClaims())
+	token.Header["kid"] = "k1"
+	tokenString, err := token.SignedString([]byte("secret1"))
+	require.NoError(t, err)
+
+	err = parseWithKeyring(keyring, tokenString)
+	require.Error(t, err)
+	assert.ErrorIs(t, err.(*jwt.ValidationError).Inner, entity.ErrInvalidSigningMethod)
+}
+
+func TestKeyring_AsymmetricKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writePrivateKeyFile(t, dir, "rsa-1.pem", rsaKey)
+	writePrivateKeyFile(t, dir, "ed-1.pem", edPrivate)
+	writeKeyFile(t, dir, "hs-1.key", "secret")
+
+	tests := []struct {
+		name     string
+		activeID string
+		wantAlg  string
+	}{
+		{name: "RS256", activeID: "rsa-1", wantAlg: "RS256"},
+		{name: "EdDSA", activeID: "ed-1", wantAlg: "EdDSA"},
+		{name: "HS256", activeID: "hs-1", wantAlg: "HS256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring := mustNewKeyring(KeyringConfig{Dir: dir, ActiveKeyID: tt.activeID})
+
+			tokenString, err := keyring.sign(testClaims())
+			require.NoError(t, err)
+
+			token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &tokenClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAlg, token.Header["alg"])
+			assert.Equal(t, tt.activeID, token.Header["kid"])
+
+			assert.NoError(t, parseWithKeyring(keyring, tokenString))
+		})
+	}
+
+	t.Run("JWKS", func(t *testing.T) {
+		keyring := mustNewKeyring(KeyringConfig{Dir: dir, ActiveKeyID: "rsa-1"})
+
+		jwks := keyring.JWKS()
+
+		require.Len(t, jwks.Keys, 2, "symmetric keys must not be published")
+
+		ed := jwks.Keys[0]
+		assert.Equal(t, "ed-1", ed.KeyID)
+		assert.Equal(t, "OKP", ed.KeyType)
+		assert.Equal(t, "Ed25519", ed.Curve)
+		assert.Equal(t, "EdDSA", ed.Algorithm)
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(edPublic), ed.X)
+
+		rsaJWK := jwks.Keys[1]
+		assert.Equal(t, "rsa-1", rsaJWK.KeyID)
+		assert.Equal(t, "RSA", rsaJWK.KeyType)
+		assert.Equal(t, "RS256", rsaJWK.Algorithm)
+		assert.Equal(t, "sig", rsaJWK.Use)
+
+		n, err := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+		require.NoError(t, err)
+		assert.Equal(t, 0, new(big.Int).SetBytes(n).Cmp(rsaKey.N))
+		assert.Equal(t, "AQAB", rsaJWK.E)
+	})
+
+	t.Run("Forged EdDSA Signature", func(t *testing.T) {
+		keyring := mustNewKeyring(KeyringConfig{Dir: dir, ActiveKeyID: "ed-1"})
+
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		token := jwt.NewWithClaims(signingMethodEd25519, testClaims())
+		token.Header["kid"] = "ed-1"
+		tokenString, err := token.SignedString(otherKey)
+		require.NoError(t, err)
+
+		assert.Error(t, parseWithKeyring(keyring, tokenString))
+	})
+
+	t.Run("HMAC Token With Public Key Rejected", func(t *testing.T) {
+		keyring := mustNewKeyring(KeyringConfig{Dir: dir, ActiveKeyID: "rsa-1"})
+
+		// Попытка подписать HS256-токен открытым ключом RSA как секретом.
+		publicPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)})
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+		token.Header["kid"] = "rsa-1"
+		tokenString, err := token.SignedString(publicPEM)
+		require.NoError(t, err)
+
+		err = parseWithKeyring(keyring, tokenString)
+		require.Error(t, err)
+		assert.ErrorIs(t, err.(*jwt.ValidationError).Inner, entity.ErrInvalidSigningMethod)
+	})
+}
+
+func TestKeyring_RejectsWeakRSAKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writePrivateKeyFile(t, dir, "weak.pem", rsaKey)
+
+	_, err = NewKeyring(KeyringConfig{Dir: dir})
+	assert.Error(t, err)
+}
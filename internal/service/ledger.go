@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/repository"
+)
+
+// ledgerWriter записывает движения монет в журнал двойной записи.
+// Журнал — источник истины, а users.coins лишь поддерживаемый кэш его остатков,
+// поэтому методы должны вызываться в той же транзакции, что и изменение кэша.
+type ledgerWriter struct {
+	repo repository.LedgerRepository
+}
+
+// openAccount заводит счет нового пользователя.
+func (l ledgerWriter) openAccount(ctx context.Context, userID int64) error {
+	_, err := l.repo.CreateUserAccount(ctx, userID)
+	return err
+}
+
+// signupGrant зачисляет новому пользователю стартовые монеты со счета эмиссии.
+func (l ledgerWriter) signupGrant(ctx context.Context, transactionID, userID, amount int64) error {
+	return l.issue(ctx, entity.JournalSignupGrant, transactionID, userID, amount)
+}
+
+func (l ledgerWriter) transfer(ctx context.Context, transactionID, fromUserID, toUserID, amount int64) error {
+	return l.betweenUsers(ctx, entity.JournalTransfer, transactionID, fromUserID, toUserID, amount)
+}
+
+// reversal проводит сторно перевода: монеты возвращаются от получателя исходного перевода отправителю.
+func (l ledgerWriter) reversal(ctx context.Context, transactionID, fromUserID, toUserID, amount int64) error {
+	return l.betweenUsers(ctx, entity.JournalReversal, transactionID, fromUserID, toUserID, amount)
+}
+
+func (l ledgerWriter) betweenUsers(ctx context.Context, kind string, transactionID, fromUserID, toUserID, amount int64) error {
+	fromID, err := l.repo.GetUserAccountID(ctx, fromUserID)
+	if err != nil {
+		return err
+	}
+
+	toID, err := l.repo.GetUserAccountID(ctx, toUserID)
+	if err != nil {
+		return err
+	}
+
+	return l.post(ctx, kind, &transactionID, fromID, toID, amount)
+}
+
+func (l ledgerWriter) purchase(ctx context.Context, transactionID, userID, amount int64) error {
+	accountID, err := l.repo.GetUserAccountID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	shopID, err := l.repo.GetSystemAccountID(ctx, entity.LedgerAccountShop)
+	if err != nil {
+		return err
+	}
+
+	return l.post(ctx, entity.JournalPurchase, &transactionID, accountID, shopID, amount)
+}
+
+// refund возвращает покупателю монеты со счета магазина.
+func (l ledgerWriter) refund(ctx context.Context, transactionID, userID, amount int64) error {
+	accountID, err := l.repo.GetUserAccountID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	shopID, err := l.repo.GetSystemAccountID(ctx, entity.LedgerAccountShop)
+	if err != nil {
+		return err
+	}
+
+	return l.post(ctx, entity.JournalRefund, &transactionID, shopID, accountID, amount)
+}
+
+// mint выпускает монеты на счет пользователя со счета эмиссии.
+func (l ledgerWriter) mint(ctx context.Context, transactionID, userID, amount int64) error {
+	return l.issue(ctx, entity.JournalMint, transactionID, userID, amount)
+}
+
+// allowance выплачивает регулярное начисление со счета эмиссии.
+func (l ledgerWriter) allowance(ctx context.Context, transactionID, userID, amount int64) error {
+	return l.issue(ctx, entity.JournalAllowance, transactionID, userID, amount)
+}
+
+func (l ledgerWriter) issue(ctx context.Context, kind string, transactionID, userID, amount int64) error {
+	accountID, err := l.repo.GetUserAccountID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	issuanceID, err := l.repo.GetSystemAccountID(ctx, entity.LedgerAccountIssuance)
+	if err != nil {
+		return err
+	}
+
+	return l.post(ctx, kind, &transactionID, issuanceID, accountID, amount)
+}
+
+// burn изымает монеты пользователя из обращения, возвращая их на счет эмиссии.
+func (l ledgerWriter) burn(ctx context.Context, transactionID, userID, amount int64) error {
+	return l.retire(ctx, entity.JournalBurn, transactionID, userID, amount)
+}
+
+// expire возвращает на счет эмиссии сгоревшие монеты пользователя.
+func (l ledgerWriter) expire(ctx context.Context, transactionID, userID, amount int64) error {
+	return l.retire(ctx, entity.JournalExpiration, transactionID, userID, amount)
+}
+
+func (l ledgerWriter) retire(ctx context.Context, kind string, transactionID, userID, amount int64) error {
+	accountID, err := l.repo.GetUserAccountID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	issuanceID, err := l.repo.GetSystemAccountID(ctx, entity.LedgerAccountIssuance)
+	if err != nil {
+		return err
+	}
+
+	return l.post(ctx, kind, &transactionID, accountID, issuanceID, amount)
+}
+
+// post проводит amount монет со счета debitID на счет creditID одной записью журнала.
+func (l ledgerWriter) post(ctx context.Context, kind string, transactionID *int64, debitID, creditID, amount int64) error {
+	entry := entity.JournalEntry{
+		Kind:          kind,
+		TransactionID: transactionID,
+		Postings: []entity.LedgerPosting{
+			{AccountID: debitID, Amount: -amount},
+			{AccountID: creditID, Amount: amount},
+		},
+	}
+	if amount <= 0 || !entry.Balanced() {
+		return entity.ErrUnbalancedEntry
+	}
+
+	_, err := l.repo.CreateJournalEntry(ctx, entry)
+	return err
+}
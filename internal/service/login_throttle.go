@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+// LoginThrottleConfig задает защиту от перебора паролей. После MaxUserFailures неудачных попыток
+// для имени пользователя (или MaxIPFailures для IP-адреса) в пределах FailureWindow вход блокируется
+// на BaseLockout, и каждая следующая неудача удваивает блокировку вплоть до MaxLockout.
+// Нулевой лимит отключает соответствующий счетчик.
+type LoginThrottleConfig struct {
+	MaxUserFailures int
+	MaxIPFailures   int
+	BaseLockout     time.Duration
+	MaxLockout      time.Duration
+	FailureWindow   time.Duration
+}
+
+func (c LoginThrottleConfig) enabled() bool {
+	return c.MaxUserFailures > 0 || c.MaxIPFailures > 0
+}
+
+// lockoutDuration возвращает длительность блокировки после failures неудач при лимите limit.
+func (c LoginThrottleConfig) lockoutDuration(failures, limit int) time.Duration {
+	lockout := c.BaseLockout
+	for i := limit; i < failures && lockout < c.MaxLockout; i++ {
+		lockout *= 2
+	}
+
+	if c.MaxLockout > 0 && lockout > c.MaxLockout {
+		return c.MaxLockout
+	}
+
+	return lockout
+}
+
+type loginKey struct {
+	key   string
+	limit int
+}
+
+func (s *AuthService) loginKeys(username, clientIP string) []loginKey {
+	var keys []loginKey
+	if s.cfg.Throttle.MaxUserFailures > 0 {
+		keys = append(keys, loginKey{key: userLoginKey(username), limit: s.cfg.Throttle.MaxUserFailures})
+	}
+	if s.cfg.Throttle.MaxIPFailures > 0 && clientIP != "" {
+		keys = append(keys, loginKey{key: "ip:" + clientIP, limit: s.cfg.Throttle.MaxIPFailures})
+	}
+
+	return keys
+}
+
+func userLoginKey(username string) string {
+	return "user:" + username
+}
+
+func (s *AuthService) checkLoginLockout(ctx context.Context, username, clientIP string) error {
+	keys := s.loginKeys(username, clientIP)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		names = append(names, k.key)
+	}
+
+	lockedUntil, err := s.loginAttemptRepo.GetLoginLockout(ctx, names...)
+	if err != nil {
+		s.log.Errorf("checkLoginLockout: failed to fetch login lockout for user %s: %v", username, err)
+		return err
+	}
+
+	if retryAfter := time.Until(lockedUntil); retryAfter > 0 {
+		s.log.Warnf("checkLoginLockout: login for user %s from %s is locked for %s", username, clientIP, retryAfter)
+		return &entity.LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// registerLoginFailure учитывает неудачную попытку входа. Ошибки хранилища только логируются,
+// чтобы не подменять ими ответ о неверных учетных данных.
+func (s *AuthService) registerLoginFailure(ctx context.Context, username, clientIP string) {
+	now := time.Now()
+	windowStart := now.Add(-s.cfg.Throttle.FailureWindow)
+
+	for _, k := range s.loginKeys(username, clientIP) {
+		failures, err := s.loginAttemptRepo.RegisterLoginFailure(ctx, k.key, now, windowStart)
+		if err != nil {
+			s.log.Errorf("registerLoginFailure: failed to register failure for %s: %v", k.key, err)
+			continue
+		}
+		if failures < k.limit {
+			continue
+		}
+
+		lockout := s.cfg.Throttle.lockoutDuration(failures, k.limit)
+		if err := s.loginAttemptRepo.LockLogin(ctx, k.key, now.Add(lockout)); err != nil {
+			s.log.Errorf("registerLoginFailure: failed to lock %s: %v", k.key, err)
+			continue
+		}
+		s.log.Warnf("registerLoginFailure: %s locked for %s after %d failed attempts", k.key, lockout, failures)
+	}
+}
+
+// resetLoginFailures сбрасывает счетчик имени пользователя после успешного входа.
+// Счетчик IP не сбрасывается, чтобы вход в собственный аккаунт не обнулял перебор чужих.
+func (s *AuthService) resetLoginFailures(ctx context.Context, username string) {
+	if s.cfg.Throttle.MaxUserFailures <= 0 {
+		return
+	}
+
+	if err := s.loginAttemptRepo.ResetLoginAttempts(ctx, userLoginKey(username)); err != nil {
+		s.log.Errorf("resetLoginFailures: failed to reset login attempts for user %s: %v", username, err)
+	}
+}
+
+// UnlockUser снимает блокировку входа с аккаунта и обнуляет его счетчик неудачных попыток.
+func (s *AuthService) UnlockUser(ctx context.Context, username string) error {
+	if _, err := s.userRepo.GetUser(ctx, username); err != nil {
+		s.log.Warnf("UnlockUser: user %s not found", username)
+		return entity.ErrUserNotFound
+	}
+
+	if err := s.loginAttemptRepo.ResetLoginAttempts(ctx, userLoginKey(username)); err != nil {
+		s.log.Errorf("UnlockUser: failed to reset login attempts for user %s: %v", username, err)
+		return err
+	}
+
+	s.log.Infof("User %s unlocked", username)
+	return nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/repository"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func TestLoginThrottleConfig_LockoutDuration(t *testing.T) {
+	cfg := LoginThrottleConfig{BaseLockout: time.Second, MaxLockout: 10 * time.Second}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 3, want: time.Second},
+		{failures: 4, want: 2 * time.Second},
+		{failures: 5, want: 4 * time.Second},
+		{failures: 6, want: 8 * time.Second},
+		{failures: 7, want: 10 * time.Second},
+		{failures: 100, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, cfg.lockoutDuration(tt.failures, 3), "failures=%d", tt.failures)
+	}
+}
+
+func TestAuthService_GenerateTokenThrottle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockRefreshRepo := mocks.NewMockRefreshTokenRepository(ctrl)
+	mockLog := logrus.New()
+	hasher := newTestHasher(t)
+
+	cfg := testAuthConfig
+	cfg.Throttle = LoginThrottleConfig{
+		MaxUserFailures: 3,
+		MaxIPFailures:   5,
+		BaseLockout:     time.Minute,
+		MaxLockout:      time.Hour,
+		FailureWindow:   time.Hour,
+	}
+
+	currentHash, err := hasher.Hash(testPassword)
+	require.NoError(t, err)
+	user := entity.User{ID: testUserID, Username: testUsername, Password: currentHash, Role: entity.RoleUser}
+
+	mockUserRepo.EXPECT().GetUser(gomock.Any(), testUsername).Return(user, nil).AnyTimes()
+	mockUserRepo.EXPECT().GetUser(gomock.Any(), gomock.Any()).Return(entity.User{}, sql.ErrNoRows).AnyTimes()
+	mockRefreshRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	newService := func() *AuthService {
+		return NewAuthService(mockUserRepo, mockRefreshRepo, nil, repository.NewLoginAttemptMemory(), nil, nil, nil, nil, hasher, cfg, mockLog)
+	}
+	ctx := context.Background()
+
+	t.Run("User Locked After Max Failures", func(t *testing.T) {
+		authService := newService()
+
+		for i := 0; i < 3; i++ {
+			_, err := authService.GenerateToken(ctx, testUsername, "wrong", "10.0.0.1")
+			assert.Equal(t, entity.ErrIncorrectPassword, err)
+		}
+
+		// Даже верный пароль отклоняется, пока действует блокировка, в том числе с другого адреса.
+		_, err := authService.GenerateToken(ctx, testUsername, testPassword, "10.0.0.2")
+
+		var lockErr *entity.LoginLockedError
+		require.ErrorAs(t, err, &lockErr)
+		assert.ErrorIs(t, err, entity.ErrTooManyLoginAttempts)
+		assert.InDelta(t, time.Minute.Seconds(), lockErr.RetryAfter.Seconds(), 1)
+	})
+
+	t.Run("Success Resets User Counter", func(t *testing.T) {
+		authService := newService()
+
+		for i := 0; i < 2; i++ {
+			_, err := authService.GenerateToken(ctx, testUsername, "wrong", "10.0.0.1")
+			assert.Equal(t, entity.ErrIncorrectPassword, err)
+		}
+
+		_, err := authService.GenerateToken(ctx, testUsername, testPassword, "10.0.0.1")
+		require.NoError(t, err)
+
+		_, err = authService.GenerateToken(ctx, testUsername, "wrong", "10.0.0.1")
+		assert.Equal(t, entity.ErrIncorrectPassword, err)
+
+		_, err = authService.GenerateToken(ctx, testUsername, testPassword, "10.0.0.1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("IP Locked Across Usernames", func(t *testing.T) {
+		authService := newService()
+
+		for i := 0; i < 5; i++ {
+			_, err := authService.GenerateToken(ctx, "unknown"+string(rune('a'+i)), "wrong", "10.0.0.3")
+			assert.Equal(t, entity.ErrUserNotFound, err)
+		}
+
+		_, err := authService.GenerateToken(ctx, testUsername, testPassword, "10.0.0.3")
+		assert.ErrorIs(t, err, entity.ErrTooManyLoginAttempts)
+
+		_, err = authService.GenerateToken(ctx, testUsername, testPassword, "10.0.0.4")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unlock", func(t *testing.T) {
+		authService := newService()
+
+		for i := 0; i < 3; i++ {
+			_, _ = authService.GenerateToken(ctx, testUsername, "wrong", "10.0.0.1")
+		}
+
+		require.NoError(t, authService.UnlockUser(ctx, testUsername))
+
+		_, err := authService.GenerateToken(ctx, testUsername, testPassword, "10.0.0.1")
+		assert.NoError(t, err)
+	})
+}
+
+func TestAuthService_UnlockUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockLoginAttemptRepo := mocks.NewMockLoginAttemptRepository(ctrl)
+	mockLog := logrus.New()
+	authService := NewAuthService(mockUserRepo, nil, nil, mockLoginAttemptRepo, nil, nil, nil, nil, newTestHasher(t), testAuthConfig, mockLog)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), testUsername).Return(entity.User{ID: testUserID}, nil)
+				mockLoginAttemptRepo.EXPECT().ResetLoginAttempts(gomock.Any(), "user:"+testUsername).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "User Not Found",
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), testUsername).Return(entity.User{}, sql.ErrNoRows)
+			},
+			wantErr: entity.ErrUserNotFound,
+		},
+		{
+			name: "Store Failure",
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), testUsername).Return(entity.User{ID: testUserID}, nil)
+				mockLoginAttemptRepo.EXPECT().ResetLoginAttempts(gomock.Any(), "user:"+testUsername).Return(errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := authService.UnlockUser(context.Background(), testUsername)
+
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -7,6 +7,7 @@ package mock_service
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	entity "github.com/senyabanana/shop-service/internal/entity"
@@ -35,6 +36,51 @@ func (m *MockAuthorization) EXPECT() *MockAuthorizationMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockAuthorization) ChangePassword(ctx context.Context, userID int64, currentPassword, newPassword string) (entity.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, currentPassword, newPassword)
+	ret0, _ := ret[0].(entity.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAuthorizationMockRecorder) ChangePassword(ctx, userID, currentPassword, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthorization)(nil).ChangePassword), ctx, userID, currentPassword, newPassword)
+}
+
+// CompleteTwoFactorLogin mocks base method.
+func (m *MockAuthorization) CompleteTwoFactorLogin(ctx context.Context, challengeToken, code, clientIP string) (entity.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTwoFactorLogin", ctx, challengeToken, code, clientIP)
+	ret0, _ := ret[0].(entity.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTwoFactorLogin indicates an expected call of CompleteTwoFactorLogin.
+func (mr *MockAuthorizationMockRecorder) CompleteTwoFactorLogin(ctx, challengeToken, code, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTwoFactorLogin", reflect.TypeOf((*MockAuthorization)(nil).CompleteTwoFactorLogin), ctx, challengeToken, code, clientIP)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockAuthorization) ConfirmTOTP(ctx context.Context, userID int64, code string) (entity.RecoveryCodesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, code)
+	ret0, _ := ret[0].(entity.RecoveryCodesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockAuthorizationMockRecorder) ConfirmTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockAuthorization)(nil).ConfirmTOTP), ctx, userID, code)
+}
+
 // CreateUser mocks base method.
 func (m *MockAuthorization) CreateUser(ctx context.Context, username, password string) error {
 	m.ctrl.T.Helper()
@@ -49,19 +95,62 @@ func (mr *MockAuthorizationMockRecorder) CreateUser(ctx, username, password inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuthorization)(nil).CreateUser), ctx, username, password)
 }
 
+// DisableTOTP mocks base method.
+func (m *MockAuthorization) DisableTOTP(ctx context.Context, userID int64, password, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, userID, password, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockAuthorizationMockRecorder) DisableTOTP(ctx, userID, password, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockAuthorization)(nil).DisableTOTP), ctx, userID, password, code)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockAuthorization) EnrollTOTP(ctx context.Context, userID int64) (entity.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx, userID)
+	ret0, _ := ret[0].(entity.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockAuthorizationMockRecorder) EnrollTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockAuthorization)(nil).EnrollTOTP), ctx, userID)
+}
+
 // GenerateToken mocks base method.
-func (m *MockAuthorization) GenerateToken(ctx context.Context, username, password string) (string, error) {
+func (m *MockAuthorization) GenerateToken(ctx context.Context, username, password, clientIP string) (entity.AuthResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateToken", ctx, username, password)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GenerateToken", ctx, username, password, clientIP)
+	ret0, _ := ret[0].(entity.AuthResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenerateToken indicates an expected call of GenerateToken.
-func (mr *MockAuthorizationMockRecorder) GenerateToken(ctx, username, password interface{}) *gomock.Call {
+func (mr *MockAuthorizationMockRecorder) GenerateToken(ctx, username, password, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockAuthorization)(nil).GenerateToken), ctx, username, password, clientIP)
+}
+
+// GetJWKS mocks base method.
+func (m *MockAuthorization) GetJWKS() entity.JWKS {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJWKS")
+	ret0, _ := ret[0].(entity.JWKS)
+	return ret0
+}
+
+// GetJWKS indicates an expected call of GetJWKS.
+func (mr *MockAuthorizationMockRecorder) GetJWKS() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockAuthorization)(nil).GenerateToken), ctx, username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJWKS", reflect.TypeOf((*MockAuthorization)(nil).GetJWKS))
 }
 
 // GetUser mocks base method.
@@ -79,19 +168,225 @@ func (mr *MockAuthorizationMockRecorder) GetUser(ctx, username interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockAuthorization)(nil).GetUser), ctx, username)
 }
 
+// Logout mocks base method.
+func (m *MockAuthorization) Logout(ctx context.Context, claims entity.TokenClaims, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, claims, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthorizationMockRecorder) Logout(ctx, claims, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthorization)(nil).Logout), ctx, claims, refreshToken)
+}
+
 // ParseToken mocks base method.
-func (m *MockAuthorization) ParseToken(accessToken string) (int64, error) {
+func (m *MockAuthorization) ParseToken(ctx context.Context, accessToken string) (entity.TokenClaims, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ParseToken", accessToken)
-	ret0, _ := ret[0].(int64)
+	ret := m.ctrl.Call(m, "ParseToken", ctx, accessToken)
+	ret0, _ := ret[0].(entity.TokenClaims)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ParseToken indicates an expected call of ParseToken.
-func (mr *MockAuthorizationMockRecorder) ParseToken(accessToken interface{}) *gomock.Call {
+func (mr *MockAuthorizationMockRecorder) ParseToken(ctx, accessToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockAuthorization)(nil).ParseToken), ctx, accessToken)
+}
+
+// RefreshToken mocks base method.
+func (m *MockAuthorization) RefreshToken(ctx context.Context, refreshToken string) (entity.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(entity.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockAuthorizationMockRecorder) RefreshToken(ctx, refreshToken interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockAuthorization)(nil).ParseToken), accessToken)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockAuthorization)(nil).RefreshToken), ctx, refreshToken)
+}
+
+// Register mocks base method.
+func (m *MockAuthorization) Register(ctx context.Context, username, password string) (entity.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, username, password)
+	ret0, _ := ret[0].(entity.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockAuthorizationMockRecorder) Register(ctx, username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthorization)(nil).Register), ctx, username, password)
+}
+
+// RevokeAllSessions mocks base method.
+func (m *MockAuthorization) RevokeAllSessions(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockAuthorizationMockRecorder) RevokeAllSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockAuthorization)(nil).RevokeAllSessions), ctx, userID)
+}
+
+// SetUserRole mocks base method.
+func (m *MockAuthorization) SetUserRole(ctx context.Context, username, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, username, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockAuthorizationMockRecorder) SetUserRole(ctx, username, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockAuthorization)(nil).SetUserRole), ctx, username, role)
+}
+
+// UnlockUser mocks base method.
+func (m *MockAuthorization) UnlockUser(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockAuthorizationMockRecorder) UnlockUser(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockAuthorization)(nil).UnlockUser), ctx, username)
+}
+
+// MockAPIKey is a mock of APIKey interface.
+type MockAPIKey struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyMockRecorder
+}
+
+// MockAPIKeyMockRecorder is the mock recorder for MockAPIKey.
+type MockAPIKeyMockRecorder struct {
+	mock *MockAPIKey
+}
+
+// NewMockAPIKey creates a new mock instance.
+func NewMockAPIKey(ctrl *gomock.Controller) *MockAPIKey {
+	mock := &MockAPIKey{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKey) EXPECT() *MockAPIKeyMockRecorder {
+	return m.recorder
+}
+
+// AuthenticateAPIKey mocks base method.
+func (m *MockAPIKey) AuthenticateAPIKey(ctx context.Context, rawKey string) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", ctx, rawKey)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey.
+func (mr *MockAPIKeyMockRecorder) AuthenticateAPIKey(ctx, rawKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockAPIKey)(nil).AuthenticateAPIKey), ctx, rawKey)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAPIKey) CreateAPIKey(ctx context.Context, userID int64, input entity.CreateAPIKeyRequest) (entity.CreatedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, userID, input)
+	ret0, _ := ret[0].(entity.CreatedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAPIKeyMockRecorder) CreateAPIKey(ctx, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKey)(nil).CreateAPIKey), ctx, userID, input)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAPIKey) ListAPIKeys(ctx context.Context, userID int64) ([]entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, userID)
+	ret0, _ := ret[0].([]entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAPIKeyMockRecorder) ListAPIKeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAPIKey)(nil).ListAPIKeys), ctx, userID)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAPIKey) RevokeAPIKey(ctx context.Context, userID, keyID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, userID, keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAPIKeyMockRecorder) RevokeAPIKey(ctx, userID, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKey)(nil).RevokeAPIKey), ctx, userID, keyID)
+}
+
+// MockIdempotency is a mock of Idempotency interface.
+type MockIdempotency struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyMockRecorder
+}
+
+// MockIdempotencyMockRecorder is the mock recorder for MockIdempotency.
+type MockIdempotencyMockRecorder struct {
+	mock *MockIdempotency
+}
+
+// NewMockIdempotency creates a new mock instance.
+func NewMockIdempotency(ctrl *gomock.Controller) *MockIdempotency {
+	mock := &MockIdempotency{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotency) EXPECT() *MockIdempotencyMockRecorder {
+	return m.recorder
+}
+
+// Execute mocks base method.
+func (m *MockIdempotency) Execute(ctx context.Context, userID int64, key, requestHash string, operation func(context.Context) entity.IdempotentResponse) (entity.IdempotentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Execute", ctx, userID, key, requestHash, operation)
+	ret0, _ := ret[0].(entity.IdempotentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Execute indicates an expected call of Execute.
+func (mr *MockIdempotencyMockRecorder) Execute(ctx, userID, key, requestHash, operation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockIdempotency)(nil).Execute), ctx, userID, key, requestHash, operation)
 }
 
 // MockTransaction is a mock of Transaction interface.
@@ -117,6 +412,36 @@ func (m *MockTransaction) EXPECT() *MockTransactionMockRecorder {
 	return m.recorder
 }
 
+// GetHistory mocks base method.
+func (m *MockTransaction) GetHistory(ctx context.Context, userID int64, filter entity.HistoryFilter) (entity.HistoryPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, userID, filter)
+	ret0, _ := ret[0].(entity.HistoryPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockTransactionMockRecorder) GetHistory(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockTransaction)(nil).GetHistory), ctx, userID, filter)
+}
+
+// GetReceipt mocks base method.
+func (m *MockTransaction) GetReceipt(ctx context.Context, userID, transactionID int64) (entity.Receipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReceipt", ctx, userID, transactionID)
+	ret0, _ := ret[0].(entity.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReceipt indicates an expected call of GetReceipt.
+func (mr *MockTransactionMockRecorder) GetReceipt(ctx, userID, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceipt", reflect.TypeOf((*MockTransaction)(nil).GetReceipt), ctx, userID, transactionID)
+}
+
 // GetUserInfo mocks base method.
 func (m *MockTransaction) GetUserInfo(ctx context.Context, userID int64) (entity.InfoResponse, error) {
 	m.ctrl.T.Helper()
@@ -132,12 +457,28 @@ func (mr *MockTransactionMockRecorder) GetUserInfo(ctx, userID interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserInfo", reflect.TypeOf((*MockTransaction)(nil).GetUserInfo), ctx, userID)
 }
 
+// GetUserInfoSummary mocks base method.
+func (m *MockTransaction) GetUserInfoSummary(ctx context.Context, userID int64) (entity.InfoSummaryResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserInfoSummary", ctx, userID)
+	ret0, _ := ret[0].(entity.InfoSummaryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserInfoSummary indicates an expected call of GetUserInfoSummary.
+func (mr *MockTransactionMockRecorder) GetUserInfoSummary(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserInfoSummary", reflect.TypeOf((*MockTransaction)(nil).GetUserInfoSummary), ctx, userID)
+}
+
 // SendCoin mocks base method.
-func (m *MockTransaction) SendCoin(ctx context.Context, fromUserID int64, toUsername string, amount int64) error {
+func (m *MockTransaction) SendCoin(ctx context.Context, fromUserID int64, toUsername string, amount int64) (entity.Receipt, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendCoin", ctx, fromUserID, toUsername, amount)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(entity.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // SendCoin indicates an expected call of SendCoin.
@@ -170,11 +511,12 @@ func (m *MockInventory) EXPECT() *MockInventoryMockRecorder {
 }
 
 // BuyItem mocks base method.
-func (m *MockInventory) BuyItem(ctx context.Context, userID int64, itemName string) error {
+func (m *MockInventory) BuyItem(ctx context.Context, userID int64, itemName string) (entity.Receipt, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "BuyItem", ctx, userID, itemName)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(entity.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // BuyItem indicates an expected call of BuyItem.
@@ -182,3 +524,267 @@ func (mr *MockInventoryMockRecorder) BuyItem(ctx, userID, itemName interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuyItem", reflect.TypeOf((*MockInventory)(nil).BuyItem), ctx, userID, itemName)
 }
+
+// MockReversal is a mock of Reversal interface.
+type MockReversal struct {
+	ctrl     *gomock.Controller
+	recorder *MockReversalMockRecorder
+}
+
+// MockReversalMockRecorder is the mock recorder for MockReversal.
+type MockReversalMockRecorder struct {
+	mock *MockReversal
+}
+
+// NewMockReversal creates a new mock instance.
+func NewMockReversal(ctrl *gomock.Controller) *MockReversal {
+	mock := &MockReversal{ctrl: ctrl}
+	mock.recorder = &MockReversalMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReversal) EXPECT() *MockReversalMockRecorder {
+	return m.recorder
+}
+
+// RefundPurchase mocks base method.
+func (m *MockReversal) RefundPurchase(ctx context.Context, adminID, transactionID int64) (entity.Receipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundPurchase", ctx, adminID, transactionID)
+	ret0, _ := ret[0].(entity.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundPurchase indicates an expected call of RefundPurchase.
+func (mr *MockReversalMockRecorder) RefundPurchase(ctx, adminID, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundPurchase", reflect.TypeOf((*MockReversal)(nil).RefundPurchase), ctx, adminID, transactionID)
+}
+
+// ReverseTransfer mocks base method.
+func (m *MockReversal) ReverseTransfer(ctx context.Context, adminID, transactionID int64, forceNegative bool) (entity.Receipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTransfer", ctx, adminID, transactionID, forceNegative)
+	ret0, _ := ret[0].(entity.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransfer indicates an expected call of ReverseTransfer.
+func (mr *MockReversalMockRecorder) ReverseTransfer(ctx, adminID, transactionID, forceNegative interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransfer", reflect.TypeOf((*MockReversal)(nil).ReverseTransfer), ctx, adminID, transactionID, forceNegative)
+}
+
+// MockIssuance is a mock of Issuance interface.
+type MockIssuance struct {
+	ctrl     *gomock.Controller
+	recorder *MockIssuanceMockRecorder
+}
+
+// MockIssuanceMockRecorder is the mock recorder for MockIssuance.
+type MockIssuanceMockRecorder struct {
+	mock *MockIssuance
+}
+
+// NewMockIssuance creates a new mock instance.
+func NewMockIssuance(ctrl *gomock.Controller) *MockIssuance {
+	mock := &MockIssuance{ctrl: ctrl}
+	mock.recorder = &MockIssuanceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIssuance) EXPECT() *MockIssuanceMockRecorder {
+	return m.recorder
+}
+
+// BurnCoins mocks base method.
+func (m *MockIssuance) BurnCoins(ctx context.Context, adminID int64, input entity.BurnRequest) (entity.Receipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BurnCoins", ctx, adminID, input)
+	ret0, _ := ret[0].(entity.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BurnCoins indicates an expected call of BurnCoins.
+func (mr *MockIssuanceMockRecorder) BurnCoins(ctx, adminID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BurnCoins", reflect.TypeOf((*MockIssuance)(nil).BurnCoins), ctx, adminID, input)
+}
+
+// MintCoins mocks base method.
+func (m *MockIssuance) MintCoins(ctx context.Context, adminID int64, input entity.MintRequest) (entity.MintResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MintCoins", ctx, adminID, input)
+	ret0, _ := ret[0].(entity.MintResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MintCoins indicates an expected call of MintCoins.
+func (mr *MockIssuanceMockRecorder) MintCoins(ctx, adminID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MintCoins", reflect.TypeOf((*MockIssuance)(nil).MintCoins), ctx, adminID, input)
+}
+
+// MockAllowance is a mock of Allowance interface.
+type MockAllowance struct {
+	ctrl     *gomock.Controller
+	recorder *MockAllowanceMockRecorder
+}
+
+// MockAllowanceMockRecorder is the mock recorder for MockAllowance.
+type MockAllowanceMockRecorder struct {
+	mock *MockAllowance
+}
+
+// NewMockAllowance creates a new mock instance.
+func NewMockAllowance(ctrl *gomock.Controller) *MockAllowance {
+	mock := &MockAllowance{ctrl: ctrl}
+	mock.recorder = &MockAllowanceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAllowance) EXPECT() *MockAllowanceMockRecorder {
+	return m.recorder
+}
+
+// ApplyDueAllowances mocks base method.
+func (m *MockAllowance) ApplyDueAllowances(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyDueAllowances", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyDueAllowances indicates an expected call of ApplyDueAllowances.
+func (mr *MockAllowanceMockRecorder) ApplyDueAllowances(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyDueAllowances", reflect.TypeOf((*MockAllowance)(nil).ApplyDueAllowances), ctx, now)
+}
+
+// CreateAllowance mocks base method.
+func (m *MockAllowance) CreateAllowance(ctx context.Context, adminID int64, input entity.CreateAllowanceRequest) (entity.Allowance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAllowance", ctx, adminID, input)
+	ret0, _ := ret[0].(entity.Allowance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAllowance indicates an expected call of CreateAllowance.
+func (mr *MockAllowanceMockRecorder) CreateAllowance(ctx, adminID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAllowance", reflect.TypeOf((*MockAllowance)(nil).CreateAllowance), ctx, adminID, input)
+}
+
+// DisableAllowance mocks base method.
+func (m *MockAllowance) DisableAllowance(ctx context.Context, adminID, allowanceID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableAllowance", ctx, adminID, allowanceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableAllowance indicates an expected call of DisableAllowance.
+func (mr *MockAllowanceMockRecorder) DisableAllowance(ctx, adminID, allowanceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableAllowance", reflect.TypeOf((*MockAllowance)(nil).DisableAllowance), ctx, adminID, allowanceID)
+}
+
+// ListAllowances mocks base method.
+func (m *MockAllowance) ListAllowances(ctx context.Context) ([]entity.Allowance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllowances", ctx)
+	ret0, _ := ret[0].([]entity.Allowance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllowances indicates an expected call of ListAllowances.
+func (mr *MockAllowanceMockRecorder) ListAllowances(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllowances", reflect.TypeOf((*MockAllowance)(nil).ListAllowances), ctx)
+}
+
+// MockExpiration is a mock of Expiration interface.
+type MockExpiration struct {
+	ctrl     *gomock.Controller
+	recorder *MockExpirationMockRecorder
+}
+
+// MockExpirationMockRecorder is the mock recorder for MockExpiration.
+type MockExpirationMockRecorder struct {
+	mock *MockExpiration
+}
+
+// NewMockExpiration creates a new mock instance.
+func NewMockExpiration(ctrl *gomock.Controller) *MockExpiration {
+	mock := &MockExpiration{ctrl: ctrl}
+	mock.recorder = &MockExpirationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExpiration) EXPECT() *MockExpirationMockRecorder {
+	return m.recorder
+}
+
+// ExpireCoins mocks base method.
+func (m *MockExpiration) ExpireCoins(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireCoins", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireCoins indicates an expected call of ExpireCoins.
+func (mr *MockExpirationMockRecorder) ExpireCoins(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireCoins", reflect.TypeOf((*MockExpiration)(nil).ExpireCoins), ctx, now)
+}
+
+// MockReconciliation is a mock of Reconciliation interface.
+type MockReconciliation struct {
+	ctrl     *gomock.Controller
+	recorder *MockReconciliationMockRecorder
+}
+
+// MockReconciliationMockRecorder is the mock recorder for MockReconciliation.
+type MockReconciliationMockRecorder struct {
+	mock *MockReconciliation
+}
+
+// NewMockReconciliation creates a new mock instance.
+func NewMockReconciliation(ctrl *gomock.Controller) *MockReconciliation {
+	mock := &MockReconciliation{ctrl: ctrl}
+	mock.recorder = &MockReconciliationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReconciliation) EXPECT() *MockReconciliationMockRecorder {
+	return m.recorder
+}
+
+// Reconcile mocks base method.
+func (m *MockReconciliation) Reconcile(ctx context.Context, repair bool) (entity.ReconciliationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", ctx, repair)
+	ret0, _ := ret[0].(entity.ReconciliationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockReconciliationMockRecorder) Reconcile(ctx, repair interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockReconciliation)(nil).Reconcile), ctx, repair)
+}
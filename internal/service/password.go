@@ -0,0 +1,258 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+const (
+	HashAlgorithmArgon2id = "argon2id"
+	HashAlgorithmBcrypt   = "bcrypt"
+
+	legacySalt = "random_salt_string"
+)
+
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encodedHash string) (bool, error)
+	NeedsRehash(encodedHash string) bool
+}
+
+type hashScheme interface {
+	match(encodedHash string) bool
+	hash(password string) (string, error)
+	verify(password, encodedHash string) (bool, error)
+	outdated(encodedHash string) bool
+}
+
+// passwordHasher хеширует новые пароли основной схемой, но умеет проверять хеши всех известных схем,
+// чтобы существующие аккаунты продолжали работать после смены алгоритма.
+type passwordHasher struct {
+	primary hashScheme
+	schemes []hashScheme
+}
+
+func NewPasswordHasher(algorithm string) (PasswordHasher, error) {
+	argon := newArgon2idScheme()
+	bcr := bcryptScheme{cost: bcrypt.DefaultCost}
+
+	h := &passwordHasher{
+		schemes: []hashScheme{argon, bcr, legacySHA256Scheme{}},
+	}
+
+	switch algorithm {
+	case HashAlgorithmArgon2id, "":
+		h.primary = argon
+	case HashAlgorithmBcrypt:
+		h.primary = bcr
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %s", algorithm)
+	}
+
+	return h, nil
+}
+
+func (h *passwordHasher) Hash(password string) (string, error) {
+	return h.primary.hash(password)
+}
+
+func (h *passwordHasher) Verify(password, encodedHash string) (bool, error) {
+	scheme := h.schemeFor(encodedHash)
+	if scheme == nil {
+		return false, entity.ErrUnknownHashFormat
+	}
+
+	return scheme.verify(password, encodedHash)
+}
+
+func (h *passwordHasher) NeedsRehash(encodedHash string) bool {
+	if !h.primary.match(encodedHash) {
+		return true
+	}
+
+	return h.primary.outdated(encodedHash)
+}
+
+func (h *passwordHasher) schemeFor(encodedHash string) hashScheme {
+	for _, scheme := range h.schemes {
+		if scheme.match(encodedHash) {
+			return scheme
+		}
+	}
+
+	return nil
+}
+
+type argon2idScheme struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	saltLen uint32
+	keyLen  uint32
+}
+
+func newArgon2idScheme() argon2idScheme {
+	return argon2idScheme{
+		memory:  64 * 1024,
+		time:    1,
+		threads: 4,
+		saltLen: 16,
+		keyLen:  32,
+	}
+}
+
+func (s argon2idScheme) match(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$argon2id$")
+}
+
+func (s argon2idScheme) hash(password string) (string, error) {
+	salt := make([]byte, s.saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, s.time, s.memory, s.threads, s.keyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, s.memory, s.time, s.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (s argon2idScheme) verify(password, encodedHash string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, otherKey) == 1, nil
+}
+
+func (s argon2idScheme) outdated(encodedHash string) bool {
+	params, salt, key, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return params.memory != s.memory || params.time != s.time || params.threads != s.threads ||
+		uint32(len(salt)) != s.saltLen || uint32(len(key)) != s.keyLen
+}
+
+func decodeArgon2id(encodedHash string) (argon2idScheme, []byte, []byte, error) {
+	var params argon2idScheme
+
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, entity.ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, entity.ErrUnknownHashFormat
+	}
+	if version != argon2.Version {
+		return params, nil, nil, entity.ErrUnknownHashFormat
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, nil, entity.ErrUnknownHashFormat
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, entity.ErrUnknownHashFormat
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, entity.ErrUnknownHashFormat
+	}
+
+	return params, salt, key, nil
+}
+
+type bcryptScheme struct {
+	cost int
+}
+
+func (s bcryptScheme) match(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$2a$") ||
+		strings.HasPrefix(encodedHash, "$2b$") ||
+		strings.HasPrefix(encodedHash, "$2y$")
+}
+
+func (s bcryptScheme) hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+func (s bcryptScheme) verify(password, encodedHash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s bcryptScheme) outdated(encodedHash string) bool {
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	if err != nil {
+		return true
+	}
+
+	return cost != s.cost
+}
+
+// legacySHA256Scheme используется только для проверки хешей, созданных до перехода на адаптивное хеширование.
+type legacySHA256Scheme struct{}
+
+func (s legacySHA256Scheme) match(encodedHash string) bool {
+	if len(encodedHash) != sha256.Size*2 {
+		return false
+	}
+
+	for _, r := range encodedHash {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s legacySHA256Scheme) hash(password string) (string, error) {
+	return generatePasswordHash(password), nil
+}
+
+func (s legacySHA256Scheme) verify(password, encodedHash string) (bool, error) {
+	return subtle.ConstantTimeCompare([]byte(generatePasswordHash(password)), []byte(encodedHash)) == 1, nil
+}
+
+func (s legacySHA256Scheme) outdated(string) bool {
+	return true
+}
+
+func generatePasswordHash(password string) string {
+	hash := sha256.Sum256([]byte(password + legacySalt))
+	return fmt.Sprintf("%x", hash)
+}
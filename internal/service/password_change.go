@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+// ChangePassword проверяет текущий пароль и сохраняет новый. Все ранее выданные токены пользователя
+// отзываются, а вызывающему выдается новая пара токенов, чтобы его сессия продолжилась.
+func (s *AuthService) ChangePassword(ctx context.Context, userID int64, currentPassword, newPassword string) (entity.AuthResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("ChangePassword: failed to fetch user %d: %v", userID, err)
+		return entity.AuthResponse{}, err
+	}
+
+	if s.cfg.Throttle.enabled() {
+		if err := s.checkLoginLockout(ctx, user.Username, ""); err != nil {
+			return entity.AuthResponse{}, err
+		}
+	}
+
+	ok, err := s.hasher.Verify(currentPassword, user.Password)
+	if err != nil {
+		s.log.Errorf("ChangePassword: failed to verify password for user %s: %v", user.Username, err)
+		return entity.AuthResponse{}, err
+	}
+	if !ok {
+		s.log.Warnf("ChangePassword: invalid current password for user %s", user.Username)
+		s.registerLoginFailure(ctx, user.Username, "")
+		return entity.AuthResponse{}, entity.ErrIncorrectPassword
+	}
+
+	if err := s.cfg.Policy.ValidatePassword(user.Username, newPassword); err != nil {
+		s.log.Warnf("ChangePassword: new password rejected for user %s: %v", user.Username, err)
+		return entity.AuthResponse{}, err
+	}
+	if newPassword == currentPassword {
+		return entity.AuthResponse{}, fmt.Errorf("%w: new password must differ from the current one", entity.ErrWeakPassword)
+	}
+
+	hashedPassword, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		s.log.Errorf("ChangePassword: failed to hash password for user %s: %v", user.Username, err)
+		return entity.AuthResponse{}, err
+	}
+
+	var tokens entity.AuthResponse
+	var revokedBefore time.Time
+	err = s.trManager.Do(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.UpdatePasswordHash(ctx, user.ID, hashedPassword); err != nil {
+			s.log.Errorf("ChangePassword: failed to update password hash for user %s: %v", user.Username, err)
+			return err
+		}
+
+		revokedBefore, err = s.revokeAllSessions(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		tokens, err = s.startSession(ctx, user.ID, user.Role)
+		if err != nil {
+			s.log.Errorf("ChangePassword: failed to issue tokens for user %s: %v", user.Username, err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entity.AuthResponse{}, err
+	}
+
+	s.revocations.revokeUser(user.ID, revokedBefore)
+	s.resetLoginFailures(ctx, user.Username)
+
+	s.log.Infof("Password of user %s changed", user.Username)
+	return tokens, nil
+}
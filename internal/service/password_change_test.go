@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockRefreshRepo := mocks.NewMockRefreshTokenRepository(ctrl)
+	mockRevocationRepo := mocks.NewMockTokenRevocationRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	mockLog := logrus.New()
+	hasher := newTestHasher(t)
+	authService := NewAuthService(mockUserRepo, mockRefreshRepo, mockRevocationRepo, nil, nil, nil, nil, mockTrManager, hasher, testAuthConfig, mockLog)
+
+	currentHash, err := hasher.Hash(testPassword)
+	assert.NoError(t, err)
+	user := entity.User{ID: testUserID, Username: testUsername, Password: currentHash, Role: entity.RoleUser}
+
+	const newPassword = "brand-new-secret"
+
+	tests := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		mockBehavior    func()
+		wantErr         error
+		wantTokens      bool
+	}{
+		{
+			name:            "Success",
+			currentPassword: testPassword,
+			newPassword:     newPassword,
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().UpdatePasswordHash(gomock.Any(), testUserID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int64, hash string) error {
+						ok, err := hasher.Verify(newPassword, hash)
+						assert.NoError(t, err)
+						assert.True(t, ok)
+						return nil
+					})
+				mockRevocationRepo.EXPECT().RevokeUserTokens(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockRefreshRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), testUserID).Return(nil)
+				mockRefreshRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+				mock.ExpectCommit()
+			},
+			wantErr:    nil,
+			wantTokens: true,
+		},
+		{
+			name:            "Incorrect Current Password",
+			currentPassword: "wrong-password",
+			newPassword:     newPassword,
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+			},
+			wantErr: entity.ErrIncorrectPassword,
+		},
+		{
+			name:            "Weak New Password",
+			currentPassword: testPassword,
+			newPassword:     "short",
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+			},
+			wantErr: entity.ErrWeakPassword,
+		},
+		{
+			name:            "Same Password",
+			currentPassword: testPassword,
+			newPassword:     testPassword,
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+			},
+			wantErr: entity.ErrWeakPassword,
+		},
+		{
+			name:            "Revocation Failure Rolls Back",
+			currentPassword: testPassword,
+			newPassword:     newPassword,
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().UpdatePasswordHash(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockRevocationRepo.EXPECT().RevokeUserTokens(gomock.Any(), testUserID, gomock.Any()).Return(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			tokens, err := authService.ChangePassword(context.Background(), testUserID, tt.currentPassword, tt.newPassword)
+
+			if tt.wantErr != nil {
+				assert.ErrorContains(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantTokens, tokens.Token != "")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
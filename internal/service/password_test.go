@@ -0,0 +1,123 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func newTestHasher(t *testing.T) PasswordHasher {
+	t.Helper()
+
+	hasher, err := NewPasswordHasher(HashAlgorithmArgon2id)
+	assert.NoError(t, err)
+
+	return hasher
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	tests := []struct {
+		name       string
+		algorithm  string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{
+			name:       "Argon2id",
+			algorithm:  HashAlgorithmArgon2id,
+			wantPrefix: "$argon2id$",
+		},
+		{
+			name:       "Bcrypt",
+			algorithm:  HashAlgorithmBcrypt,
+			wantPrefix: "$2a$",
+		},
+		{
+			name:       "Default",
+			algorithm:  "",
+			wantPrefix: "$argon2id$",
+		},
+		{
+			name:      "Unsupported",
+			algorithm: "md5",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher, err := NewPasswordHasher(tt.algorithm)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			hash, err := hasher.Hash("password")
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(hash, tt.wantPrefix))
+		})
+	}
+}
+
+func TestPasswordHasher_Verify(t *testing.T) {
+	argonHasher := newTestHasher(t)
+	bcryptHasher, err := NewPasswordHasher(HashAlgorithmBcrypt)
+	assert.NoError(t, err)
+
+	argonHash, err := argonHasher.Hash("password")
+	assert.NoError(t, err)
+	bcryptHash, err := bcryptHasher.Hash("password")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		hash     string
+		wantOK   bool
+		wantErr  error
+	}{
+		{name: "Argon2id Match", password: "password", hash: argonHash, wantOK: true},
+		{name: "Argon2id Mismatch", password: "wrong", hash: argonHash, wantOK: false},
+		{name: "Bcrypt Match", password: "password", hash: bcryptHash, wantOK: true},
+		{name: "Bcrypt Mismatch", password: "wrong", hash: bcryptHash, wantOK: false},
+		{name: "Legacy Match", password: "password", hash: generatePasswordHash("password"), wantOK: true},
+		{name: "Legacy Mismatch", password: "wrong", hash: generatePasswordHash("password"), wantOK: false},
+		{name: "Unknown Format", password: "password", hash: "plain", wantErr: entity.ErrUnknownHashFormat},
+		{name: "Malformed Argon2id", password: "password", hash: "$argon2id$v=19$broken", wantErr: entity.ErrUnknownHashFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := argonHasher.Verify(tt.password, tt.hash)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestPasswordHasher_NeedsRehash(t *testing.T) {
+	argonHasher := newTestHasher(t)
+	bcryptHasher, err := NewPasswordHasher(HashAlgorithmBcrypt)
+	assert.NoError(t, err)
+
+	argonHash, err := argonHasher.Hash("password")
+	assert.NoError(t, err)
+	bcryptHash, err := bcryptHasher.Hash("password")
+	assert.NoError(t, err)
+
+	weakArgon := argon2idScheme{memory: 8 * 1024, time: 1, threads: 1, saltLen: 16, keyLen: 32}
+	weakArgonHash, err := weakArgon.hash("password")
+	assert.NoError(t, err)
+
+	assert.False(t, argonHasher.NeedsRehash(argonHash))
+	assert.True(t, argonHasher.NeedsRehash(bcryptHash))
+	assert.True(t, argonHasher.NeedsRehash(weakArgonHash))
+	assert.True(t, argonHasher.NeedsRehash(generatePasswordHash("password")))
+	assert.False(t, bcryptHasher.NeedsRehash(bcryptHash))
+	assert.True(t, bcryptHasher.NeedsRehash(argonHash))
+}
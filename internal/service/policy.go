@@ -0,0 +1,44 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+const maxPasswordLength = 72
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.@-]{3,64}$`)
+
+type CredentialsPolicy struct {
+	MinPasswordLength int
+}
+
+func (p CredentialsPolicy) Validate(username, password string) error {
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("%w: username must be 3-64 characters long and contain only letters, digits and ._@-",
+			entity.ErrInvalidUsername)
+	}
+
+	return p.ValidatePassword(username, password)
+}
+
+func (p CredentialsPolicy) ValidatePassword(username, password string) error {
+	length := utf8.RuneCountInString(password)
+
+	switch {
+	case length < p.MinPasswordLength:
+		return fmt.Errorf("%w: password must be at least %d characters long", entity.ErrWeakPassword, p.MinPasswordLength)
+	case len(password) > maxPasswordLength:
+		return fmt.Errorf("%w: password must be at most %d bytes long", entity.ErrWeakPassword, maxPasswordLength)
+	case strings.TrimSpace(password) == "":
+		return fmt.Errorf("%w: password must not be blank", entity.ErrWeakPassword)
+	case strings.Contains(strings.ToLower(password), strings.ToLower(username)):
+		return fmt.Errorf("%w: password must not contain the username", entity.ErrWeakPassword)
+	}
+
+	return nil
+}
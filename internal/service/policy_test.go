@@ -0,0 +1,42 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func TestCredentialsPolicy_Validate(t *testing.T) {
+	policy := CredentialsPolicy{MinPasswordLength: 8}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{name: "Valid", username: "alice", password: "correct horse", wantErr: nil},
+		{name: "Valid Email Username", username: "alice@example.com", password: "correct horse", wantErr: nil},
+		{name: "Too Short Username", username: "al", password: "correct horse", wantErr: entity.ErrInvalidUsername},
+		{name: "Username With Spaces", username: "al ice", password: "correct horse", wantErr: entity.ErrInvalidUsername},
+		{name: "Too Short Password", username: "alice", password: "1234567", wantErr: entity.ErrWeakPassword},
+		{name: "Too Long Password", username: "alice", password: strings.Repeat("x", 73), wantErr: entity.ErrWeakPassword},
+		{name: "Blank Password", username: "alice", password: "          ", wantErr: entity.ErrWeakPassword},
+		{name: "Password Contains Username", username: "alice", password: "ALICE12345", wantErr: entity.ErrWeakPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.username, tt.password)
+
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/sirupsen/logrus"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/repository"
+)
+
+// driftedAccounts — число счетов с неустраненным расхождением по итогам последней сверки.
+var driftedAccounts = expvar.NewInt("balance_drifted_accounts")
+
+type ReconciliationService struct {
+	userRepo   repository.UserRepository
+	ledgerRepo repository.LedgerRepository
+	trManager  *manager.Manager
+	log        *logrus.Logger
+}
+
+func NewReconciliationService(
+	userRepo repository.UserRepository,
+	ledgerRepo repository.LedgerRepository,
+	trManager *manager.Manager,
+	log *logrus.Logger) *ReconciliationService {
+	return &ReconciliationService{
+		userRepo:   userRepo,
+		ledgerRepo: ledgerRepo,
+		trManager:  trManager,
+		log:        log,
+	}
+}
+
+// Reconcile сверяет users.coins с остатками счетов в журнале. Журнал ведется от балансов, восстановленных
+// по истории операций, поэтому расхождения, накопленные до его появления, тоже обнаруживаются. С repair расходящиеся балансы
+// перезаписываются значением из журнала; балансы, изменившиеся во время сверки, и пользователи
+// без счета не трогаются.
+func (s *ReconciliationService) Reconcile(ctx context.Context, repair bool) (entity.ReconciliationReport, error) {
+	report := entity.ReconciliationReport{CheckedAt: time.Now().UTC()}
+
+	err := s.trManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+
+		report.CheckedAccounts, err = s.userRepo.CountUsers(ctx)
+		if err != nil {
+			s.log.Errorf("Reconcile failed: failed to count users: %v", err)
+			return err
+		}
+
+		report.Drifts, err = s.ledgerRepo.GetBalanceDrifts(ctx)
+		if err != nil {
+			s.log.Errorf("Reconcile failed: failed to fetch balance drifts: %v", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entity.ReconciliationReport{}, err
+	}
+
+	for i := range report.Drifts {
+		drift := &report.Drifts[i]
+		drift.Drift = drift.Cached - drift.Expected
+
+		if !repair || drift.MissingAccount {
+			continue
+		}
+
+		drift.Repaired, err = s.userRepo.SetCoins(ctx, drift.UserID, drift.Cached, drift.Expected)
+		if err != nil {
+			s.log.Errorf("Reconcile failed: failed to repair balance of user %d: %v", drift.UserID, err)
+			return entity.ReconciliationReport{}, err
+		}
+		if drift.Repaired {
+			report.RepairedAccounts++
+			s.log.Warnf("Reconcile: balance of user %d repaired from %d to %d", drift.UserID, drift.Cached, drift.Expected)
+		}
+	}
+
+	if report.Drifts == nil {
+		report.Drifts = make([]entity.BalanceDrift, 0)
+	}
+	report.DriftedAccounts = len(report.Drifts)
+	driftedAccounts.Set(int64(report.DriftedAccounts - report.RepairedAccounts))
+
+	s.log.Infof("Reconciliation finished: %d accounts checked, %d drifted, %d repaired",
+		report.CheckedAccounts, report.DriftedAccounts, report.RepairedAccounts)
+	return report, nil
+}
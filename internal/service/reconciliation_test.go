@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func TestReconciliationService_Reconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+
+	service := NewReconciliationService(mockUserRepo, mockLedgerRepo, mockTrManager, logrus.New())
+
+	drifts := func() []entity.BalanceDrift {
+		return []entity.BalanceDrift{
+			{UserID: 1, Username: "alice", Cached: 950, Expected: 900},
+			{UserID: 2, Username: "bob", Cached: 1000, Expected: 0, MissingAccount: true},
+			{UserID: 3, Username: "carol", Cached: 100, Expected: 120},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		repair       bool
+		mockBehavior func()
+		wantReport   entity.ReconciliationReport
+		wantMetric   int64
+		wantErr      error
+	}{
+		{
+			name: "No Drift",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().CountUsers(gomock.Any()).Return(3, nil)
+				mockLedgerRepo.EXPECT().GetBalanceDrifts(gomock.Any()).Return(nil, nil)
+				mock.ExpectCommit()
+			},
+			wantReport: entity.ReconciliationReport{CheckedAccounts: 3, Drifts: []entity.BalanceDrift{}},
+			wantMetric: 0,
+		},
+		{
+			name: "Report Only",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().CountUsers(gomock.Any()).Return(5, nil)
+				mockLedgerRepo.EXPECT().GetBalanceDrifts(gomock.Any()).Return(drifts(), nil)
+				mock.ExpectCommit()
+			},
+			wantReport: entity.ReconciliationReport{
+				CheckedAccounts: 5,
+				DriftedAccounts: 3,
+				Drifts: []entity.BalanceDrift{
+					{UserID: 1, Username: "alice", Cached: 950, Expected: 900, Drift: 50},
+					{UserID: 2, Username: "bob", Cached: 1000, Expected: 0, Drift: 1000, MissingAccount: true},
+					{UserID: 3, Username: "carol", Cached: 100, Expected: 120, Drift: -20},
+				},
+			},
+			wantMetric: 3,
+		},
+		{
+			name:   "Repair",
+			repair: true,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().CountUsers(gomock.Any()).Return(5, nil)
+				mockLedgerRepo.EXPECT().GetBalanceDrifts(gomock.Any()).Return(drifts(), nil)
+				mock.ExpectCommit()
+				mockUserRepo.EXPECT().SetCoins(gomock.Any(), int64(1), int64(950), int64(900)).Return(true, nil)
+				mockUserRepo.EXPECT().SetCoins(gomock.Any(), int64(3), int64(100), int64(120)).Return(false, nil)
+			},
+			wantReport: entity.ReconciliationReport{
+				CheckedAccounts:  5,
+				DriftedAccounts:  3,
+				RepairedAccounts: 1,
+				Drifts: []entity.BalanceDrift{
+					{UserID: 1, Username: "alice", Cached: 950, Expected: 900, Drift: 50, Repaired: true},
+					{UserID: 2, Username: "bob", Cached: 1000, Expected: 0, Drift: 1000, MissingAccount: true},
+					{UserID: 3, Username: "carol", Cached: 100, Expected: 120, Drift: -20},
+				},
+			},
+			wantMetric: 2,
+		},
+		{
+			name: "Drift Query Error",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().CountUsers(gomock.Any()).Return(5, nil)
+				mockLedgerRepo.EXPECT().GetBalanceDrifts(gomock.Any()).Return(nil, errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+			driftedAccounts.Set(-1)
+
+			report, err := service.Reconcile(context.Background(), tt.repair)
+
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				assert.Equal(t, int64(-1), driftedAccounts.Value())
+				return
+			}
+
+			assert.False(t, report.CheckedAt.IsZero())
+			report.CheckedAt = tt.wantReport.CheckedAt
+			assert.Equal(t, tt.wantReport, report)
+			assert.Equal(t, tt.wantMetric, driftedAccounts.Value())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
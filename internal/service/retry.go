@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	trmsql "github.com/avito-tech/go-transaction-manager/drivers/sql/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2"
+	trmcontext "github.com/avito-tech/go-transaction-manager/trm/v2/context"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/settings"
+	"github.com/sirupsen/logrus"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/repository"
+)
+
+const (
+	opSendCoin        = "sendCoin"
+	opBuyItem         = "buyItem"
+	opReverseTransfer = "reverseTransfer"
+	opRefundPurchase  = "refundPurchase"
+	opMintCoins       = "mintCoins"
+	opBurnCoins       = "burnCoins"
+	opApplyAllowance  = "applyAllowance"
+	opExpireCoins     = "expireCoins"
+	opIdempotent      = "idempotent"
+)
+
+var (
+	// transactionRetries — число повторов транзакций по операциям.
+	transactionRetries = expvar.NewMap("transaction_retries")
+	// transactionRetriesExhausted — сколько раз операция так и не прошла за отведенные попытки.
+	transactionRetriesExhausted = expvar.NewMap("transaction_retries_exhausted")
+)
+
+// TxConfig задает повторы денежных транзакций, прерванных Postgres из-за конфликта с параллельной
+// транзакцией, и уровни изоляции операций.
+type TxConfig struct {
+	MaxAttempts       int
+	BaseDelay         time.Duration
+	MaxDelay          time.Duration
+	SendCoinIsolation sql.IsolationLevel
+	BuyItemIsolation  sql.IsolationLevel
+}
+
+// isolation возвращает уровень изоляции операции. Отмены выполняются с тем же уровнем,
+// что и операции, которые они отменяют.
+func (c TxConfig) isolation(operation string) sql.IsolationLevel {
+	switch operation {
+	case opSendCoin, opReverseTransfer:
+		return c.SendCoinIsolation
+	case opBuyItem, opRefundPurchase:
+		return c.BuyItemIsolation
+	default:
+		return sql.LevelDefault
+	}
+}
+
+// strictestIsolation — уровень для транзакции, внутри которой может выполниться любая из операций.
+func (c TxConfig) strictestIsolation() sql.IsolationLevel {
+	return max(c.SendCoinIsolation, c.BuyItemIsolation)
+}
+
+// ParseIsolationLevel разбирает уровень изоляции в записи SQL, например "repeatable read".
+// Пустая строка означает уровень по умолчанию для базы.
+func ParseIsolationLevel(level string) (sql.IsolationLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "":
+		return sql.LevelDefault, nil
+	case "read committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	default:
+		return sql.LevelDefault, fmt.Errorf("unsupported isolation level: %s", level)
+	}
+}
+
+func isolationSettings(level sql.IsolationLevel) trm.Settings {
+	return trmsql.MustSettings(settings.Must(), trmsql.WithTxOptions(&sql.TxOptions{Isolation: level}))
+}
+
+// txRunner выполняет операцию в транзакции и повторяет ее целиком при serialization failure или deadlock.
+type txRunner struct {
+	trManager *manager.Manager
+	cfg       TxConfig
+	log       *logrus.Logger
+}
+
+// do повторяет только транзакции, которые сам открыл: если операция выполняется внутри чужой
+// транзакции (например, запроса с Idempotency-Key), повторять ее часть бессмысленно, поэтому
+// конфликт возвращается как entity.ErrTransactionConflict и передается через reportConflict
+// тому, кто открыл внешнюю транзакцию и может повторить ее целиком.
+func (r txRunner) do(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	if trmcontext.DefaultManager.Default(ctx) != nil {
+		err := r.trManager.Do(ctx, fn)
+		if repository.IsRetryable(err) {
+			reportConflict(ctx, err)
+			return fmt.Errorf("%w: %w", entity.ErrTransactionConflict, err)
+		}
+		return err
+	}
+
+	return r.retry(ctx, operation, isolationSettings(r.cfg.isolation(operation)), fn)
+}
+
+// retry выполняет fn в новой транзакции и повторяет ее с задержкой, пока ошибка (в том числе
+// ошибка коммита) остается serialization failure или deadlock.
+func (r txRunner) retry(ctx context.Context, operation string, txSettings trm.Settings, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := r.trManager.DoWithSettings(ctx, txSettings, fn)
+		if !repository.IsRetryable(err) {
+			return err
+		}
+
+		if attempt >= r.cfg.MaxAttempts {
+			transactionRetriesExhausted.Add(operation, 1)
+			r.log.Errorf("%s: transaction conflict persisted after %d attempts: %v", operation, attempt, err)
+			return fmt.Errorf("%w: %w", entity.ErrTransactionConflict, err)
+		}
+
+		transactionRetries.Add(operation, 1)
+		delay := r.backoff(attempt)
+		r.log.Warnf("%s: transaction conflict on attempt %d, retrying in %s: %v", operation, attempt, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+type conflictKey struct{}
+
+// withConflictReport возвращает контекст, в который вложенные операции сообщают о конфликте.
+// Нужен там, где ошибку операции поглощает обработчик, превращая ее в ответ.
+func withConflictReport(ctx context.Context) (context.Context, *error) {
+	var conflict error
+	return context.WithValue(ctx, conflictKey{}, &conflict), &conflict
+}
+
+func reportConflict(ctx context.Context, err error) {
+	if conflict, ok := ctx.Value(conflictKey{}).(*error); ok {
+		*conflict = err
+	}
+}
+
+// backoff удваивает задержку с каждой попыткой (не более MaxDelay) и выбирает случайное значение
+// из второй половины интервала, чтобы конфликтующие запросы не повторялись одновременно.
+func (r txRunner) backoff(attempt int) time.Duration {
+	delay := r.cfg.BaseDelay
+	for i := 1; i < attempt && delay < r.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > r.cfg.MaxDelay {
+		delay = r.cfg.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+func TestTxRunner_Do(t *testing.T) {
+	serializationFailure := &pq.Error{Code: "40001"}
+	deadlock := &pq.Error{Code: "40P01"}
+
+	tests := []struct {
+		name          string
+		results       []error
+		wantErr       error
+		wantAttempts  int
+		wantRetries   int64
+		wantExhausted int64
+	}{
+		{
+			name:         "Success",
+			results:      []error{nil},
+			wantAttempts: 1,
+		},
+		{
+			name:         "Retry After Serialization Failure",
+			results:      []error{serializationFailure, deadlock, nil},
+			wantAttempts: 3,
+			wantRetries:  2,
+		},
+		{
+			name:          "Attempts Exhausted",
+			results:       []error{deadlock, deadlock, deadlock},
+			wantErr:       entity.ErrTransactionConflict,
+			wantAttempts:  3,
+			wantRetries:   2,
+			wantExhausted: 1,
+		},
+		{
+			name:         "Non Retryable Error",
+			results:      []error{entity.ErrInsufficientBalance},
+			wantErr:      entity.ErrInsufficientBalance,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(sqlx.NewDb(db, testDriverName)))
+			runner := txRunner{
+				trManager: mockTrManager,
+				cfg:       TxConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond},
+				log:       logrus.New(),
+			}
+
+			for _, result := range tt.results {
+				mock.ExpectBegin()
+				if result == nil {
+					mock.ExpectCommit()
+				} else {
+					mock.ExpectRollback()
+				}
+			}
+
+			operation := "test" + tt.name
+			attempts := 0
+			err := runner.do(context.Background(), operation, func(ctx context.Context) error {
+				attempts++
+				return tt.results[attempts-1]
+			})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantAttempts, attempts)
+			assert.Equal(t, tt.wantRetries, expvarMapValue(transactionRetries, operation))
+			assert.Equal(t, tt.wantExhausted, expvarMapValue(transactionRetriesExhausted, operation))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTxRunner_DoNested(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(sqlx.NewDb(db, testDriverName)))
+	runner := txRunner{trManager: mockTrManager, cfg: TxConfig{MaxAttempts: 3}, log: logrus.New()}
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	attempts := 0
+	err := mockTrManager.Do(context.Background(), func(ctx context.Context) error {
+		return runner.do(ctx, "testNested", func(ctx context.Context) error {
+			attempts++
+			return &pq.Error{Code: "40001"}
+		})
+	})
+
+	assert.ErrorIs(t, err, entity.ErrTransactionConflict)
+	assert.Equal(t, 1, attempts, "a transaction opened by the caller must not be retried")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTxRunner_Backoff(t *testing.T) {
+	runner := txRunner{cfg: TxConfig{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}}
+
+	for attempt, want := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 40 * time.Millisecond, 10: 50 * time.Millisecond} {
+		for range 20 {
+			delay := runner.backoff(attempt)
+			assert.GreaterOrEqual(t, delay, want/2)
+			assert.LessOrEqual(t, delay, want)
+		}
+	}
+}
+
+func TestParseIsolationLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    sql.IsolationLevel
+		wantErr bool
+	}{
+		{input: "", want: sql.LevelDefault},
+		{input: "read committed", want: sql.LevelReadCommitted},
+		{input: "Repeatable Read", want: sql.LevelRepeatableRead},
+		{input: "SERIALIZABLE", want: sql.LevelSerializable},
+		{input: "snapshot", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, err := ParseIsolationLevel(tt.input)
+
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, level)
+		})
+	}
+}
+
+func expvarMapValue(m *expvar.Map, key string) int64 {
+	value, ok := m.Get(key).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+
+	return value.Value()
+}
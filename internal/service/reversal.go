@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/sirupsen/logrus"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/repository"
+)
+
+// ReversalService отменяет операции по запросу администратора. Исходная операция не меняется:
+// отмена записывается отдельной операцией со ссылкой на нее и компенсирующей записью журнала.
+type ReversalService struct {
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
+	inventoryRepo   repository.InventoryRepository
+	purchaseRepo    repository.PurchaseRepository
+	coinLotRepo     repository.CoinLotRepository
+	ledger          ledgerWriter
+	tx              txRunner
+	log             *logrus.Logger
+}
+
+func NewReversalService(
+	userRepo repository.UserRepository,
+	transactionRepo repository.TransactionRepository,
+	inventoryRepo repository.InventoryRepository,
+	purchaseRepo repository.PurchaseRepository,
+	coinLotRepo repository.CoinLotRepository,
+	ledgerRepo repository.LedgerRepository,
+	trManager *manager.Manager,
+	txCfg TxConfig,
+	log *logrus.Logger) *ReversalService {
+	return &ReversalService{
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		inventoryRepo:   inventoryRepo,
+		purchaseRepo:    purchaseRepo,
+		coinLotRepo:     coinLotRepo,
+		ledger:          ledgerWriter{repo: ledgerRepo},
+		tx:              txRunner{trManager: trManager, cfg: txCfg, log: log},
+		log:             log,
+	}
+}
+
+// ReverseTransfer возвращает монеты перевода отправителю. Если у получателя уже нет нужной суммы,
+// операция отклоняется с entity.ErrInsufficientBalance, а с forceNegative его баланс уходит в минус.
+func (s *ReversalService) ReverseTransfer(ctx context.Context, adminID, transactionID int64, forceNegative bool) (entity.Receipt, error) {
+	s.log.Infof("Admin %d is reversing transfer %d (force negative: %t)", adminID, transactionID, forceNegative)
+
+	var reversal entity.Transaction
+
+	err := s.tx.do(ctx, opReverseTransfer, func(ctx context.Context) error {
+		original, err := s.getReversible(ctx, transactionID, entity.TransactionTypeTransfer)
+		if err != nil {
+			return err
+		}
+
+		senderID, recipientID := original.FromUserID, *original.ToUserID
+
+		balances, err := lockBalances(ctx, s.userRepo, senderID, recipientID)
+		if err != nil {
+			s.log.Errorf("ReverseTransfer failed: failed to lock balances of users %d and %d: %v", senderID, recipientID, err)
+			return err
+		}
+		if balances[recipientID] < original.Amount && !forceNegative {
+			s.log.Warnf("ReverseTransfer failed: user %d has %d coins, %d required", recipientID, balances[recipientID], original.Amount)
+			return entity.ErrInsufficientBalance
+		}
+
+		err = s.userRepo.AdjustCoins(ctx, recipientID, -original.Amount)
+		if err != nil {
+			s.log.Errorf("ReverseTransfer failed: failed to decrease balance for user %d: %v", recipientID, err)
+			return err
+		}
+
+		if err = s.coinLotRepo.ConsumeCoinLots(ctx, recipientID, original.Amount, time.Now()); err != nil {
+			s.log.Errorf("ReverseTransfer failed: failed to consume coin lots of user %d: %v", recipientID, err)
+			return err
+		}
+
+		err = s.userRepo.AdjustCoins(ctx, senderID, original.Amount)
+		if err != nil {
+			s.log.Errorf("ReverseTransfer failed: failed to increase balance for user %d: %v", senderID, err)
+			return err
+		}
+
+		recipientBalance, err := s.userRepo.GetUserBalance(ctx, recipientID)
+		if err != nil {
+			s.log.Errorf("ReverseTransfer failed: failed to fetch new balance for user %d: %v", recipientID, err)
+			return err
+		}
+
+		senderBalance, err := s.userRepo.GetUserBalance(ctx, senderID)
+		if err != nil {
+			s.log.Errorf("ReverseTransfer failed: failed to fetch new balance for user %d: %v", senderID, err)
+			return err
+		}
+
+		reversal, err = s.transactionRepo.InsertTransaction(ctx, entity.Transaction{
+			Type:             entity.TransactionTypeReversal,
+			FromUserID:       recipientID,
+			ToUserID:         &senderID,
+			Amount:           original.Amount,
+			SenderBalance:    &recipientBalance,
+			RecipientBalance: &senderBalance,
+			ReversesID:       &original.ID,
+		})
+		if err != nil {
+			s.log.Errorf("ReverseTransfer failed: failed to insert reversal of transaction %d: %v", original.ID, err)
+			return err
+		}
+		reversal.FromUsername = original.ToUsername
+		reversal.ToUsername = original.FromUsername
+
+		if err = s.ledger.reversal(ctx, reversal.ID, recipientID, senderID, original.Amount); err != nil {
+			s.log.Errorf("ReverseTransfer failed: failed to post transaction %d to ledger: %v", reversal.ID, err)
+			return err
+		}
+
+		s.log.Infof("Admin %d reversed transfer %d with transaction %d", adminID, original.ID, reversal.ID)
+		return nil
+	})
+	if err != nil {
+		return entity.Receipt{}, err
+	}
+
+	return newReversalReceipt(reversal), nil
+}
+
+// RefundPurchase возвращает покупателю потраченные монеты и забирает купленный товар.
+// Если товара у пользователя уже нет, возвращается entity.ErrItemNotOwned.
+func (s *ReversalService) RefundPurchase(ctx context.Context, adminID, transactionID int64) (entity.Receipt, error) {
+	s.log.Infof("Admin %d is refunding purchase %d", adminID, transactionID)
+
+	var refund entity.Transaction
+
+	err := s.tx.do(ctx, opRefundPurchase, func(ctx context.Context) error {
+		original, err := s.getReversible(ctx, transactionID, entity.TransactionTypePurchase)
+		if err != nil {
+			return err
+		}
+
+		userID := original.FromUserID
+
+		purchase, err := s.purchaseRepo.GetPurchaseByTransaction(ctx, original.ID)
+		if err != nil {
+			s.log.Errorf("RefundPurchase failed: failed to fetch purchase for transaction %d: %v", original.ID, err)
+			return err
+		}
+
+		if _, err = s.userRepo.GetUserBalanceForUpdate(ctx, userID); err != nil {
+			s.log.Errorf("RefundPurchase failed: failed to lock balance of user %d: %v", userID, err)
+			return err
+		}
+
+		err = s.inventoryRepo.RemoveInventoryItems(ctx, userID, purchase.MerchID, purchase.Quantity)
+		if err != nil {
+			s.log.Warnf("RefundPurchase failed: failed to take %s back from user %d: %v", original.Item, userID, err)
+			return err
+		}
+
+		err = s.userRepo.AdjustCoins(ctx, userID, original.Amount)
+		if err != nil {
+			s.log.Errorf("RefundPurchase failed: failed to increase balance for user %d: %v", userID, err)
+			return err
+		}
+
+		newBalance, err := s.userRepo.GetUserBalance(ctx, userID)
+		if err != nil {
+			s.log.Errorf("RefundPurchase failed: failed to fetch new balance for user %d: %v", userID, err)
+			return err
+		}
+
+		refund, err = s.transactionRepo.InsertTransaction(ctx, entity.Transaction{
+			Type:          entity.TransactionTypeRefund,
+			FromUserID:    userID,
+			MerchID:       original.MerchID,
+			Amount:        original.Amount,
+			SenderBalance: &newBalance,
+			ReversesID:    &original.ID,
+		})
+		if err != nil {
+			s.log.Errorf("RefundPurchase failed: failed to insert refund of transaction %d: %v", original.ID, err)
+			return err
+		}
+		refund.FromUsername = original.FromUsername
+		refund.Item = original.Item
+
+		if err = s.ledger.refund(ctx, refund.ID, userID, original.Amount); err != nil {
+			s.log.Errorf("RefundPurchase failed: failed to post transaction %d to ledger: %v", refund.ID, err)
+			return err
+		}
+
+		s.log.Infof("Admin %d refunded purchase %d with transaction %d", adminID, original.ID, refund.ID)
+		return nil
+	})
+	if err != nil {
+		return entity.Receipt{}, err
+	}
+
+	return newReversalReceipt(refund), nil
+}
+
+// getReversible возвращает операцию, если ее тип — transactionType и она еще не отменена.
+func (s *ReversalService) getReversible(ctx context.Context, transactionID int64, transactionType string) (entity.Transaction, error) {
+	transaction, err := s.transactionRepo.GetTransaction(ctx, transactionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.log.Warnf("Reversal failed: transaction %d not found", transactionID)
+		return entity.Transaction{}, entity.ErrTransactionNotFound
+	}
+	if err != nil {
+		s.log.Errorf("Reversal failed: failed to fetch transaction %d: %v", transactionID, err)
+		return entity.Transaction{}, err
+	}
+
+	if transaction.Type != transactionType {
+		s.log.Warnf("Reversal failed: transaction %d is a %s, not a %s", transactionID, transaction.Type, transactionType)
+		if transactionType == entity.TransactionTypePurchase {
+			return entity.Transaction{}, entity.ErrNotRefundable
+		}
+		return entity.Transaction{}, entity.ErrNotReversible
+	}
+
+	if transaction.ReversedByID != nil {
+		s.log.Warnf("Reversal failed: transaction %d is already reversed by %d", transactionID, *transaction.ReversedByID)
+		return entity.Transaction{}, entity.ErrAlreadyReversed
+	}
+
+	return transaction, nil
+}
+
+// newReversalReceipt строит квитанцию для администратора: в ней видны обе стороны операции, но не их балансы.
+func newReversalReceipt(transaction entity.Transaction) entity.Receipt {
+	return entity.Receipt{
+		ID:        transaction.ID,
+		Type:      transaction.Type,
+		Amount:    transaction.Amount,
+		FromUser:  transaction.FromUsername,
+		ToUser:    transaction.ToUsername,
+		Item:      transaction.Item,
+		Reverses:  transaction.ReversesID,
+		CreatedAt: transaction.CreatedAt,
+	}
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func TestReversalService_ReverseTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockInventoryRepo := mocks.NewMockInventoryRepository(ctrl)
+	mockPurchaseRepo := mocks.NewMockPurchaseRepository(ctrl)
+	mockCoinLotRepo := mocks.NewMockCoinLotRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+
+	service := NewReversalService(mockUserRepo, mockTransactionRepo, mockInventoryRepo, mockPurchaseRepo, mockCoinLotRepo, mockLedgerRepo, mockTrManager, TxConfig{MaxAttempts: 1}, logrus.New())
+
+	transfer := entity.Transaction{
+		ID:           7,
+		Type:         entity.TransactionTypeTransfer,
+		FromUserID:   1,
+		ToUserID:     int64Ptr(2),
+		Amount:       100,
+		FromUsername: "user1",
+		ToUsername:   "user2",
+	}
+
+	expectReversal := func(recipientBalance int64) {
+		mockUserRepo.EXPECT().AdjustCoins(gomock.Any(), int64(2), int64(-100)).Return(nil)
+		mockCoinLotRepo.EXPECT().ConsumeCoinLots(gomock.Any(), int64(2), int64(100), gomock.Any()).Return(nil)
+		mockUserRepo.EXPECT().AdjustCoins(gomock.Any(), int64(1), int64(100)).Return(nil)
+		mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(2)).Return(recipientBalance-100, nil)
+		mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(1000), nil)
+		mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, tr entity.Transaction) (entity.Transaction, error) {
+				assert.Equal(t, entity.TransactionTypeReversal, tr.Type)
+				assert.Equal(t, int64(2), tr.FromUserID)
+				assert.Equal(t, int64(1), *tr.ToUserID)
+				assert.Equal(t, int64(7), *tr.ReversesID)
+				tr.ID = 9
+				tr.CreatedAt = testCreatedAt
+				return tr, nil
+			})
+		mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), int64(2)).Return(int64(12), nil)
+		mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), int64(1)).Return(int64(11), nil)
+		mockLedgerRepo.EXPECT().CreateJournalEntry(gomock.Any(), entity.JournalEntry{
+			Kind:          entity.JournalReversal,
+			TransactionID: int64Ptr(9),
+			Postings: []entity.LedgerPosting{
+				{AccountID: 12, Amount: -100},
+				{AccountID: 11, Amount: 100},
+			},
+		}).Return(int64(4), nil)
+	}
+
+	wantReceipt := entity.Receipt{
+		ID:        9,
+		Type:      entity.TransactionTypeReversal,
+		Amount:    100,
+		FromUser:  "user2",
+		ToUser:    "user1",
+		Reverses:  int64Ptr(7),
+		CreatedAt: testCreatedAt,
+	}
+
+	tests := []struct {
+		name          string
+		forceNegative bool
+		mockBehavior  func()
+		wantReceipt   entity.Receipt
+		wantErr       error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(7)).Return(transfer, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(900), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(300), nil)
+				expectReversal(300)
+				mock.ExpectCommit()
+			},
+			wantReceipt: wantReceipt,
+		},
+		{
+			name: "Insufficient Balance",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(7)).Return(transfer, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(900), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(40), nil)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrInsufficientBalance,
+		},
+		{
+			name:          "Force Negative",
+			forceNegative: true,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(7)).Return(transfer, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(900), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(40), nil)
+				expectReversal(40)
+				mock.ExpectCommit()
+			},
+			wantReceipt: wantReceipt,
+		},
+		{
+			name: "Not Found",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(7)).Return(entity.Transaction{}, sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrTransactionNotFound,
+		},
+		{
+			name: "Not A Transfer",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(7)).
+					Return(entity.Transaction{ID: 7, Type: entity.TransactionTypePurchase}, nil)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrNotReversible,
+		},
+		{
+			name: "Already Reversed",
+			mockBehavior: func() {
+				reversed := transfer
+				reversed.ReversedByID = int64Ptr(9)
+
+				mock.ExpectBegin()
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(7)).Return(reversed, nil)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrAlreadyReversed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			receipt, err := service.ReverseTransfer(context.Background(), 100, 7, tt.forceNegative)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantReceipt, receipt)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReversalService_RefundPurchase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockInventoryRepo := mocks.NewMockInventoryRepository(ctrl)
+	mockPurchaseRepo := mocks.NewMockPurchaseRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+
+	service := NewReversalService(mockUserRepo, mockTransactionRepo, mockInventoryRepo, mockPurchaseRepo, nil, mockLedgerRepo, mockTrManager, TxConfig{MaxAttempts: 1}, logrus.New())
+
+	purchase := entity.Transaction{
+		ID:           8,
+		Type:         entity.TransactionTypePurchase,
+		FromUserID:   1,
+		MerchID:      int64Ptr(10),
+		Amount:       80,
+		FromUsername: "user1",
+		Item:         "t-shirt",
+	}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantReceipt  entity.Receipt
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(8)).Return(purchase, nil)
+				mockPurchaseRepo.EXPECT().GetPurchaseByTransaction(gomock.Any(), int64(8)).
+					Return(entity.Purchase{ID: 3, UserID: 1, MerchID: 10, UnitPrice: 80, Quantity: 1, TransactionID: 8}, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(920), nil)
+				mockInventoryRepo.EXPECT().RemoveInventoryItems(gomock.Any(), int64(1), int64(10), 1).Return(nil)
+				mockUserRepo.EXPECT().AdjustCoins(gomock.Any(), int64(1), int64(80)).Return(nil)
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(1000), nil)
+				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, tr entity.Transaction) (entity.Transaction, error) {
+						assert.Equal(t, entity.TransactionTypeRefund, tr.Type)
+						assert.Nil(t, tr.ToUserID)
+						assert.Equal(t, int64(10), *tr.MerchID)
+						assert.Equal(t, int64(1000), *tr.SenderBalance)
+						tr.ID = 11
+						tr.CreatedAt = testCreatedAt
+						return tr, nil
+					})
+				mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), int64(1)).Return(int64(11), nil)
+				mockLedgerRepo.EXPECT().GetSystemAccountID(gomock.Any(), entity.LedgerAccountShop).Return(int64(1), nil)
+				mockLedgerRepo.EXPECT().CreateJournalEntry(gomock.Any(), entity.JournalEntry{
+					Kind:          entity.JournalRefund,
+					TransactionID: int64Ptr(11),
+					Postings: []entity.LedgerPosting{
+						{AccountID: 1, Amount: -80},
+						{AccountID: 11, Amount: 80},
+					},
+				}).Return(int64(6), nil)
+				mock.ExpectCommit()
+			},
+			wantReceipt: entity.Receipt{
+				ID:        11,
+				Type:      entity.TransactionTypeRefund,
+				Amount:    80,
+				FromUser:  "user1",
+				Item:      "t-shirt",
+				Reverses:  int64Ptr(8),
+				CreatedAt: testCreatedAt,
+			},
+		},
+		{
+			name: "Item Not Owned",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(8)).Return(purchase, nil)
+				mockPurchaseRepo.EXPECT().GetPurchaseByTransaction(gomock.Any(), int64(8)).
+					Return(entity.Purchase{ID: 3, UserID: 1, MerchID: 10, UnitPrice: 80, Quantity: 1, TransactionID: 8}, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(920), nil)
+				mockInventoryRepo.EXPECT().RemoveInventoryItems(gomock.Any(), int64(1), int64(10), 1).Return(entity.ErrItemNotOwned)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrItemNotOwned,
+		},
+		{
+			name: "Not A Purchase",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(8)).
+					Return(entity.Transaction{ID: 8, Type: entity.TransactionTypeTransfer}, nil)
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrNotRefundable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			receipt, err := service.RefundPurchase(context.Background(), 100, 8)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantReceipt, receipt)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+const revocationCacheSweepInterval = time.Minute
+
+type revocationEntry struct {
+	revoked bool
+	until   time.Time
+}
+
+// revocationCache хранит результаты проверки отзыва токенов, чтобы не обращаться к БД на каждый запрос.
+// Отозванные токены кешируются до истечения срока их действия, неотозванные — не дольше ttl,
+// чтобы отзыв, сделанный на другой реплике, вступал в силу без перезапуска.
+type revocationCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	tokenTTL  time.Duration
+	tokens    map[string]revocationEntry
+	users     map[int64]time.Time
+	lastSweep time.Time
+}
+
+func newRevocationCache(ttl, tokenTTL time.Duration) *revocationCache {
+	return &revocationCache{
+		ttl:       ttl,
+		tokenTTL:  tokenTTL,
+		tokens:    make(map[string]revocationEntry),
+		users:     make(map[int64]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *revocationCache) get(claims entity.TokenClaims) (revoked, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if revokedBefore, ok := c.users[claims.UserID]; ok && !claims.IssuedAt.After(revokedBefore) {
+		return true, true
+	}
+
+	entry, ok := c.tokens[claims.TokenID]
+	if !ok || time.Now().After(entry.until) {
+		return false, false
+	}
+
+	return entry.revoked, true
+}
+
+func (c *revocationCache) set(claims entity.TokenClaims, revoked bool) {
+	until := claims.ExpiresAt
+	if !revoked {
+		if deadline := time.Now().Add(c.ttl); deadline.Before(until) {
+			until = deadline
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens[claims.TokenID] = revocationEntry{revoked: revoked, until: until}
+	c.sweep()
+}
+
+func (c *revocationCache) revokeUser(userID int64, revokedBefore time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if current, ok := c.users[userID]; !ok || current.Before(revokedBefore) {
+		c.users[userID] = revokedBefore
+	}
+	c.sweep()
+}
+
+func (c *revocationCache) sweep() {
+	now := time.Now()
+	if now.Sub(c.lastSweep) < revocationCacheSweepInterval {
+		return
+	}
+	c.lastSweep = now
+
+	for id, entry := range c.tokens {
+		if now.After(entry.until) {
+			delete(c.tokens, id)
+		}
+	}
+
+	// Все токены, выпущенные до отметки отзыва, к этому моменту уже истекли.
+	for userID, revokedBefore := range c.users {
+		if now.After(revokedBefore.Add(c.tokenTTL)) {
+			delete(c.users, userID)
+		}
+	}
+}
+
+func (s *AuthService) isTokenRevoked(ctx context.Context, claims entity.TokenClaims) (bool, error) {
+	if revoked, found := s.revocations.get(claims); found {
+		return revoked, nil
+	}
+
+	revoked, err := s.revocationRepo.IsTokenRevoked(ctx, claims.TokenID, claims.UserID, claims.IssuedAt)
+	if err != nil {
+		return false, err
+	}
+
+	s.revocations.set(claims, revoked)
+	return revoked, nil
+}
+
+// Logout отзывает текущий access-токен и, если он передан, всё семейство refresh-токена.
+func (s *AuthService) Logout(ctx context.Context, claims entity.TokenClaims, refreshToken string) error {
+	err := s.trManager.Do(ctx, func(ctx context.Context) error {
+		if err := s.revocationRepo.RevokeToken(ctx, claims.TokenID, claims.UserID, claims.ExpiresAt); err != nil {
+			s.log.Errorf("Logout: failed to revoke access token for user %d: %v", claims.UserID, err)
+			return err
+		}
+
+		if refreshToken == "" {
+			return nil
+		}
+
+		stored, err := s.refreshTokenRepo.GetRefreshTokenForUpdate(ctx, hashToken(refreshToken))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				s.log.Warnf("Logout: refresh token of user %d not found", claims.UserID)
+				return nil
+			}
+			s.log.Errorf("Logout: failed to fetch refresh token for user %d: %v", claims.UserID, err)
+			return err
+		}
+		if stored.UserID != claims.UserID {
+			s.log.Warnf("Logout: user %d presented a refresh token of another user", claims.UserID)
+			return nil
+		}
+
+		if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+			s.log.Errorf("Logout: failed to revoke refresh token family for user %d: %v", claims.UserID, err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.set(claims, true)
+
+	if err := s.revocationRepo.DeleteExpiredRevokedTokens(ctx); err != nil {
+		s.log.Warnf("Logout: failed to clean up expired revoked tokens: %v", err)
+	}
+
+	s.log.Infof("User %d logged out", claims.UserID)
+	return nil
+}
+
+// RevokeAllSessions делает недействительными все выданные пользователю access- и refresh-токены.
+func (s *AuthService) RevokeAllSessions(ctx context.Context, userID int64) error {
+	revokedBefore, err := s.revokeAllSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	s.revocations.revokeUser(userID, revokedBefore)
+
+	s.log.Infof("All sessions of user %d revoked", userID)
+	return nil
+}
+
+// revokeAllSessions отзывает сессии пользователя в БД и возвращает отметку отзыва: недействительны
+// все токены, выпущенные не позже нее. Кеш отзывов вызывающий обновляет сам после коммита внешней
+// транзакции, иначе при ее откате реплика отклоняла бы сессии, которые в БД не отозваны.
+func (s *AuthService) revokeAllSessions(ctx context.Context, userID int64) (time.Time, error) {
+	// Токены выпускаются с меткой iat_us, поэтому отметка отзыва отличает токены, выпущенные в ту же
+	// секунду до и после нее. Postgres хранит время с точностью до микросекунды.
+	revokedBefore := time.Now().Truncate(time.Microsecond)
+
+	err := s.trManager.Do(ctx, func(ctx context.Context) error {
+		if err := s.revocationRepo.RevokeUserTokens(ctx, userID, revokedBefore); err != nil {
+			s.log.Errorf("RevokeAllSessions: failed to revoke access tokens for user %d: %v", userID, err)
+			return err
+		}
+
+		if err := s.refreshTokenRepo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+			s.log.Errorf("RevokeAllSessions: failed to revoke refresh tokens for user %d: %v", userID, err)
+			return err
+		}
+
+		return nil
+	})
+
+	return revokedBefore, err
+}
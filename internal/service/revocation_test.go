@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func TestAuthService_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRefreshRepo := mocks.NewMockRefreshTokenRepository(ctrl)
+	mockRevocationRepo := mocks.NewMockTokenRevocationRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	mockLog := logrus.New()
+	authService := NewAuthService(nil, mockRefreshRepo, mockRevocationRepo, nil, nil, nil, nil, mockTrManager, newTestHasher(t), testAuthConfig, mockLog)
+
+	claims := entity.TokenClaims{UserID: testUserID, TokenID: "jti", IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name         string
+		refreshToken string
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name:         "Access Token Only",
+			refreshToken: "",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRevocationRepo.EXPECT().RevokeToken(gomock.Any(), "jti", testUserID, claims.ExpiresAt).Return(nil)
+				mock.ExpectCommit()
+				mockRevocationRepo.EXPECT().DeleteExpiredRevokedTokens(gomock.Any()).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:         "With Refresh Token",
+			refreshToken: "refresh-token",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRevocationRepo.EXPECT().RevokeToken(gomock.Any(), "jti", testUserID, claims.ExpiresAt).Return(nil)
+				mockRefreshRepo.EXPECT().GetRefreshTokenForUpdate(gomock.Any(), hashToken("refresh-token")).
+					Return(entity.RefreshToken{ID: 1, UserID: testUserID, FamilyID: "family"}, nil)
+				mockRefreshRepo.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), "family").Return(nil)
+				mock.ExpectCommit()
+				mockRevocationRepo.EXPECT().DeleteExpiredRevokedTokens(gomock.Any()).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:         "Refresh Token Of Another User Ignored",
+			refreshToken: "foreign-token",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRevocationRepo.EXPECT().RevokeToken(gomock.Any(), "jti", testUserID, claims.ExpiresAt).Return(nil)
+				mockRefreshRepo.EXPECT().GetRefreshTokenForUpdate(gomock.Any(), hashToken("foreign-token")).
+					Return(entity.RefreshToken{ID: 2, UserID: 42, FamilyID: "foreign"}, nil)
+				mock.ExpectCommit()
+				mockRevocationRepo.EXPECT().DeleteExpiredRevokedTokens(gomock.Any()).Return(errors.New("cleanup error"))
+			},
+			wantErr: nil,
+		},
+		{
+			name:         "Unknown Refresh Token Ignored",
+			refreshToken: "unknown",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRevocationRepo.EXPECT().RevokeToken(gomock.Any(), "jti", testUserID, claims.ExpiresAt).Return(nil)
+				mockRefreshRepo.EXPECT().GetRefreshTokenForUpdate(gomock.Any(), hashToken("unknown")).
+					Return(entity.RefreshToken{}, sql.ErrNoRows)
+				mock.ExpectCommit()
+				mockRevocationRepo.EXPECT().DeleteExpiredRevokedTokens(gomock.Any()).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:         "Revoke Failure",
+			refreshToken: "",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRevocationRepo.EXPECT().RevokeToken(gomock.Any(), "jti", testUserID, claims.ExpiresAt).Return(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := authService.Logout(context.Background(), claims, tt.refreshToken)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuthService_RevokeAllSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRefreshRepo := mocks.NewMockRefreshTokenRepository(ctrl)
+	mockRevocationRepo := mocks.NewMockTokenRevocationRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	mockLog := logrus.New()
+	authService := NewAuthService(nil, mockRefreshRepo, mockRevocationRepo, nil, nil, nil, nil, mockTrManager, newTestHasher(t), testAuthConfig, mockLog)
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRevocationRepo.EXPECT().RevokeUserTokens(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockRefreshRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), testUserID).Return(nil)
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Refresh Tokens Revoke Failure",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockRevocationRepo.EXPECT().RevokeUserTokens(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockRefreshRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), testUserID).Return(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := authService.RevokeAllSessions(context.Background(), testUserID)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("Old Tokens Rejected From Cache", func(t *testing.T) {
+		claims := entity.TokenClaims{
+			UserID:    testUserID,
+			TokenID:   "old",
+			IssuedAt:  time.Now().Add(-time.Minute),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		revoked, err := authService.isTokenRevoked(context.Background(), claims)
+
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+}
+
+func TestAuthService_RevokeAllSessions_SameSecond(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRefreshRepo := mocks.NewMockRefreshTokenRepository(ctrl)
+	mockRevocationRepo := mocks.NewMockTokenRevocationRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	authService := NewAuthService(nil, mockRefreshRepo, mockRevocationRepo, nil, nil, nil, nil, mockTrManager, newTestHasher(t), testAuthConfig, logrus.New())
+
+	oldToken, err := authService.generateAccessToken(testUserID, entity.RoleUser)
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	mockRevocationRepo.EXPECT().RevokeUserTokens(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+	mockRefreshRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), testUserID).Return(nil)
+	mock.ExpectCommit()
+
+	assert.NoError(t, authService.RevokeAllSessions(context.Background(), testUserID))
+
+	_, err = authService.ParseToken(context.Background(), oldToken)
+	assert.ErrorIs(t, err, entity.ErrTokenRevoked, "token issued in the same second before the revocation must be rejected")
+
+	newToken, err := authService.generateAccessToken(testUserID, entity.RoleUser)
+	assert.NoError(t, err)
+	mockRevocationRepo.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any(), testUserID, gomock.Any()).Return(false, nil)
+
+	_, err = authService.ParseToken(context.Background(), newToken)
+	assert.NoError(t, err, "token issued after the revocation must be accepted")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
 	"github.com/sirupsen/logrus"
@@ -15,29 +16,121 @@ import (
 type Authorization interface {
 	GetUser(ctx context.Context, username string) (entity.User, error)
 	CreateUser(ctx context.Context, username, password string) error
-	GenerateToken(ctx context.Context, username, password string) (string, error)
-	ParseToken(accessToken string) (int64, error)
+	Register(ctx context.Context, username, password string) (entity.AuthResponse, error)
+	GenerateToken(ctx context.Context, username, password, clientIP string) (entity.AuthResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string) (entity.AuthResponse, error)
+	ParseToken(ctx context.Context, accessToken string) (entity.TokenClaims, error)
+	Logout(ctx context.Context, claims entity.TokenClaims, refreshToken string) error
+	RevokeAllSessions(ctx context.Context, userID int64) error
+	GetJWKS() entity.JWKS
+	SetUserRole(ctx context.Context, username, role string) error
+	UnlockUser(ctx context.Context, username string) error
+	ChangePassword(ctx context.Context, userID int64, currentPassword, newPassword string) (entity.AuthResponse, error)
+	EnrollTOTP(ctx context.Context, userID int64) (entity.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID int64, code string) (entity.RecoveryCodesResponse, error)
+	DisableTOTP(ctx context.Context, userID int64, password, code string) error
+	CompleteTwoFactorLogin(ctx context.Context, challengeToken, code, clientIP string) (entity.AuthResponse, error)
+}
+
+type APIKey interface {
+	CreateAPIKey(ctx context.Context, userID int64, input entity.CreateAPIKeyRequest) (entity.CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context, userID int64) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, keyID int64) error
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (entity.APIKey, error)
+}
+
+// Idempotency выполняет операцию с Idempotency-Key не более одного раза и хранит ее ответ.
+type Idempotency interface {
+	Execute(ctx context.Context, userID int64, key, requestHash string, operation func(ctx context.Context) entity.IdempotentResponse) (entity.IdempotentResponse, error)
 }
 
 type Transaction interface {
 	GetUserInfo(ctx context.Context, userID int64) (entity.InfoResponse, error)
-	SendCoin(ctx context.Context, fromUserID int64, toUsername string, amount int64) error
+	GetUserInfoSummary(ctx context.Context, userID int64) (entity.InfoSummaryResponse, error)
+	SendCoin(ctx context.Context, fromUserID int64, toUsername string, amount int64) (entity.Receipt, error)
+	GetReceipt(ctx context.Context, userID, transactionID int64) (entity.Receipt, error)
+	GetHistory(ctx context.Context, userID int64, filter entity.HistoryFilter) (entity.HistoryPage, error)
 }
 
 type Inventory interface {
-	BuyItem(ctx context.Context, userID int64, itemName string) error
+	BuyItem(ctx context.Context, userID int64, itemName string) (entity.Receipt, error)
+}
+
+// Reversal отменяет переводы и покупки по запросу администратора.
+type Reversal interface {
+	ReverseTransfer(ctx context.Context, adminID, transactionID int64, forceNegative bool) (entity.Receipt, error)
+	RefundPurchase(ctx context.Context, adminID, transactionID int64) (entity.Receipt, error)
+}
+
+// Issuance начисляет и списывает монеты по решению администратора.
+type Issuance interface {
+	MintCoins(ctx context.Context, adminID int64, input entity.MintRequest) (entity.MintResponse, error)
+	BurnCoins(ctx context.Context, adminID int64, input entity.BurnRequest) (entity.Receipt, error)
+}
+
+// Allowance управляет регулярными начислениями и выплачивает их по расписанию.
+type Allowance interface {
+	CreateAllowance(ctx context.Context, adminID int64, input entity.CreateAllowanceRequest) (entity.Allowance, error)
+	ListAllowances(ctx context.Context) ([]entity.Allowance, error)
+	DisableAllowance(ctx context.Context, adminID, allowanceID int64) error
+	ApplyDueAllowances(ctx context.Context, now time.Time) (int, error)
+}
+
+// Expiration списывает сгоревшие монеты.
+type Expiration interface {
+	ExpireCoins(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Reconciliation сверяет кэш балансов с журналом двойной записи.
+type Reconciliation interface {
+	Reconcile(ctx context.Context, repair bool) (entity.ReconciliationReport, error)
 }
 
 type Service struct {
 	Authorization
+	APIKey
+	Idempotency
 	Transaction
 	Inventory
+	Reversal
+	Issuance
+	Allowance
+	Expiration
+	Reconciliation
 }
 
-func NewService(repos *repository.Repository, trManager *manager.Manager, jwtSecretKey string, log *logrus.Logger) *Service {
+func NewService(
+	repos *repository.Repository,
+	trManager *manager.Manager,
+	hasher PasswordHasher,
+	authCfg AuthConfig,
+	historyCfg HistoryConfig,
+	txCfg TxConfig,
+	expirationCfg ExpirationConfig,
+	idempotencyTTL time.Duration,
+	log *logrus.Logger) *Service {
 	return &Service{
-		Authorization: NewAuthService(repos.UserRepository, trManager, jwtSecretKey, log),
-		Transaction:   NewTransactionService(repos.UserRepository, repos.TransactionRepository, repos.InventoryRepository, trManager, log),
-		Inventory:     NewInventoryService(repos.UserRepository, repos.InventoryRepository, trManager, log),
+		Authorization: NewAuthService(
+			repos.UserRepository,
+			repos.RefreshTokenRepository,
+			repos.TokenRevocationRepository,
+			repos.LoginAttemptRepository,
+			repos.TwoFactorRepository,
+			repos.TransactionRepository,
+			repos.LedgerRepository,
+			trManager,
+			hasher,
+			authCfg,
+			log,
+		),
+		APIKey:         NewAPIKeyService(repos.APIKeyRepository, authCfg.APIKeyTTL, log),
+		Idempotency:    NewIdempotencyService(repos.IdempotencyRepository, trManager, txCfg, idempotencyTTL, log),
+		Transaction:    NewTransactionService(repos.UserRepository, repos.TransactionRepository, repos.InventoryRepository, repos.PurchaseRepository, repos.CoinLotRepository, repos.LedgerRepository, trManager, historyCfg, txCfg, log),
+		Inventory:      NewInventoryService(repos.UserRepository, repos.InventoryRepository, repos.TransactionRepository, repos.PurchaseRepository, repos.CoinLotRepository, repos.LedgerRepository, trManager, txCfg, log),
+		Reversal:       NewReversalService(repos.UserRepository, repos.TransactionRepository, repos.InventoryRepository, repos.PurchaseRepository, repos.CoinLotRepository, repos.LedgerRepository, trManager, txCfg, log),
+		Issuance:       NewIssuanceService(repos.UserRepository, repos.TransactionRepository, repos.CoinAdjustmentRepository, repos.CoinLotRepository, repos.LedgerRepository, trManager, txCfg, log),
+		Allowance:      NewAllowanceService(repos.UserRepository, repos.TransactionRepository, repos.AllowanceRepository, repos.CoinLotRepository, repos.LedgerRepository, trManager, txCfg, expirationCfg, log),
+		Expiration:     NewExpirationService(repos.UserRepository, repos.TransactionRepository, repos.CoinLotRepository, repos.LedgerRepository, trManager, txCfg, log),
+		Reconciliation: NewReconciliationService(repos.UserRepository, repos.LedgerRepository, trManager, log),
 	}
 }
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	signupRuleDomain  = "domain:"
+	signupRulePattern = "pattern:"
+)
+
+// SignupGrantRule задает стартовый баланс для пользователей, чье имя оканчивается на @Domain
+// или соответствует Pattern.
+type SignupGrantRule struct {
+	Domain  string
+	Pattern *regexp.Regexp
+	Grant   int64
+}
+
+func (r SignupGrantRule) matches(username string) bool {
+	if r.Pattern != nil {
+		return r.Pattern.MatchString(username)
+	}
+
+	at := strings.LastIndex(username, "@")
+	return at >= 0 && strings.EqualFold(username[at+1:], r.Domain)
+}
+
+// SignupGrantPolicy определяет, сколько монет выпускается новому пользователю при регистрации.
+// Применяется первое подходящее правило, иначе Default. Нулевой грант означает регистрацию без монет.
+type SignupGrantPolicy struct {
+	Default int64
+	Rules   []SignupGrantRule
+}
+
+func (p SignupGrantPolicy) Grant(username string) int64 {
+	for _, rule := range p.Rules {
+		if rule.matches(username) {
+			return rule.Grant
+		}
+	}
+
+	return p.Default
+}
+
+// ParseSignupGrantRules разбирает правила в формате "domain:example.com=2000,pattern:^bot_=0".
+// Регулярное выражение не может содержать запятую.
+func ParseSignupGrantRules(rules string) ([]SignupGrantRule, error) {
+	var parsed []SignupGrantRule
+
+	for _, entry := range strings.Split(rules, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		eq := strings.LastIndex(entry, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("signup grant rule %q: expected <kind>:<value>=<grant>", entry)
+		}
+
+		grant, err := strconv.ParseInt(strings.TrimSpace(entry[eq+1:]), 10, 64)
+		if err != nil || grant < 0 {
+			return nil, fmt.Errorf("signup grant rule %q: grant must be a non-negative integer", entry)
+		}
+
+		condition := strings.TrimSpace(entry[:eq])
+		rule := SignupGrantRule{Grant: grant}
+
+		switch {
+		case strings.HasPrefix(condition, signupRuleDomain):
+			rule.Domain = strings.TrimPrefix(strings.TrimPrefix(condition, signupRuleDomain), "@")
+			if rule.Domain == "" {
+				return nil, fmt.Errorf("signup grant rule %q: empty domain", entry)
+			}
+		case strings.HasPrefix(condition, signupRulePattern):
+			rule.Pattern, err = regexp.Compile(strings.TrimPrefix(condition, signupRulePattern))
+			if err != nil {
+				return nil, fmt.Errorf("signup grant rule %q: %w", entry, err)
+			}
+		default:
+			return nil, fmt.Errorf("signup grant rule %q: kind must be domain or pattern", entry)
+		}
+
+		parsed = append(parsed, rule)
+	}
+
+	return parsed, nil
+}
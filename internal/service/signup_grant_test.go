@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignupGrantPolicy_Grant(t *testing.T) {
+	rules, err := ParseSignupGrantRules("domain:partner.com=2000, pattern:^bot_=0, domain:@example.com=500")
+	require.NoError(t, err)
+
+	policy := SignupGrantPolicy{Default: 1000, Rules: rules}
+
+	tests := []struct {
+		name     string
+		username string
+		want     int64
+	}{
+		{name: "Default", username: "alice", want: 1000},
+		{name: "Domain", username: "alice@partner.com", want: 2000},
+		{name: "Domain Case Insensitive", username: "alice@Partner.COM", want: 2000},
+		{name: "Subdomain Does Not Match", username: "alice@eu.partner.com", want: 1000},
+		{name: "Pattern", username: "bot_checker", want: 0},
+		{name: "First Rule Wins", username: "bot_x@partner.com", want: 2000},
+		{name: "Domain With At Prefix", username: "bob@example.com", want: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.Grant(tt.username))
+		})
+	}
+}
+
+func TestParseSignupGrantRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantLen int
+		wantErr bool
+	}{
+		{name: "Empty", input: "", wantLen: 0},
+		{name: "Valid", input: "domain:example.com=2000,pattern:^bot_=0", wantLen: 2},
+		{name: "Trailing Comma", input: "domain:example.com=2000,", wantLen: 1},
+		{name: "Missing Grant", input: "domain:example.com", wantErr: true},
+		{name: "Negative Grant", input: "domain:example.com=-5", wantErr: true},
+		{name: "Non Numeric Grant", input: "domain:example.com=lots", wantErr: true},
+		{name: "Empty Domain", input: "domain:=100", wantErr: true},
+		{name: "Invalid Pattern", input: "pattern:([a-z=100", wantErr: true},
+		{name: "Unknown Kind", input: "prefix:bot=0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseSignupGrantRules(tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, rules, tt.wantLen)
+		})
+	}
+}
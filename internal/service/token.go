@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+type tokenClaims struct {
+	jwt.StandardClaims
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role,omitempty"`
+	// Purpose задан только у служебных токенов (например, challenge-токена 2FA); access-токен его не содержит.
+	Purpose string `json:"purpose,omitempty"`
+	// IssuedAtMicro — время выпуска в микросекундах. iat хранит только секунды, а отзыв всех сессий
+	// должен отличать токены, выпущенные в ту же секунду до и после него.
+	IssuedAtMicro int64 `json:"iat_us,omitempty"`
+}
+
+func (s *AuthService) ParseToken(ctx context.Context, accessToken string) (entity.TokenClaims, error) {
+	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, s.cfg.Keyring.verificationKey)
+	if err != nil {
+		s.log.Warnf("ParseToken: failed to parse token: %s", err.Error())
+		return entity.TokenClaims{}, entity.ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*tokenClaims)
+	if !ok {
+		s.log.Warn("ParseToken: token claims are invalid")
+		return entity.TokenClaims{}, entity.ErrInvalidTokenClaimsType
+	}
+	if claims.Id == "" {
+		s.log.Warn("ParseToken: token has no id")
+		return entity.TokenClaims{}, entity.ErrInvalidToken
+	}
+	if claims.Purpose != "" {
+		s.log.Warnf("ParseToken: %s token presented as access token", claims.Purpose)
+		return entity.TokenClaims{}, entity.ErrInvalidToken
+	}
+
+	// Токены, выпущенные до появления ролей, роли не содержат.
+	if claims.Role == "" {
+		claims.Role = entity.RoleUser
+	}
+
+	result := entity.TokenClaims{
+		UserID:    claims.UserID,
+		Role:      claims.Role,
+		TokenID:   claims.Id,
+		IssuedAt:  time.Unix(claims.IssuedAt, 0),
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}
+	// Токены, выпущенные до появления iat_us, сравниваются с отметкой отзыва по началу секунды выпуска.
+	if claims.IssuedAtMicro != 0 {
+		result.IssuedAt = time.UnixMicro(claims.IssuedAtMicro)
+	}
+
+	revoked, err := s.isTokenRevoked(ctx, result)
+	if err != nil {
+		s.log.Errorf("ParseToken: failed to check token revocation: %v", err)
+		return entity.TokenClaims{}, err
+	}
+	if revoked {
+		s.log.Warnf("ParseToken: revoked token presented by user %d", result.UserID)
+		return entity.TokenClaims{}, entity.ErrTokenRevoked
+	}
+
+	return result, nil
+}
+
+// RefreshToken обменивает refresh-токен на новую пару токенов. Каждый refresh-токен одноразовый:
+// повторное предъявление уже использованного токена считается утечкой и отзывает всё семейство.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (entity.AuthResponse, error) {
+	var (
+		tokens entity.AuthResponse
+		reused bool
+	)
+
+	err := s.trManager.Do(ctx, func(ctx context.Context) error {
+		stored, err := s.refreshTokenRepo.GetRefreshTokenForUpdate(ctx, hashToken(refreshToken))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				s.log.Warn("RefreshToken: refresh token not found")
+				return entity.ErrInvalidRefreshToken
+			}
+			s.log.Errorf("RefreshToken: failed to fetch refresh token: %v", err)
+			return err
+		}
+
+		if stored.RevokedAt != nil {
+			s.log.Warnf("RefreshToken: revoked refresh token presented for user %d", stored.UserID)
+			return entity.ErrInvalidRefreshToken
+		}
+
+		if stored.UsedAt != nil {
+			s.log.Warnf("RefreshToken: reuse detected for user %d, revoking token family", stored.UserID)
+			if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+				s.log.Errorf("RefreshToken: failed to revoke token family for user %d: %v", stored.UserID, err)
+				return err
+			}
+			// Отзыв семейства должен быть зафиксирован, поэтому ошибка возвращается уже после коммита.
+			reused = true
+			return nil
+		}
+
+		if time.Now().After(stored.ExpiresAt) {
+			s.log.Warnf("RefreshToken: expired refresh token presented for user %d", stored.UserID)
+			return entity.ErrInvalidRefreshToken
+		}
+
+		if err := s.refreshTokenRepo.MarkRefreshTokenUsed(ctx, stored.ID); err != nil {
+			s.log.Errorf("RefreshToken: failed to mark refresh token as used for user %d: %v", stored.UserID, err)
+			return err
+		}
+
+		// Роль перечитывается при каждой ротации, чтобы ее изменение вступало в силу без повторного входа.
+		user, err := s.userRepo.GetUserByID(ctx, stored.UserID)
+		if err != nil {
+			s.log.Errorf("RefreshToken: failed to fetch user %d: %v", stored.UserID, err)
+			return err
+		}
+
+		tokens, err = s.issueTokens(ctx, user.ID, user.Role, stored.FamilyID)
+		if err != nil {
+			s.log.Errorf("RefreshToken: failed to issue tokens for user %d: %v", stored.UserID, err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entity.AuthResponse{}, err
+	}
+	if reused {
+		return entity.AuthResponse{}, entity.ErrRefreshTokenReused
+	}
+
+	s.log.Info("Refresh token rotated successfully")
+	return tokens, nil
+}
+
+// GetJWKS возвращает открытые ключи, которыми другие сервисы могут проверять access-токены.
+func (s *AuthService) GetJWKS() entity.JWKS {
+	return s.cfg.Keyring.JWKS()
+}
+
+// startSession выдает пару токенов, открывающую новое семейство refresh-токенов.
+func (s *AuthService) startSession(ctx context.Context, userID int64, role string) (entity.AuthResponse, error) {
+	familyID, err := generateRandomToken(16)
+	if err != nil {
+		return entity.AuthResponse{}, err
+	}
+
+	return s.issueTokens(ctx, userID, role, familyID)
+}
+
+func (s *AuthService) issueTokens(ctx context.Context, userID int64, role, familyID string) (entity.AuthResponse, error) {
+	accessToken, err := s.generateAccessToken(userID, role)
+	if err != nil {
+		return entity.AuthResponse{}, err
+	}
+
+	refreshToken, err := generateRandomToken(32)
+	if err != nil {
+		return entity.AuthResponse{}, err
+	}
+
+	err = s.refreshTokenRepo.CreateRefreshToken(ctx, entity.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.cfg.RefreshTokenTTL),
+	})
+	if err != nil {
+		return entity.AuthResponse{}, err
+	}
+
+	return entity.AuthResponse{Token: accessToken, RefreshToken: refreshToken}, nil
+}
+
+func (s *AuthService) generateAccessToken(userID int64, role string) (string, error) {
+	tokenID, err := generateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	return s.cfg.Keyring.sign(&tokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
+			ExpiresAt: now.Add(s.cfg.AccessTokenTTL).Unix(),
+			IssuedAt:  now.Unix(),
+		},
+		UserID:        userID,
+		Role:          role,
+		IssuedAtMicro: now.UnixMicro(),
+	})
+}
+
+func generateRandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
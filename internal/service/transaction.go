@@ -2,6 +2,10 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
 
 	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
 	"github.com/sirupsen/logrus"
@@ -14,7 +18,12 @@ type TransactionService struct {
 	userRepo        repository.UserRepository
 	transactionRepo repository.TransactionRepository
 	inventoryRepo   repository.InventoryRepository
+	purchaseRepo    repository.PurchaseRepository
+	coinLotRepo     repository.CoinLotRepository
+	ledger          ledgerWriter
 	trManager       *manager.Manager
+	tx              txRunner
+	historyCfg      HistoryConfig
 	log             *logrus.Logger
 }
 
@@ -22,13 +31,23 @@ func NewTransactionService(
 	userRepo repository.UserRepository,
 	transactionRepo repository.TransactionRepository,
 	inventoryRepo repository.InventoryRepository,
+	purchaseRepo repository.PurchaseRepository,
+	coinLotRepo repository.CoinLotRepository,
+	ledgerRepo repository.LedgerRepository,
 	trManager *manager.Manager,
+	historyCfg HistoryConfig,
+	txCfg TxConfig,
 	log *logrus.Logger) *TransactionService {
 	return &TransactionService{
 		userRepo:        userRepo,
 		transactionRepo: transactionRepo,
 		inventoryRepo:   inventoryRepo,
+		purchaseRepo:    purchaseRepo,
+		coinLotRepo:     coinLotRepo,
+		ledger:          ledgerWriter{repo: ledgerRepo},
 		trManager:       trManager,
+		tx:              txRunner{trManager: trManager, cfg: txCfg, log: log},
+		historyCfg:      historyCfg,
 		log:             log,
 	}
 }
@@ -47,24 +66,36 @@ func (s *TransactionService) GetUserInfo(ctx context.Context, userID int64) (ent
 			return err
 		}
 
+		info.ExpiringCoins, err = s.coinLotRepo.GetUpcomingExpirations(ctx, userID, time.Now())
+		if err != nil {
+			s.log.Errorf("Failed to get upcoming coin expirations for userID %d: %v", userID, err)
+			return err
+		}
+
 		info.Inventory, err = s.inventoryRepo.GetUserInventory(ctx, userID)
 		if err != nil {
 			s.log.Errorf("Failed to get user inventory for userID %d: %v", userID, err)
 			return err
 		}
 
-		info.CoinHistory.Received, err = s.transactionRepo.GetReceivedTransactions(ctx, userID)
+		info.CoinHistory.Received, err = s.transactionRepo.GetReceivedTransactions(ctx, userID, s.historyCfg.InfoLimit)
 		if err != nil {
 			s.log.Errorf("Failed to get received transactions for userID %d: %v", userID, err)
 			return err
 		}
 
-		info.CoinHistory.Sent, err = s.transactionRepo.GetSentTransactions(ctx, userID)
+		info.CoinHistory.Sent, err = s.transactionRepo.GetSentTransactions(ctx, userID, s.historyCfg.InfoLimit)
 		if err != nil {
 			s.log.Errorf("Failed to get sent transactions for userID %d: %v", userID, err)
 			return err
 		}
 
+		info.CoinHistory.Purchases, err = s.purchaseRepo.GetUserPurchases(ctx, userID, s.historyCfg.InfoLimit)
+		if err != nil {
+			s.log.Errorf("Failed to get purchases for userID %d: %v", userID, err)
+			return err
+		}
+
 		return nil
 	})
 
@@ -72,6 +103,9 @@ func (s *TransactionService) GetUserInfo(ctx context.Context, userID int64) (ent
 		return entity.InfoResponse{}, err
 	}
 
+	if info.ExpiringCoins == nil {
+		info.ExpiringCoins = make([]entity.CoinExpiration, 0)
+	}
 	if info.Inventory == nil {
 		info.Inventory = make([]entity.InventoryItem, 0)
 	}
@@ -81,15 +115,83 @@ func (s *TransactionService) GetUserInfo(ctx context.Context, userID int64) (ent
 	if info.CoinHistory.Sent == nil {
 		info.CoinHistory.Sent = make([]entity.TransactionDetail, 0)
 	}
+	if info.CoinHistory.Purchases == nil {
+		info.CoinHistory.Purchases = make([]entity.PurchaseDetail, 0)
+	}
 
 	s.log.Infof("Successfully fetched user info for userID: %d", userID)
 	return info, nil
 }
 
-func (s *TransactionService) SendCoin(ctx context.Context, fromUserID int64, toUsername string, amount int64) error {
+// GetUserInfoSummary возвращает то же, что GetUserInfo, но история сгруппирована по контрагентам средствами БД.
+func (s *TransactionService) GetUserInfoSummary(ctx context.Context, userID int64) (entity.InfoSummaryResponse, error) {
+	s.log.Infof("Fetching user info summary for userID: %d", userID)
+
+	var info entity.InfoSummaryResponse
+
+	err := s.trManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+
+		info.Coins, err = s.userRepo.GetUserBalance(ctx, userID)
+		if err != nil {
+			s.log.Errorf("Failed to get user balance for userID %d: %v", userID, err)
+			return err
+		}
+
+		info.ExpiringCoins, err = s.coinLotRepo.GetUpcomingExpirations(ctx, userID, time.Now())
+		if err != nil {
+			s.log.Errorf("Failed to get upcoming coin expirations for userID %d: %v", userID, err)
+			return err
+		}
+
+		info.Inventory, err = s.inventoryRepo.GetUserInventory(ctx, userID)
+		if err != nil {
+			s.log.Errorf("Failed to get user inventory for userID %d: %v", userID, err)
+			return err
+		}
+
+		info.CoinHistory.Received, err = s.transactionRepo.GetReceivedTotals(ctx, userID)
+		if err != nil {
+			s.log.Errorf("Failed to get received totals for userID %d: %v", userID, err)
+			return err
+		}
+
+		info.CoinHistory.Sent, err = s.transactionRepo.GetSentTotals(ctx, userID)
+		if err != nil {
+			s.log.Errorf("Failed to get sent totals for userID %d: %v", userID, err)
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return entity.InfoSummaryResponse{}, err
+	}
+
+	if info.ExpiringCoins == nil {
+		info.ExpiringCoins = make([]entity.CoinExpiration, 0)
+	}
+	if info.Inventory == nil {
+		info.Inventory = make([]entity.InventoryItem, 0)
+	}
+	if info.CoinHistory.Received == nil {
+		info.CoinHistory.Received = make([]entity.CounterpartyTotal, 0)
+	}
+	if info.CoinHistory.Sent == nil {
+		info.CoinHistory.Sent = make([]entity.CounterpartyTotal, 0)
+	}
+
+	s.log.Infof("Successfully fetched user info summary for userID: %d", userID)
+	return info, nil
+}
+
+func (s *TransactionService) SendCoin(ctx context.Context, fromUserID int64, toUsername string, amount int64) (entity.Receipt, error) {
 	s.log.Infof("User %d is sending %d coins to %s", fromUserID, amount, toUsername)
 
-	return s.trManager.Do(ctx, func(ctx context.Context) error {
+	var transaction entity.Transaction
+
+	err := s.tx.do(ctx, opSendCoin, func(ctx context.Context) error {
 		toUser, err := s.userRepo.GetUser(ctx, toUsername)
 		if err != nil {
 			s.log.Warnf("SendCoin failed: recipient %s not found", toUsername)
@@ -102,12 +204,12 @@ func (s *TransactionService) SendCoin(ctx context.Context, fromUserID int64, toU
 			return entity.ErrSendThemselves
 		}
 
-		balance, err := s.userRepo.GetUserBalance(ctx, fromUserID)
+		balances, err := lockBalances(ctx, s.userRepo, fromUserID, toUserID)
 		if err != nil {
-			s.log.Errorf("SendCoin failed: failed to fetch balance for user %d: %v", fromUserID, err)
+			s.log.Errorf("SendCoin failed: failed to lock balances of users %d and %d: %v", fromUserID, toUserID, err)
 			return err
 		}
-		if balance < amount {
+		if balances[fromUserID] < amount {
 			s.log.Warnf("SendCoin failed: insufficient balance for user %d", fromUserID)
 			return entity.ErrInsufficientBalance
 		}
@@ -118,19 +220,117 @@ func (s *TransactionService) SendCoin(ctx context.Context, fromUserID int64, toU
 			return err
 		}
 
+		if err = s.coinLotRepo.ConsumeCoinLots(ctx, fromUserID, amount, time.Now()); err != nil {
+			s.log.Errorf("SendCoin failed: failed to consume coin lots of user %d: %v", fromUserID, err)
+			return err
+		}
+
 		err = s.userRepo.UpdateCoins(ctx, toUserID, amount)
 		if err != nil {
 			s.log.Errorf("SendCoin failed: failed to increase balance for user %d: %v", toUserID, err)
 			return err
 		}
 
-		err = s.transactionRepo.InsertTransaction(ctx, fromUserID, toUserID, amount)
+		senderBalance, err := s.userRepo.GetUserBalance(ctx, fromUserID)
+		if err != nil {
+			s.log.Errorf("SendCoin failed: failed to fetch new balance for user %d: %v", fromUserID, err)
+			return err
+		}
+
+		recipientBalance, err := s.userRepo.GetUserBalance(ctx, toUserID)
+		if err != nil {
+			s.log.Errorf("SendCoin failed: failed to fetch new balance for user %d: %v", toUserID, err)
+			return err
+		}
+
+		transaction, err = s.transactionRepo.InsertTransaction(ctx, entity.Transaction{
+			Type:             entity.TransactionTypeTransfer,
+			FromUserID:       fromUserID,
+			ToUserID:         &toUserID,
+			Amount:           amount,
+			SenderBalance:    &senderBalance,
+			RecipientBalance: &recipientBalance,
+		})
 		if err != nil {
 			s.log.Errorf("SendCoin failed: failed to insert transaction record: %v", err)
 			return err
 		}
+		transaction.ToUsername = toUser.Username
+
+		if err = s.ledger.transfer(ctx, transaction.ID, fromUserID, toUserID, amount); err != nil {
+			s.log.Errorf("SendCoin failed: failed to post transaction %d to ledger: %v", transaction.ID, err)
+			return err
+		}
 
-		s.log.Infof("Transaction successful: %d coins from %d to %s", amount, fromUserID, toUsername)
+		s.log.Infof("Transaction %d successful: %d coins from %d to %s", transaction.ID, amount, fromUserID, toUsername)
 		return nil
 	})
+	if err != nil {
+		return entity.Receipt{}, err
+	}
+
+	return newReceipt(transaction, fromUserID), nil
+}
+
+// GetReceipt возвращает квитанцию по операции. Чужие операции не отличаются от несуществующих.
+func (s *TransactionService) GetReceipt(ctx context.Context, userID, transactionID int64) (entity.Receipt, error) {
+	transaction, err := s.transactionRepo.GetTransaction(ctx, transactionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Receipt{}, entity.ErrTransactionNotFound
+	}
+	if err != nil {
+		s.log.Errorf("GetReceipt failed: failed to fetch transaction %d: %v", transactionID, err)
+		return entity.Receipt{}, err
+	}
+
+	isRecipient := transaction.ToUserID != nil && *transaction.ToUserID == userID
+	if transaction.FromUserID != userID && !isRecipient {
+		s.log.Warnf("GetReceipt failed: user %d is not a party to transaction %d", userID, transactionID)
+		return entity.Receipt{}, entity.ErrTransactionNotFound
+	}
+
+	return newReceipt(transaction, userID), nil
+}
+
+// lockBalances блокирует строки пользователей в порядке возрастания ID и возвращает их балансы.
+// Единый порядок захвата исключает взаимную блокировку встречных переводов между одной парой пользователей.
+func lockBalances(ctx context.Context, userRepo repository.UserRepository, userIDs ...int64) (map[int64]int64, error) {
+	ordered := slices.Clone(userIDs)
+	slices.Sort(ordered)
+
+	balances := make(map[int64]int64, len(ordered))
+	for _, userID := range slices.Compact(ordered) {
+		balance, err := userRepo.GetUserBalanceForUpdate(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		balances[userID] = balance
+	}
+
+	return balances, nil
+}
+
+// newReceipt строит квитанцию для участника операции userID.
+func newReceipt(transaction entity.Transaction, userID int64) entity.Receipt {
+	receipt := entity.Receipt{
+		ID:        transaction.ID,
+		Type:      transaction.Type,
+		Amount:    transaction.Amount,
+		Reverses:  transaction.ReversesID,
+		CreatedAt: transaction.CreatedAt,
+	}
+
+	switch {
+	case transaction.Type == entity.TransactionTypePurchase, transaction.Type == entity.TransactionTypeRefund:
+		receipt.Item = transaction.Item
+		receipt.Balance = transaction.SenderBalance
+	case transaction.FromUserID == userID:
+		receipt.ToUser = transaction.ToUsername
+		receipt.Balance = transaction.SenderBalance
+	default:
+		receipt.FromUser = transaction.FromUsername
+		receipt.Balance = transaction.RecipientBalance
+	}
+
+	return receipt
 }
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
@@ -17,6 +19,12 @@ import (
 	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
 )
 
+var testCreatedAt = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
 func TestTransactionService_GetUserInfo(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -24,12 +32,14 @@ func TestTransactionService_GetUserInfo(t *testing.T) {
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
 	mockInventoryRepo := mocks.NewMockInventoryRepository(ctrl)
+	mockPurchaseRepo := mocks.NewMockPurchaseRepository(ctrl)
+	mockCoinLotRepo := mocks.NewMockCoinLotRepository(ctrl)
 	db, mock, _ := sqlmock.New()
 	mockDB := sqlx.NewDb(db, testDriverName)
 	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
 	mockLog := logrus.New()
 
-	service := NewTransactionService(mockUserRepo, mockTransactionRepo, mockInventoryRepo, mockTrManager, mockLog)
+	service := NewTransactionService(mockUserRepo, mockTransactionRepo, mockInventoryRepo, mockPurchaseRepo, mockCoinLotRepo, nil, mockTrManager, HistoryConfig{InfoLimit: 50}, TxConfig{}, mockLog)
 
 	tests := []struct {
 		name         string
@@ -44,15 +54,24 @@ func TestTransactionService_GetUserInfo(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockCoinLotRepo.EXPECT().GetUpcomingExpirations(gomock.Any(), int64(1), gomock.Any()).
+					Return([]entity.CoinExpiration{{Amount: 30, ExpiresAt: testCreatedAt}}, nil)
 				mockInventoryRepo.EXPECT().GetUserInventory(gomock.Any(), int64(1)).Return([]entity.InventoryItem{}, nil)
-				mockTransactionRepo.EXPECT().GetReceivedTransactions(gomock.Any(), int64(1)).Return([]entity.TransactionDetail{}, nil)
-				mockTransactionRepo.EXPECT().GetSentTransactions(gomock.Any(), int64(1)).Return([]entity.TransactionDetail{}, nil)
+				mockTransactionRepo.EXPECT().GetReceivedTransactions(gomock.Any(), int64(1), 50).Return([]entity.TransactionDetail{}, nil)
+				mockTransactionRepo.EXPECT().GetSentTransactions(gomock.Any(), int64(1), 50).Return([]entity.TransactionDetail{}, nil)
+				mockPurchaseRepo.EXPECT().GetUserPurchases(gomock.Any(), int64(1), 50).
+					Return([]entity.PurchaseDetail{{ID: 9, Item: "cup", UnitPrice: 20, Quantity: 1, Amount: 20, CreatedAt: testCreatedAt}}, nil)
 				mock.ExpectCommit()
 			},
 			wantInfo: entity.InfoResponse{
-				Coins:       100,
-				Inventory:   []entity.InventoryItem{},
-				CoinHistory: entity.CoinHistory{Received: []entity.TransactionDetail{}, Sent: []entity.TransactionDetail{}},
+				Coins:         100,
+				ExpiringCoins: []entity.CoinExpiration{{Amount: 30, ExpiresAt: testCreatedAt}},
+				Inventory:     []entity.InventoryItem{},
+				CoinHistory: entity.CoinHistory{
+					Received:  []entity.TransactionDetail{},
+					Sent:      []entity.TransactionDetail{},
+					Purchases: []entity.PurchaseDetail{{ID: 9, Item: "cup", UnitPrice: 20, Quantity: 1, Amount: 20, CreatedAt: testCreatedAt}},
+				},
 			},
 			wantErr: nil,
 		},
@@ -67,12 +86,25 @@ func TestTransactionService_GetUserInfo(t *testing.T) {
 			wantInfo: entity.InfoResponse{},
 			wantErr:  errors.New("db error"),
 		},
+		{
+			name:   "Error fetching expiring coins",
+			userID: 8,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(8)).Return(int64(100), nil)
+				mockCoinLotRepo.EXPECT().GetUpcomingExpirations(gomock.Any(), int64(8), gomock.Any()).Return(nil, errors.New("coin lots error"))
+				mock.ExpectRollback()
+			},
+			wantInfo: entity.InfoResponse{},
+			wantErr:  errors.New("coin lots error"),
+		},
 		{
 			name:   "Error fetching inventory",
 			userID: 3,
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(3)).Return(int64(100), nil)
+				mockCoinLotRepo.EXPECT().GetUpcomingExpirations(gomock.Any(), int64(3), gomock.Any()).Return(nil, nil)
 				mockInventoryRepo.EXPECT().GetUserInventory(gomock.Any(), int64(3)).Return(nil, errors.New("inventory error"))
 				mock.ExpectRollback()
 			},
@@ -85,8 +117,9 @@ func TestTransactionService_GetUserInfo(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(4)).Return(int64(100), nil)
+				mockCoinLotRepo.EXPECT().GetUpcomingExpirations(gomock.Any(), int64(4), gomock.Any()).Return(nil, nil)
 				mockInventoryRepo.EXPECT().GetUserInventory(gomock.Any(), int64(4)).Return([]entity.InventoryItem{}, nil)
-				mockTransactionRepo.EXPECT().GetReceivedTransactions(gomock.Any(), int64(4)).Return(nil, errors.New("received transactions error"))
+				mockTransactionRepo.EXPECT().GetReceivedTransactions(gomock.Any(), int64(4), 50).Return(nil, errors.New("received transactions error"))
 				mock.ExpectRollback()
 			},
 			wantInfo: entity.InfoResponse{},
@@ -98,29 +131,53 @@ func TestTransactionService_GetUserInfo(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(5)).Return(int64(100), nil)
+				mockCoinLotRepo.EXPECT().GetUpcomingExpirations(gomock.Any(), int64(5), gomock.Any()).Return(nil, nil)
 				mockInventoryRepo.EXPECT().GetUserInventory(gomock.Any(), int64(5)).Return([]entity.InventoryItem{}, nil)
-				mockTransactionRepo.EXPECT().GetReceivedTransactions(gomock.Any(), int64(5)).Return([]entity.TransactionDetail{}, nil)
-				mockTransactionRepo.EXPECT().GetSentTransactions(gomock.Any(), int64(5)).Return(nil, errors.New("sent transactions error"))
+				mockTransactionRepo.EXPECT().GetReceivedTransactions(gomock.Any(), int64(5), 50).Return([]entity.TransactionDetail{}, nil)
+				mockTransactionRepo.EXPECT().GetSentTransactions(gomock.Any(), int64(5), 50).Return(nil, errors.New("sent transactions error"))
 				mock.ExpectRollback()
 			},
 			wantInfo: entity.InfoResponse{},
 			wantErr:  errors.New("sent transactions error"),
 		},
+		{
+			name:   "Error fetching purchases",
+			userID: 7,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(7)).Return(int64(100), nil)
+				mockCoinLotRepo.EXPECT().GetUpcomingExpirations(gomock.Any(), int64(7), gomock.Any()).Return(nil, nil)
+				mockInventoryRepo.EXPECT().GetUserInventory(gomock.Any(), int64(7)).Return([]entity.InventoryItem{}, nil)
+				mockTransactionRepo.EXPECT().GetReceivedTransactions(gomock.Any(), int64(7), 50).Return([]entity.TransactionDetail{}, nil)
+				mockTransactionRepo.EXPECT().GetSentTransactions(gomock.Any(), int64(7), 50).Return([]entity.TransactionDetail{}, nil)
+				mockPurchaseRepo.EXPECT().GetUserPurchases(gomock.Any(), int64(7), 50).Return(nil, errors.New("purchases error"))
+				mock.ExpectRollback()
+			},
+			wantInfo: entity.InfoResponse{},
+			wantErr:  errors.New("purchases error"),
+		},
 		{
 			name:   "User has empty inventory and transactions",
 			userID: 6,
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(6)).Return(int64(50), nil)
+				mockCoinLotRepo.EXPECT().GetUpcomingExpirations(gomock.Any(), int64(6), gomock.Any()).Return(nil, nil)
 				mockInventoryRepo.EXPECT().GetUserInventory(gomock.Any(), int64(6)).Return(nil, nil)
-				mockTransactionRepo.EXPECT().GetReceivedTransactions(gomock.Any(), int64(6)).Return(nil, nil)
-				mockTransactionRepo.EXPECT().GetSentTransactions(gomock.Any(), int64(6)).Return(nil, nil)
+				mockTransactionRepo.EXPECT().GetReceivedTransactions(gomock.Any(), int64(6), 50).Return(nil, nil)
+				mockTransactionRepo.EXPECT().GetSentTransactions(gomock.Any(), int64(6), 50).Return(nil, nil)
+				mockPurchaseRepo.EXPECT().GetUserPurchases(gomock.Any(), int64(6), 50).Return(nil, nil)
 				mock.ExpectCommit()
 			},
 			wantInfo: entity.InfoResponse{
-				Coins:       50,
-				Inventory:   []entity.InventoryItem{},
-				CoinHistory: entity.CoinHistory{Received: []entity.TransactionDetail{}, Sent: []entity.TransactionDetail{}},
+				Coins:         50,
+				ExpiringCoins: []entity.CoinExpiration{},
+				Inventory:     []entity.InventoryItem{},
+				CoinHistory: entity.CoinHistory{
+					Received:  []entity.TransactionDetail{},
+					Sent:      []entity.TransactionDetail{},
+					Purchases: []entity.PurchaseDetail{},
+				},
 			},
 			wantErr: nil,
 		},
@@ -137,19 +194,89 @@ func TestTransactionService_GetUserInfo(t *testing.T) {
 	}
 }
 
+func TestTransactionService_GetUserInfoSummary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockInventoryRepo := mocks.NewMockInventoryRepository(ctrl)
+	mockCoinLotRepo := mocks.NewMockCoinLotRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+
+	service := NewTransactionService(mockUserRepo, mockTransactionRepo, mockInventoryRepo, nil, mockCoinLotRepo, nil, mockTrManager, HistoryConfig{}, TxConfig{}, logrus.New())
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantInfo     entity.InfoSummaryResponse
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), testUserID).Return(int64(100), nil)
+				mockCoinLotRepo.EXPECT().GetUpcomingExpirations(gomock.Any(), testUserID, gomock.Any()).Return(nil, nil)
+				mockInventoryRepo.EXPECT().GetUserInventory(gomock.Any(), testUserID).Return(nil, nil)
+				mockTransactionRepo.EXPECT().GetReceivedTotals(gomock.Any(), testUserID).
+					Return([]entity.CounterpartyTotal{{FromUser: "alice", Amount: 150, Count: 3}}, nil)
+				mockTransactionRepo.EXPECT().GetSentTotals(gomock.Any(), testUserID).Return(nil, nil)
+				mock.ExpectCommit()
+			},
+			wantInfo: entity.InfoSummaryResponse{
+				Coins:         100,
+				ExpiringCoins: []entity.CoinExpiration{},
+				Inventory:     []entity.InventoryItem{},
+				CoinHistory: entity.CoinHistorySummary{
+					Received: []entity.CounterpartyTotal{{FromUser: "alice", Amount: 150, Count: 3}},
+					Sent:     []entity.CounterpartyTotal{},
+				},
+			},
+		},
+		{
+			name: "Error fetching totals",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), testUserID).Return(int64(100), nil)
+				mockCoinLotRepo.EXPECT().GetUpcomingExpirations(gomock.Any(), testUserID, gomock.Any()).Return(nil, nil)
+				mockInventoryRepo.EXPECT().GetUserInventory(gomock.Any(), testUserID).Return(nil, nil)
+				mockTransactionRepo.EXPECT().GetReceivedTotals(gomock.Any(), testUserID).Return(nil, errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantInfo: entity.InfoSummaryResponse{},
+			wantErr:  errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+			info, err := service.GetUserInfoSummary(context.Background(), testUserID)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantInfo, info)
+		})
+	}
+}
+
 func TestTransactionService_SendCoin(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockCoinLotRepo := mocks.NewMockCoinLotRepository(ctrl)
+	mockLedgerRepo := mocks.NewMockLedgerRepository(ctrl)
 
 	db, mock, _ := sqlmock.New()
 	mockDB := sqlx.NewDb(db, testDriverName)
 	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
 
 	mockLog := logrus.New()
-	service := NewTransactionService(mockUserRepo, mockTransactionRepo, nil, mockTrManager, mockLog)
+	service := NewTransactionService(mockUserRepo, mockTransactionRepo, nil, nil, mockCoinLotRepo, mockLedgerRepo, mockTrManager, HistoryConfig{}, TxConfig{}, mockLog)
 
 	tests := []struct {
 		name         string
@@ -157,6 +284,7 @@ func TestTransactionService_SendCoin(t *testing.T) {
 		fromUserID   int64
 		toUsername   string
 		amount       int64
+		wantReceipt  entity.Receipt
 		wantErr      error
 	}{
 		{
@@ -167,12 +295,43 @@ func TestTransactionService_SendCoin(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockUserRepo.EXPECT().GetUser(gomock.Any(), "recipient").Return(entity.User{ID: 2, Username: "recipient"}, nil)
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(1000), nil)
 				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(1), int64(-50)).Return(nil)
+				mockCoinLotRepo.EXPECT().ConsumeCoinLots(gomock.Any(), int64(1), int64(50), gomock.Any()).Return(nil)
 				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(2), int64(50)).Return(nil)
-				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), int64(1), int64(2), int64(50)).Return(nil)
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(50), nil)
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(2)).Return(int64(1050), nil)
+				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, tr entity.Transaction) (entity.Transaction, error) {
+						assert.Equal(t, entity.TransactionTypeTransfer, tr.Type)
+						assert.Equal(t, int64(2), *tr.ToUserID)
+						assert.Equal(t, int64(50), *tr.SenderBalance)
+						assert.Equal(t, int64(1050), *tr.RecipientBalance)
+						tr.ID = 7
+						tr.CreatedAt = testCreatedAt
+						return tr, nil
+					})
+				mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), int64(1)).Return(int64(11), nil)
+				mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), int64(2)).Return(int64(12), nil)
+				mockLedgerRepo.EXPECT().CreateJournalEntry(gomock.Any(), entity.JournalEntry{
+					Kind:          entity.JournalTransfer,
+					TransactionID: int64Ptr(7),
+					Postings: []entity.LedgerPosting{
+						{AccountID: 11, Amount: -50},
+						{AccountID: 12, Amount: 50},
+					},
+				}).Return(int64(4), nil)
 				mock.ExpectCommit()
 			},
+			wantReceipt: entity.Receipt{
+				ID:        7,
+				Type:      entity.TransactionTypeTransfer,
+				Amount:    50,
+				ToUser:    "recipient",
+				Balance:   int64Ptr(50),
+				CreatedAt: testCreatedAt,
+			},
 			wantErr: nil,
 		},
 		{
@@ -207,7 +366,8 @@ func TestTransactionService_SendCoin(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockUserRepo.EXPECT().GetUser(gomock.Any(), "recipient").Return(entity.User{ID: 2, Username: "recipient"}, nil)
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(1000), nil)
 				mock.ExpectRollback()
 			},
 			wantErr: entity.ErrInsufficientBalance,
@@ -220,7 +380,7 @@ func TestTransactionService_SendCoin(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockUserRepo.EXPECT().GetUser(gomock.Any(), "recipient").Return(entity.User{ID: 2, Username: "recipient"}, nil)
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(0), errors.New("db error"))
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(0), errors.New("db error"))
 				mock.ExpectRollback()
 			},
 			wantErr: errors.New("db error"),
@@ -233,7 +393,8 @@ func TestTransactionService_SendCoin(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockUserRepo.EXPECT().GetUser(gomock.Any(), "recipient").Return(entity.User{ID: 2, Username: "recipient"}, nil)
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(1000), nil)
 				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(1), int64(-50)).Return(errors.New("db error"))
 				mock.ExpectRollback()
 			},
@@ -247,8 +408,10 @@ func TestTransactionService_SendCoin(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockUserRepo.EXPECT().GetUser(gomock.Any(), "recipient").Return(entity.User{ID: 2, Username: "recipient"}, nil)
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(1000), nil)
 				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(1), int64(-50)).Return(nil)
+				mockCoinLotRepo.EXPECT().ConsumeCoinLots(gomock.Any(), int64(1), int64(50), gomock.Any()).Return(nil)
 				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(2), int64(50)).Return(errors.New("db error"))
 				mock.ExpectRollback()
 			},
@@ -262,22 +425,183 @@ func TestTransactionService_SendCoin(t *testing.T) {
 			mockBehavior: func() {
 				mock.ExpectBegin()
 				mockUserRepo.EXPECT().GetUser(gomock.Any(), "recipient").Return(entity.User{ID: 2, Username: "recipient"}, nil)
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(1000), nil)
 				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(1), int64(-50)).Return(nil)
+				mockCoinLotRepo.EXPECT().ConsumeCoinLots(gomock.Any(), int64(1), int64(50), gomock.Any()).Return(nil)
 				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(2), int64(50)).Return(nil)
-				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), int64(1), int64(2), int64(50)).Return(errors.New("db error"))
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(50), nil)
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(2)).Return(int64(1050), nil)
+				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), gomock.Any()).Return(entity.Transaction{}, errors.New("db error"))
 				mock.ExpectRollback()
 			},
 			wantErr: errors.New("db error"),
 		},
+		{
+			name:       "Error posting to ledger",
+			fromUserID: 1,
+			toUsername: "recipient",
+			amount:     50,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().GetUser(gomock.Any(), "recipient").Return(entity.User{ID: 2, Username: "recipient"}, nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(1)).Return(int64(100), nil)
+				mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(1000), nil)
+				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(1), int64(-50)).Return(nil)
+				mockCoinLotRepo.EXPECT().ConsumeCoinLots(gomock.Any(), int64(1), int64(50), gomock.Any()).Return(nil)
+				mockUserRepo.EXPECT().UpdateCoins(gomock.Any(), int64(2), int64(50)).Return(nil)
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(1)).Return(int64(50), nil)
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), int64(2)).Return(int64(1050), nil)
+				mockTransactionRepo.EXPECT().InsertTransaction(gomock.Any(), gomock.Any()).Return(entity.Transaction{ID: 7}, nil)
+				mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), int64(1)).Return(int64(11), nil)
+				mockLedgerRepo.EXPECT().GetUserAccountID(gomock.Any(), int64(2)).Return(int64(12), nil)
+				mockLedgerRepo.EXPECT().CreateJournalEntry(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+			receipt, err := service.SendCoin(context.Background(), tt.fromUserID, tt.toUsername, tt.amount)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantReceipt, receipt)
+		})
+	}
+}
+
+func TestLockBalances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+
+	gomock.InOrder(
+		mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(2)).Return(int64(1000), nil),
+		mockUserRepo.EXPECT().GetUserBalanceForUpdate(gomock.Any(), int64(5)).Return(int64(300), nil),
+	)
+
+	balances, err := lockBalances(context.Background(), mockUserRepo, 5, 2, 5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int64{2: 1000, 5: 300}, balances)
+}
+
+func TestTransactionService_GetReceipt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	service := NewTransactionService(nil, mockTransactionRepo, nil, nil, nil, nil, nil, HistoryConfig{}, TxConfig{}, logrus.New())
+
+	transfer := entity.Transaction{
+		ID:               7,
+		Type:             entity.TransactionTypeTransfer,
+		FromUserID:       1,
+		ToUserID:         int64Ptr(2),
+		Amount:           50,
+		SenderBalance:    int64Ptr(950),
+		RecipientBalance: int64Ptr(1050),
+		CreatedAt:        testCreatedAt,
+		FromUsername:     "sender",
+		ToUsername:       "recipient",
+	}
+	purchase := entity.Transaction{
+		ID:            8,
+		Type:          entity.TransactionTypePurchase,
+		FromUserID:    1,
+		MerchID:       int64Ptr(3),
+		Amount:        80,
+		SenderBalance: int64Ptr(870),
+		CreatedAt:     testCreatedAt,
+		FromUsername:  "sender",
+		Item:          "t-shirt",
+	}
+
+	tests := []struct {
+		name          string
+		userID        int64
+		transactionID int64
+		mockBehavior  func()
+		wantReceipt   entity.Receipt
+		wantErr       error
+	}{
+		{
+			name:          "Sender",
+			userID:        1,
+			transactionID: 7,
+			mockBehavior: func() {
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(7)).Return(transfer, nil)
+			},
+			wantReceipt: entity.Receipt{
+				ID: 7, Type: entity.TransactionTypeTransfer, Amount: 50,
+				ToUser: "recipient", Balance: int64Ptr(950), CreatedAt: testCreatedAt,
+			},
+		},
+		{
+			name:          "Recipient",
+			userID:        2,
+			transactionID: 7,
+			mockBehavior: func() {
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(7)).Return(transfer, nil)
+			},
+			wantReceipt: entity.Receipt{
+				ID: 7, Type: entity.TransactionTypeTransfer, Amount: 50,
+				FromUser: "sender", Balance: int64Ptr(1050), CreatedAt: testCreatedAt,
+			},
+		},
+		{
+			name:          "Purchase",
+			userID:        1,
+			transactionID: 8,
+			mockBehavior: func() {
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(8)).Return(purchase, nil)
+			},
+			wantReceipt: entity.Receipt{
+				ID: 8, Type: entity.TransactionTypePurchase, Amount: 80,
+				Item: "t-shirt", Balance: int64Ptr(870), CreatedAt: testCreatedAt,
+			},
+		},
+		{
+			name:          "Not A Party",
+			userID:        3,
+			transactionID: 7,
+			mockBehavior: func() {
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(7)).Return(transfer, nil)
+			},
+			wantErr: entity.ErrTransactionNotFound,
+		},
+		{
+			name:          "Not Found",
+			userID:        1,
+			transactionID: 9,
+			mockBehavior: func() {
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(9)).Return(entity.Transaction{}, sql.ErrNoRows)
+			},
+			wantErr: entity.ErrTransactionNotFound,
+		},
+		{
+			name:          "Repository Error",
+			userID:        1,
+			transactionID: 7,
+			mockBehavior: func() {
+				mockTransactionRepo.EXPECT().GetTransaction(gomock.Any(), int64(7)).Return(entity.Transaction{}, errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockBehavior()
-			err := service.SendCoin(context.Background(), tt.fromUserID, tt.toUsername, tt.amount)
+
+			receipt, err := service.GetReceipt(context.Background(), tt.userID, tt.transactionID)
 
 			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantReceipt, receipt)
 		})
 	}
 }
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+)
+
+const (
+	// challengeTokenPurpose помечает JWT промежуточного шага входа, чтобы его нельзя было
+	// предъявить вместо access-токена.
+	challengeTokenPurpose = "2fa"
+
+	recoveryCodeCount = 10
+	recoveryCodeSize  = 10
+)
+
+// TwoFactorConfig задает параметры двухфакторной аутентификации.
+type TwoFactorConfig struct {
+	Issuer       string
+	ChallengeTTL time.Duration
+}
+
+// EnrollTOTP генерирует новый TOTP-секрет. Второй фактор включается только после ConfirmTOTP.
+func (s *AuthService) EnrollTOTP(ctx context.Context, userID int64) (entity.TOTPEnrollment, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("EnrollTOTP: failed to fetch user %d: %v", userID, err)
+		return entity.TOTPEnrollment{}, err
+	}
+	if user.TwoFactorEnabled {
+		return entity.TOTPEnrollment{}, entity.ErrTwoFactorEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return entity.TOTPEnrollment{}, err
+	}
+
+	if err := s.twoFactorRepo.SaveTOTPSecret(ctx, user.ID, secret); err != nil {
+		s.log.Errorf("EnrollTOTP: failed to save totp secret for user %s: %v", user.Username, err)
+		return entity.TOTPEnrollment{}, err
+	}
+
+	s.log.Infof("TOTP enrollment started for user %s", user.Username)
+	return entity.TOTPEnrollment{
+		Secret: secret,
+		URI:    totpURI(s.cfg.TwoFactor.Issuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP включает второй фактор после проверки первого кода из приложения
+// и возвращает коды восстановления. Они показываются один раз и хранятся только в виде хешей.
+func (s *AuthService) ConfirmTOTP(ctx context.Context, userID int64, code string) (entity.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("ConfirmTOTP: failed to fetch user %d: %v", userID, err)
+		return entity.RecoveryCodesResponse{}, err
+	}
+	if user.TwoFactorEnabled {
+		return entity.RecoveryCodesResponse{}, entity.ErrTwoFactorEnabled
+	}
+
+	totp, err := s.twoFactorRepo.GetTOTP(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.RecoveryCodesResponse{}, entity.ErrTwoFactorNotEnrolled
+		}
+		s.log.Errorf("ConfirmTOTP: failed to fetch totp secret for user %s: %v", user.Username, err)
+		return entity.RecoveryCodesResponse{}, err
+	}
+
+	step, ok := validateTOTP(totp.Secret, code, time.Now(), totp.LastUsedStep)
+	if !ok {
+		s.log.Warnf("ConfirmTOTP: invalid code for user %s", user.Username)
+		return entity.RecoveryCodesResponse{}, entity.ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return entity.RecoveryCodesResponse{}, err
+	}
+
+	err = s.trManager.Do(ctx, func(ctx context.Context) error {
+		if err := s.twoFactorRepo.ConfirmTOTP(ctx, user.ID, step); err != nil {
+			s.log.Errorf("ConfirmTOTP: failed to confirm totp for user %s: %v", user.Username, err)
+			return err
+		}
+
+		if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+			s.log.Errorf("ConfirmTOTP: failed to save recovery codes for user %s: %v", user.Username, err)
+			return err
+		}
+
+		if err := s.userRepo.SetTwoFactorEnabled(ctx, user.ID, true); err != nil {
+			s.log.Errorf("ConfirmTOTP: failed to enable two-factor for user %s: %v", user.Username, err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entity.RecoveryCodesResponse{}, err
+	}
+
+	s.log.Infof("Two-factor authentication enabled for user %s", user.Username)
+	return entity.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP отключает второй фактор. Требует пароль и действующий код (TOTP или код восстановления).
+func (s *AuthService) DisableTOTP(ctx context.Context, userID int64, password, code string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		s.log.Errorf("DisableTOTP: failed to fetch user %d: %v", userID, err)
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return entity.ErrTwoFactorNotEnabled
+	}
+
+	if s.cfg.Throttle.enabled() {
+		if err := s.checkLoginLockout(ctx, user.Username, ""); err != nil {
+			return err
+		}
+	}
+
+	ok, err := s.hasher.Verify(password, user.Password)
+	if err != nil {
+		s.log.Errorf("DisableTOTP: failed to verify password for user %s: %v", user.Username, err)
+		return err
+	}
+	if !ok {
+		s.log.Warnf("DisableTOTP: invalid password for user %s", user.Username)
+		s.registerLoginFailure(ctx, user.Username, "")
+		return entity.ErrIncorrectPassword
+	}
+
+	if err := s.verifySecondFactor(ctx, user, code, ""); err != nil {
+		return err
+	}
+
+	err = s.trManager.Do(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.SetTwoFactorEnabled(ctx, user.ID, false); err != nil {
+			s.log.Errorf("DisableTOTP: failed to disable two-factor for user %s: %v", user.Username, err)
+			return err
+		}
+
+		if err := s.twoFactorRepo.DeleteTOTP(ctx, user.ID); err != nil {
+			s.log.Errorf("DisableTOTP: failed to delete totp secret for user %s: %v", user.Username, err)
+			return err
+		}
+
+		return s.twoFactorRepo.ReplaceRecoveryCodes(ctx, user.ID, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.Infof("Two-factor authentication disabled for user %s", user.Username)
+	return nil
+}
+
+// CompleteTwoFactorLogin завершает вход: проверяет challenge-токен, выданный GenerateToken,
+// и код второго фактора, после чего выдает обычную пару токенов.
+func (s *AuthService) CompleteTwoFactorLogin(ctx context.Context, challengeToken, code, clientIP string) (entity.AuthResponse, error) {
+	userID, err := s.parseChallengeToken(challengeToken)
+	if err != nil {
+		return entity.AuthResponse{}, err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.AuthResponse{}, entity.ErrInvalidChallengeToken
+		}
+		s.log.Errorf("CompleteTwoFactorLogin: failed to fetch user %d: %v", userID, err)
+		return entity.AuthResponse{}, err
+	}
+	if !user.TwoFactorEnabled {
+		s.log.Warnf("CompleteTwoFactorLogin: two-factor is disabled for user %s", user.Username)
+		return entity.AuthResponse{}, entity.ErrInvalidChallengeToken
+	}
+
+	if s.cfg.Throttle.enabled() {
+		if err := s.checkLoginLockout(ctx, user.Username, clientIP); err != nil {
+			return entity.AuthResponse{}, err
+		}
+	}
+
+	if err := s.verifySecondFactor(ctx, user, code, clientIP); err != nil {
+		return entity.AuthResponse{}, err
+	}
+
+	s.resetLoginFailures(ctx, user.Username)
+
+	tokens, err := s.startSession(ctx, user.ID, user.Role)
+	if err != nil {
+		s.log.Errorf("CompleteTwoFactorLogin: failed to issue tokens for user %s: %v", user.Username, err)
+		return entity.AuthResponse{}, err
+	}
+
+	s.log.Infof("User %s passed two-factor authentication", user.Username)
+	return tokens, nil
+}
+
+// verifySecondFactor принимает TOTP-код или неиспользованный код восстановления.
+// Неверный код учитывается в счетчике неудачных входов.
+func (s *AuthService) verifySecondFactor(ctx context.Context, user entity.User, code, clientIP string) error {
+	code = strings.TrimSpace(code)
+
+	totp, err := s.twoFactorRepo.GetTOTP(ctx, user.ID)
+	if err != nil {
+		s.log.Errorf("verifySecondFactor: failed to fetch totp secret for user %s: %v", user.Username, err)
+		return err
+	}
+
+	var used bool
+	if step, ok := validateTOTP(totp.Secret, code, time.Now(), totp.LastUsedStep); ok {
+		used, err = s.twoFactorRepo.UseTOTPStep(ctx, user.ID, step)
+	} else {
+		used, err = s.twoFactorRepo.UseRecoveryCode(ctx, user.ID, hashToken(normalizeRecoveryCode(code)))
+		if used {
+			s.log.Warnf("verifySecondFactor: user %s used a recovery code", user.Username)
+		}
+	}
+	if err != nil {
+		s.log.Errorf("verifySecondFactor: failed to consume code for user %s: %v", user.Username, err)
+		return err
+	}
+
+	if !used {
+		s.log.Warnf("verifySecondFactor: invalid code for user %s", user.Username)
+		s.registerLoginFailure(ctx, user.Username, clientIP)
+		return entity.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+func (s *AuthService) generateChallengeToken(userID int64) (string, error) {
+	tokenID, err := generateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	return s.cfg.Keyring.sign(&tokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
+			ExpiresAt: time.Now().Add(s.cfg.TwoFactor.ChallengeTTL).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		UserID:  userID,
+		Purpose: challengeTokenPurpose,
+	})
+}
+
+func (s *AuthService) parseChallengeToken(challengeToken string) (int64, error) {
+	token, err := jwt.ParseWithClaims(challengeToken, &tokenClaims{}, s.cfg.Keyring.verificationKey)
+	if err != nil {
+		s.log.Warnf("parseChallengeToken: failed to parse token: %s", err.Error())
+		return 0, entity.ErrInvalidChallengeToken
+	}
+
+	claims, ok := token.Claims.(*tokenClaims)
+	if !ok || claims.Purpose != challengeTokenPurpose {
+		s.log.Warn("parseChallengeToken: token is not a challenge token")
+		return 0, entity.ErrInvalidChallengeToken
+	}
+
+	return claims.UserID, nil
+}
+
+// generateRecoveryCodes возвращает коды в виде xxxxx-xxxxx и их хеши для хранения.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	buf := make([]byte, recoveryCodeSize)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(totpEncoding.EncodeToString(buf))[:recoveryCodeSize]
+		codes = append(codes, raw[:recoveryCodeSize/2]+"-"+raw[recoveryCodeSize/2:])
+		hashes = append(hashes, hashToken(raw))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	mocks "github.com/senyabanana/shop-service/internal/repository/mocks"
+)
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	key, err := totpEncoding.DecodeString(secret)
+	require.NoError(t, err)
+
+	return totpCode(key, totpStep(time.Now()))
+}
+
+func TestAuthService_GenerateToken_TwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockRefreshRepo := mocks.NewMockRefreshTokenRepository(ctrl)
+	mockTwoFactorRepo := mocks.NewMockTwoFactorRepository(ctrl)
+	hasher := newTestHasher(t)
+	authService := NewAuthService(mockUserRepo, mockRefreshRepo, nil, nil, mockTwoFactorRepo, nil, nil, nil, hasher, testAuthConfig, logrus.New())
+
+	passwordHash, err := hasher.Hash(testPassword)
+	require.NoError(t, err)
+	user := entity.User{ID: testUserID, Username: testUsername, Password: passwordHash, Role: entity.RoleUser, TwoFactorEnabled: true}
+
+	mockUserRepo.EXPECT().GetUser(gomock.Any(), testUsername).Return(user, nil)
+
+	_, err = authService.GenerateToken(context.Background(), testUsername, testPassword, "127.0.0.1")
+
+	var challengeErr *entity.TwoFactorRequiredError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.NotEmpty(t, challengeErr.ChallengeToken)
+
+	_, err = authService.ParseToken(context.Background(), challengeErr.ChallengeToken)
+	assert.ErrorIs(t, err, entity.ErrInvalidToken, "challenge token must not be accepted as access token")
+
+	secret, err := generateTOTPSecret()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		challengeToken string
+		code           string
+		mockBehavior   func()
+		wantErr        error
+	}{
+		{
+			name:           "Valid TOTP Code",
+			challengeToken: challengeErr.ChallengeToken,
+			code:           currentTOTPCode(t, secret),
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+				mockTwoFactorRepo.EXPECT().GetTOTP(gomock.Any(), testUserID).Return(entity.TOTP{UserID: testUserID, Secret: secret}, nil)
+				mockTwoFactorRepo.EXPECT().UseTOTPStep(gomock.Any(), testUserID, gomock.Any()).Return(true, nil)
+				mockRefreshRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:           "Replayed TOTP Code",
+			challengeToken: challengeErr.ChallengeToken,
+			code:           currentTOTPCode(t, secret),
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+				mockTwoFactorRepo.EXPECT().GetTOTP(gomock.Any(), testUserID).Return(entity.TOTP{UserID: testUserID, Secret: secret}, nil)
+				mockTwoFactorRepo.EXPECT().UseTOTPStep(gomock.Any(), testUserID, gomock.Any()).Return(false, nil)
+			},
+			wantErr: entity.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:           "Recovery Code",
+			challengeToken: challengeErr.ChallengeToken,
+			code:           "ABCDE-FGHIJ",
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+				mockTwoFactorRepo.EXPECT().GetTOTP(gomock.Any(), testUserID).Return(entity.TOTP{UserID: testUserID, Secret: secret}, nil)
+				mockTwoFactorRepo.EXPECT().UseRecoveryCode(gomock.Any(), testUserID, hashToken("abcdefghij")).Return(true, nil)
+				mockRefreshRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:           "Invalid Code",
+			challengeToken: challengeErr.ChallengeToken,
+			code:           "not-a-code",
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+				mockTwoFactorRepo.EXPECT().GetTOTP(gomock.Any(), testUserID).Return(entity.TOTP{UserID: testUserID, Secret: secret}, nil)
+				mockTwoFactorRepo.EXPECT().UseRecoveryCode(gomock.Any(), testUserID, gomock.Any()).Return(false, nil)
+			},
+			wantErr: entity.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:           "Malformed Challenge Token",
+			challengeToken: "garbage",
+			code:           "123456",
+			mockBehavior:   func() {},
+			wantErr:        entity.ErrInvalidChallengeToken,
+		},
+		{
+			name:           "Repository Error",
+			challengeToken: challengeErr.ChallengeToken,
+			code:           "123456",
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+				mockTwoFactorRepo.EXPECT().GetTOTP(gomock.Any(), testUserID).Return(entity.TOTP{}, errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			tokens, err := authService.CompleteTwoFactorLogin(context.Background(), tt.challengeToken, tt.code, "127.0.0.1")
+
+			if tt.wantErr != nil {
+				assert.ErrorContains(t, err, tt.wantErr.Error())
+				assert.Empty(t, tokens)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, tokens.Token)
+			assert.NotEmpty(t, tokens.RefreshToken)
+		})
+	}
+
+	t.Run("Access Token Is Not A Challenge", func(t *testing.T) {
+		accessToken, err := authService.generateAccessToken(testUserID, entity.RoleUser)
+		require.NoError(t, err)
+
+		_, err = authService.CompleteTwoFactorLogin(context.Background(), accessToken, "123456", "127.0.0.1")
+		assert.ErrorIs(t, err, entity.ErrInvalidChallengeToken)
+	})
+}
+
+func TestAuthService_ConfirmTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTwoFactorRepo := mocks.NewMockTwoFactorRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	authService := NewAuthService(mockUserRepo, nil, nil, nil, mockTwoFactorRepo, nil, nil, mockTrManager, newTestHasher(t), testAuthConfig, logrus.New())
+
+	secret, err := generateTOTPSecret()
+	require.NoError(t, err)
+	user := entity.User{ID: testUserID, Username: testUsername}
+
+	tests := []struct {
+		name         string
+		code         string
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			code: currentTOTPCode(t, secret),
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+				mockTwoFactorRepo.EXPECT().GetTOTP(gomock.Any(), testUserID).Return(entity.TOTP{UserID: testUserID, Secret: secret}, nil)
+				mock.ExpectBegin()
+				mockTwoFactorRepo.EXPECT().ConfirmTOTP(gomock.Any(), testUserID, gomock.Any()).Return(nil)
+				mockTwoFactorRepo.EXPECT().ReplaceRecoveryCodes(gomock.Any(), testUserID, gomock.Len(recoveryCodeCount)).Return(nil)
+				mockUserRepo.EXPECT().SetTwoFactorEnabled(gomock.Any(), testUserID, true).Return(nil)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Invalid Code",
+			code: "000000",
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+				mockTwoFactorRepo.EXPECT().GetTOTP(gomock.Any(), testUserID).Return(entity.TOTP{UserID: testUserID, Secret: secret}, nil)
+			},
+			wantErr: entity.ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Not Enrolled",
+			code: "000000",
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+				mockTwoFactorRepo.EXPECT().GetTOTP(gomock.Any(), testUserID).Return(entity.TOTP{}, sql.ErrNoRows)
+			},
+			wantErr: entity.ErrTwoFactorNotEnrolled,
+		},
+		{
+			name: "Already Enabled",
+			code: "000000",
+			mockBehavior: func() {
+				enabled := user
+				enabled.TwoFactorEnabled = true
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(enabled, nil)
+			},
+			wantErr: entity.ErrTwoFactorEnabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			resp, err := authService.ConfirmTOTP(context.Background(), testUserID, tt.code)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, resp.RecoveryCodes, recoveryCodeCount)
+			assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, resp.RecoveryCodes[0])
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuthService_DisableTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTwoFactorRepo := mocks.NewMockTwoFactorRepository(ctrl)
+	db, mock, _ := sqlmock.New()
+	mockDB := sqlx.NewDb(db, testDriverName)
+	mockTrManager := manager.Must(trmsqlx.NewDefaultFactory(mockDB))
+	hasher := newTestHasher(t)
+	authService := NewAuthService(mockUserRepo, nil, nil, nil, mockTwoFactorRepo, nil, nil, mockTrManager, hasher, testAuthConfig, logrus.New())
+
+	passwordHash, err := hasher.Hash(testPassword)
+	require.NoError(t, err)
+	user := entity.User{ID: testUserID, Username: testUsername, Password: passwordHash, TwoFactorEnabled: true}
+
+	secret, err := generateTOTPSecret()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		password     string
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name:     "Success",
+			password: testPassword,
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+				mockTwoFactorRepo.EXPECT().GetTOTP(gomock.Any(), testUserID).Return(entity.TOTP{UserID: testUserID, Secret: secret}, nil)
+				mockTwoFactorRepo.EXPECT().UseTOTPStep(gomock.Any(), testUserID, gomock.Any()).Return(true, nil)
+				mock.ExpectBegin()
+				mockUserRepo.EXPECT().SetTwoFactorEnabled(gomock.Any(), testUserID, false).Return(nil)
+				mockTwoFactorRepo.EXPECT().DeleteTOTP(gomock.Any(), testUserID).Return(nil)
+				mockTwoFactorRepo.EXPECT().ReplaceRecoveryCodes(gomock.Any(), testUserID, nil).Return(nil)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:     "Wrong Password",
+			password: "wrong-password",
+			mockBehavior: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(user, nil)
+			},
+			wantErr: entity.ErrIncorrectPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := authService.DisableTOTP(context.Background(), testUserID, tt.password, currentTOTPCode(t, secret))
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
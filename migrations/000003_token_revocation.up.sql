@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS revoked_tokens
+(
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+CREATE TABLE IF NOT EXISTS user_token_revocations
+(
+    user_id BIGINT PRIMARY KEY REFERENCES users(id),
+    revoked_before TIMESTAMP NOT NULL
+);
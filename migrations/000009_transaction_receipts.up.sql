@@ -0,0 +1,12 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS type VARCHAR(16) NOT NULL DEFAULT 'transfer',
+    ADD COLUMN IF NOT EXISTS merch_id BIGINT REFERENCES merch_items(id),
+    ADD COLUMN IF NOT EXISTS sender_balance BIGINT,
+    ADD COLUMN IF NOT EXISTS recipient_balance BIGINT,
+    ALTER COLUMN to_user DROP NOT NULL;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_type_check CHECK (
+        (type = 'transfer' AND to_user IS NOT NULL AND merch_id IS NULL) OR
+        (type = 'purchase' AND to_user IS NULL AND merch_id IS NOT NULL)
+    );
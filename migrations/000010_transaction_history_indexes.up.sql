@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_transactions_from_user_created ON transactions(from_user, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_to_user_created ON transactions(to_user, created_at DESC, id DESC);
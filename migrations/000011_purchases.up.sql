@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS purchases
+(
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    merch_id BIGINT NOT NULL REFERENCES merch_items(id),
+    unit_price BIGINT NOT NULL CHECK (unit_price > 0),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    transaction_id BIGINT NOT NULL UNIQUE REFERENCES transactions(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchases_user_created ON purchases(user_id, created_at DESC, id DESC);
+
+INSERT INTO purchases (user_id, merch_id, unit_price, quantity, transaction_id, created_at)
+SELECT from_user, merch_id, amount, 1, id, COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM transactions
+WHERE type = 'purchase';
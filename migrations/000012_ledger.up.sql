@@ -0,0 +1,90 @@
+CREATE TABLE IF NOT EXISTS ledger_accounts
+(
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('user', 'shop', 'issuance')),
+    user_id BIGINT UNIQUE REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((type = 'user') = (user_id IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_accounts_system ON ledger_accounts(type) WHERE type <> 'user';
+
+CREATE TABLE IF NOT EXISTS journal_entries
+(
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL,
+    transaction_id BIGINT REFERENCES transactions(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS ledger_postings
+(
+    id BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT NOT NULL REFERENCES journal_entries(id),
+    account_id BIGINT NOT NULL REFERENCES ledger_accounts(id),
+    amount BIGINT NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_account ON ledger_postings(account_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_entry ON ledger_postings(entry_id);
+
+-- Сумма проводок каждой записи журнала должна быть нулевой. Проверка отложена до коммита,
+-- чтобы проводки одной записи можно было вставлять по очереди.
+CREATE OR REPLACE FUNCTION check_journal_entry_balanced() RETURNS trigger AS $$
+BEGIN
+    IF (SELECT SUM(amount) FROM ledger_postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced
+    AFTER INSERT ON ledger_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+INSERT INTO ledger_accounts (type) VALUES ('shop'), ('issuance');
+
+INSERT INTO ledger_accounts (type, user_id)
+SELECT 'user', id FROM users;
+
+-- Счет каждого пользователя открывается балансом, восстановленным по истории, а не копией users.coins:
+-- стартовые 1000 монет, плюс полученные и минус отправленные переводы, минус покупки. Покупки, сделанные
+-- до появления истории покупок, восстанавливаются по инвентарю и текущей цене. Если users.coins с историей
+-- не сходится, расхождение остается в кэше и обнаруживается сверкой (reconcile), а не принимается за истину.
+WITH untracked_purchases AS (
+    SELECT i.user_id, SUM(GREATEST(i.quantity - COALESCE(p.quantity, 0), 0) * m.price) AS amount
+    FROM (SELECT user_id, merch_id, SUM(quantity) AS quantity FROM inventory GROUP BY user_id, merch_id) AS i
+    JOIN merch_items AS m ON m.id = i.merch_id
+    LEFT JOIN (SELECT user_id, merch_id, SUM(quantity) AS quantity FROM purchases GROUP BY user_id, merch_id) AS p
+        ON p.user_id = i.user_id AND p.merch_id = i.merch_id
+    GROUP BY i.user_id
+), history AS (
+    SELECT u.id,
+        1000
+        + COALESCE((SELECT SUM(amount) FROM transactions WHERE type = 'transfer' AND to_user = u.id), 0)
+        - COALESCE((SELECT SUM(amount) FROM transactions WHERE type = 'transfer' AND from_user = u.id), 0)
+        - COALESCE((SELECT SUM(unit_price * quantity) FROM purchases WHERE user_id = u.id), 0)
+        - COALESCE((SELECT amount FROM untracked_purchases WHERE user_id = u.id), 0) AS coins
+    FROM users AS u
+), balances AS (
+    SELECT id, coins FROM history WHERE coins <> 0
+), opening AS (
+    INSERT INTO journal_entries (kind)
+    SELECT 'opening_balance' FROM balances
+    RETURNING id
+), numbered_entries AS (
+    SELECT id, row_number() OVER (ORDER BY id) AS n FROM opening
+), numbered_users AS (
+    SELECT id, coins, row_number() OVER (ORDER BY id) AS n FROM balances
+)
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT e.id, a.id, u.coins
+FROM numbered_entries AS e
+JOIN numbered_users AS u ON u.n = e.n
+JOIN ledger_accounts AS a ON a.user_id = u.id
+UNION ALL
+SELECT e.id, (SELECT id FROM ledger_accounts WHERE type = 'issuance'), -u.coins
+FROM numbered_entries AS e
+JOIN numbered_users AS u ON u.n = e.n;
@@ -0,0 +1,14 @@
+-- Сторно перевода (reversal) и возврат покупки (refund) — отдельные операции, ссылающиеся на исходную.
+-- Уникальность reverses_id не дает отменить одну операцию дважды.
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS reverses_id BIGINT UNIQUE REFERENCES transactions(id);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_type_check CHECK (
+        (type = 'transfer' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NULL) OR
+        (type = 'purchase' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NULL) OR
+        (type = 'reversal' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NOT NULL) OR
+        (type = 'refund' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NOT NULL)
+    );
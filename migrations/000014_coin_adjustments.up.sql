@@ -0,0 +1,23 @@
+-- Начисление (mint) и списание (burn) монет администратором. Пользователь хранится в from_user,
+-- как у покупки; кто и зачем выполнил операцию — в coin_adjustments.
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_type_check CHECK (
+        (type = 'transfer' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NULL) OR
+        (type = 'purchase' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NULL) OR
+        (type = 'reversal' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NOT NULL) OR
+        (type = 'refund' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NOT NULL) OR
+        (type IN ('mint', 'burn') AND to_user IS NULL AND merch_id IS NULL AND reverses_id IS NULL)
+    );
+
+CREATE TABLE IF NOT EXISTS coin_adjustments
+(
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL UNIQUE REFERENCES transactions(id),
+    admin_id BIGINT NOT NULL REFERENCES users(id),
+    reason TEXT NOT NULL CHECK (reason <> ''),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_adjustments_admin ON coin_adjustments(admin_id, created_at DESC);
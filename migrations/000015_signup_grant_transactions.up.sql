@@ -0,0 +1,29 @@
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_type_check CHECK (
+        (type = 'transfer' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NULL) OR
+        (type = 'purchase' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NULL) OR
+        (type = 'reversal' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NOT NULL) OR
+        (type = 'refund' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NOT NULL) OR
+        (type IN ('mint', 'burn', 'signup_grant') AND to_user IS NULL AND merch_id IS NULL AND reverses_id IS NULL)
+    );
+
+-- Стартовые монеты, выпущенные до этой миграции, записаны только в журнал.
+-- Для каждой такой записи создается операция, чтобы грант появился в истории пользователя.
+WITH grants AS (
+    SELECT e.id AS entry_id, a.user_id, p.amount, e.created_at
+    FROM journal_entries AS e
+    JOIN ledger_postings AS p ON p.entry_id = e.id
+    JOIN ledger_accounts AS a ON p.account_id = a.id
+    WHERE e.kind = 'signup_grant' AND e.transaction_id IS NULL AND a.type = 'user'
+), inserted AS (
+    INSERT INTO transactions (type, from_user, amount, sender_balance, created_at)
+    SELECT 'signup_grant', user_id, amount, amount, created_at FROM grants
+    RETURNING id, from_user
+)
+UPDATE journal_entries AS e
+SET transaction_id = i.id
+FROM inserted AS i
+JOIN grants AS g ON g.user_id = i.from_user
+WHERE e.id = g.entry_id;
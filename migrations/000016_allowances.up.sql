@@ -0,0 +1,52 @@
+-- Регулярные начисления (allowance), которые настраивают администраторы. Каждая выплата — операция
+-- типа allowance у получателя; факт выплаты за период фиксируется строкой allowance_runs.
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_type_check CHECK (
+        (type = 'transfer' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NULL) OR
+        (type = 'purchase' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NULL) OR
+        (type = 'reversal' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NOT NULL) OR
+        (type = 'refund' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NOT NULL) OR
+        (type IN ('mint', 'burn', 'signup_grant', 'allowance') AND to_user IS NULL AND merch_id IS NULL AND reverses_id IS NULL)
+    );
+
+CREATE TABLE IF NOT EXISTS allowances
+(
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL CHECK (name <> ''),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    cadence VARCHAR(16) NOT NULL CHECK (cadence IN ('daily', 'weekly', 'monthly')),
+    all_users BOOLEAN NOT NULL,
+    created_by BIGINT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    disabled_at TIMESTAMP
+);
+
+-- Получатели начислений с all_users = false.
+CREATE TABLE IF NOT EXISTS allowance_recipients
+(
+    allowance_id BIGINT NOT NULL REFERENCES allowances(id),
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    PRIMARY KEY (allowance_id, user_id)
+);
+
+-- Выплата за период. Уникальность (allowance_id, period_start) гарантирует, что при нескольких
+-- репликах период выплачивается ровно один раз: строка вставляется в одной транзакции с начислениями,
+-- и реплика, вставляющая ее параллельно, ждет коммита и получает конфликт.
+CREATE TABLE IF NOT EXISTS allowance_runs
+(
+    id BIGSERIAL PRIMARY KEY,
+    allowance_id BIGINT NOT NULL REFERENCES allowances(id),
+    period_start TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (allowance_id, period_start)
+);
+
+CREATE TABLE IF NOT EXISTS allowance_payouts
+(
+    transaction_id BIGINT PRIMARY KEY REFERENCES transactions(id),
+    run_id BIGINT NOT NULL REFERENCES allowance_runs(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_allowance_payouts_run ON allowance_payouts(run_id);
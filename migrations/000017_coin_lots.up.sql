@@ -0,0 +1,26 @@
+-- Партии сгорающих монет. remaining уменьшается при тратах (сначала самые ранние партии),
+-- а по истечении expires_at остаток партии списывается операцией expiration.
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_type_check CHECK (
+        (type = 'transfer' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NULL) OR
+        (type = 'purchase' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NULL) OR
+        (type = 'reversal' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NOT NULL) OR
+        (type = 'refund' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NOT NULL) OR
+        (type IN ('mint', 'burn', 'signup_grant', 'allowance', 'expiration') AND to_user IS NULL AND merch_id IS NULL AND reverses_id IS NULL)
+    );
+
+CREATE TABLE IF NOT EXISTS coin_lots
+(
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    transaction_id BIGINT NOT NULL UNIQUE REFERENCES transactions(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    remaining BIGINT NOT NULL CHECK (remaining >= 0 AND remaining <= amount),
+    granted_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL CHECK (expires_at > granted_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_lots_open ON coin_lots(user_id, granted_at, id) WHERE remaining > 0;
+CREATE INDEX IF NOT EXISTS idx_coin_lots_expiry ON coin_lots(expires_at) WHERE remaining > 0;
@@ -0,0 +1,20 @@
+-- Выплата периода теперь идет пачками по отдельным транзакциям: allowance_runs.completed_at отмечает,
+-- что получили все, а allowance_payouts.user_id позволяет продолжить прерванную выплату с тех,
+-- кому она еще не досталась, и не дает выплатить пользователю дважды за один период.
+ALTER TABLE allowance_runs ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+
+UPDATE allowance_runs SET completed_at = created_at WHERE completed_at IS NULL;
+
+ALTER TABLE allowance_payouts ADD COLUMN IF NOT EXISTS user_id BIGINT REFERENCES users(id);
+
+UPDATE allowance_payouts AS p
+SET user_id = t.from_user
+FROM transactions AS t
+WHERE t.id = p.transaction_id;
+
+ALTER TABLE allowance_payouts ALTER COLUMN user_id SET NOT NULL;
+
+ALTER TABLE allowance_payouts
+    ADD CONSTRAINT allowance_payouts_run_user_key UNIQUE (run_id, user_id);
+
+DROP INDEX IF EXISTS idx_allowance_payouts_run;
@@ -0,0 +1,10 @@
+DELETE FROM transactions WHERE type = 'purchase';
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+
+ALTER TABLE transactions
+    ALTER COLUMN to_user SET NOT NULL,
+    DROP COLUMN IF EXISTS recipient_balance,
+    DROP COLUMN IF EXISTS sender_balance,
+    DROP COLUMN IF EXISTS merch_id,
+    DROP COLUMN IF EXISTS type;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_transactions_to_user_created;
+DROP INDEX IF EXISTS idx_transactions_from_user_created;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ledger_postings;
+DROP FUNCTION IF EXISTS check_journal_entry_balanced();
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS ledger_accounts;
@@ -0,0 +1,21 @@
+DELETE FROM ledger_postings
+WHERE entry_id IN (
+    SELECT e.id FROM journal_entries AS e
+    JOIN transactions AS t ON e.transaction_id = t.id
+    WHERE t.reverses_id IS NOT NULL
+);
+
+DELETE FROM journal_entries
+WHERE transaction_id IN (SELECT id FROM transactions WHERE reverses_id IS NOT NULL);
+
+DELETE FROM transactions WHERE reverses_id IS NOT NULL;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_type_check CHECK (
+        (type = 'transfer' AND to_user IS NOT NULL AND merch_id IS NULL) OR
+        (type = 'purchase' AND to_user IS NULL AND merch_id IS NOT NULL)
+    );
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS reverses_id;
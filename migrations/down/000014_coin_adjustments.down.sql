@@ -0,0 +1,23 @@
+DROP TABLE IF EXISTS coin_adjustments;
+
+DELETE FROM ledger_postings
+WHERE entry_id IN (
+    SELECT e.id FROM journal_entries AS e
+    JOIN transactions AS t ON e.transaction_id = t.id
+    WHERE t.type IN ('mint', 'burn')
+);
+
+DELETE FROM journal_entries
+WHERE transaction_id IN (SELECT id FROM transactions WHERE type IN ('mint', 'burn'));
+
+DELETE FROM transactions WHERE type IN ('mint', 'burn');
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_type_check CHECK (
+        (type = 'transfer' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NULL) OR
+        (type = 'purchase' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NULL) OR
+        (type = 'reversal' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NOT NULL) OR
+        (type = 'refund' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NOT NULL)
+    );
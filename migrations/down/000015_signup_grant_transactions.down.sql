@@ -0,0 +1,16 @@
+UPDATE journal_entries
+SET transaction_id = NULL
+WHERE transaction_id IN (SELECT id FROM transactions WHERE type = 'signup_grant');
+
+DELETE FROM transactions WHERE type = 'signup_grant';
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_type_check CHECK (
+        (type = 'transfer' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NULL) OR
+        (type = 'purchase' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NULL) OR
+        (type = 'reversal' AND to_user IS NOT NULL AND merch_id IS NULL AND reverses_id IS NOT NULL) OR
+        (type = 'refund' AND to_user IS NULL AND merch_id IS NOT NULL AND reverses_id IS NOT NULL) OR
+        (type IN ('mint', 'burn') AND to_user IS NULL AND merch_id IS NULL AND reverses_id IS NULL)
+    );
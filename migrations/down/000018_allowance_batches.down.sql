@@ -0,0 +1,7 @@
+CREATE INDEX IF NOT EXISTS idx_allowance_payouts_run ON allowance_payouts(run_id);
+
+ALTER TABLE allowance_payouts DROP CONSTRAINT IF EXISTS allowance_payouts_run_user_key;
+
+ALTER TABLE allowance_payouts DROP COLUMN IF EXISTS user_id;
+
+ALTER TABLE allowance_runs DROP COLUMN IF EXISTS completed_at;
@@ -0,0 +1,155 @@
+//go:build integration
+
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	trmsqlx "github.com/avito-tech/go-transaction-manager/drivers/sqlx/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/senyabanana/shop-service/internal/entity"
+	"github.com/senyabanana/shop-service/internal/repository"
+	"github.com/senyabanana/shop-service/internal/service"
+)
+
+const (
+	defaultDSN = "host=localhost port=5432 user=postgres password=qwerty dbname=shop-db sslmode=disable"
+
+	usersCount     = 10
+	startCoins     = 1000
+	transfersCount = 3000
+	purchasesCount = 1000
+	workersCount   = 64
+	password       = "testpassword"
+)
+
+var items = []string{"pen", "socks", "cup"}
+
+// TestConcurrentTransfersAndPurchases гоняет тысячи параллельных встречных переводов и покупок
+// между небольшой группой пользователей и проверяет, что монеты не появляются и не исчезают.
+func TestConcurrentTransfersAndPurchases(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		dsn = defaultDSN
+	}
+
+	ctx := context.Background()
+
+	db, err := sqlx.ConnectContext(ctx, "postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(workersCount)
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	hasher, err := service.NewPasswordHasher("bcrypt")
+	require.NoError(t, err)
+
+	trManager := manager.Must(trmsqlx.NewDefaultFactory(db))
+	authCfg := service.AuthConfig{
+		Policy:      service.CredentialsPolicy{MinPasswordLength: 8},
+		SignupGrant: service.SignupGrantPolicy{Default: startCoins},
+	}
+	services := service.NewService(repository.NewRepository(db), trManager, hasher, authCfg, service.HistoryConfig{}, service.TxConfig{MaxAttempts: 5}, service.ExpirationConfig{}, time.Hour, log)
+
+	prefix := fmt.Sprintf("conc-%d", time.Now().UnixNano())
+	userIDs := make([]int64, usersCount)
+	usernames := make([]string, usersCount)
+	for i := range usersCount {
+		usernames[i] = fmt.Sprintf("%s-%02d", prefix, i)
+		require.NoError(t, services.Authorization.CreateUser(ctx, usernames[i], password))
+
+		user, err := services.Authorization.GetUser(ctx, usernames[i])
+		require.NoError(t, err)
+		userIDs[i] = user.ID
+	}
+
+	var (
+		spent      atomic.Int64
+		transfers  atomic.Int64
+		purchases  atomic.Int64
+		unexpected atomic.Int64
+		firstErr   atomic.Value
+	)
+
+	record := func(err error) {
+		if err == nil || errors.Is(err, entity.ErrInsufficientBalance) {
+			return
+		}
+		unexpected.Add(1)
+		firstErr.CompareAndSwap(nil, err.Error())
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workersCount {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				from := rand.IntN(usersCount)
+
+				if job < transfersCount {
+					to := (from + 1 + rand.IntN(usersCount-1)) % usersCount
+					_, err := services.Transaction.SendCoin(ctx, userIDs[from], usernames[to], int64(1+rand.IntN(50)))
+					if err == nil {
+						transfers.Add(1)
+					}
+					record(err)
+					continue
+				}
+
+				receipt, err := services.Inventory.BuyItem(ctx, userIDs[from], items[rand.IntN(len(items))])
+				if err == nil {
+					purchases.Add(1)
+					spent.Add(receipt.Amount)
+				}
+				record(err)
+			}
+		}()
+	}
+
+	for _, job := range rand.Perm(transfersCount + purchasesCount) {
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+
+	t.Logf("transfers: %d, purchases: %d, spent: %d", transfers.Load(), purchases.Load(), spent.Load())
+	assert.Zero(t, unexpected.Load(), "unexpected errors, first one: %v", firstErr.Load())
+	assert.NotZero(t, transfers.Load())
+	assert.NotZero(t, purchases.Load())
+
+	var total, negative int64
+	err = db.GetContext(ctx, &total, `SELECT COALESCE(SUM(coins), 0) FROM users WHERE id = ANY($1)`, pq.Array(userIDs))
+	require.NoError(t, err)
+	err = db.GetContext(ctx, &negative, `SELECT COUNT(*) FROM users WHERE id = ANY($1) AND coins < 0`, pq.Array(userIDs))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(usersCount*startCoins)-spent.Load(), total, "coins must be conserved")
+	assert.Zero(t, negative, "balances must never go negative")
+
+	var ledgerTotal int64
+	err = db.GetContext(ctx, &ledgerTotal, `
+		SELECT COALESCE(SUM(p.amount), 0) FROM ledger_postings AS p
+		JOIN ledger_accounts AS a ON a.id = p.account_id
+		WHERE a.user_id = ANY($1)`, pq.Array(userIDs))
+	require.NoError(t, err)
+	assert.Equal(t, total, ledgerTotal, "ledger must match cached balances")
+}